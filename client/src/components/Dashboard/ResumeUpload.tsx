@@ -84,7 +84,7 @@ const ResumeUpload: React.FC = () => {
                 {isLoading ? 'Uploading...' : 'Drop your resume here or click to browse'}
               </span>
               <span className="mt-1 block text-xs text-gray-500">
-                PDF, DOC, DOCX up to 10MB
+                PDF, DOCX, ODT, RTF, HTML, Markdown or TXT up to 10MB
               </span>
             </label>
             <input
@@ -93,7 +93,7 @@ const ResumeUpload: React.FC = () => {
               name="file-upload"
               type="file"
               className="sr-only"
-              accept=".pdf,.doc,.docx"
+              accept=".pdf,.docx,.odt,.rtf,.html,.htm,.md,.txt"
               onChange={handleChange}
               disabled={isLoading}
             />
@@ -9,6 +9,8 @@ import (
 type Config struct {
 	DatabaseURL string
 	Port        string
+	LogLevel    string
+	LogFormat   string
 }
 
 // DatabaseConfig holds database connection settings
@@ -48,25 +50,20 @@ func LoadDatabaseConfig() *DatabaseConfig {
 
 // Load loads configuration from environment variables
 func Load() *Config {
-	fmt.Println("Loading resume-processor configuration...")
-	
 	// Try to get DATABASE_URL directly, otherwise build from components
 	databaseURL := getEnv("DATABASE_URL", "")
 	if databaseURL == "" {
-		fmt.Println("DATABASE_URL not found, building from components...")
 		dbConfig := LoadDatabaseConfig()
 		databaseURL = dbConfig.BuildDatabaseURL()
-		fmt.Printf("Built database URL: %s\n", databaseURL)
-	} else {
-		fmt.Printf("Using provided DATABASE_URL: %s\n", databaseURL)
 	}
 	
 	config := &Config{
 		DatabaseURL: databaseURL,
 		Port:        getEnv("PORT", "8081"),
+		LogLevel:    getEnv("LOG_LEVEL", "info"),
+		LogFormat:   getEnv("LOG_FORMAT", "json"),
 	}
 	
-	fmt.Printf("Resume-processor configuration loaded successfully\n")
 	return config
 }
 
@@ -1,8 +1,7 @@
 package database
 
 import (
-	"log"
-
+	"github.com/rs/zerolog/log"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"github.com/resume-optimizer/resume-processor/internal/models"
@@ -14,7 +13,7 @@ func InitDatabase(databaseURL string) {
 	var err error
 	DB, err = gorm.Open(postgres.Open(databaseURL), &gorm.Config{})
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		log.Fatal().Err(err).Msg("Failed to connect to database")
 	}
 
 	// Auto-migrate the schema
@@ -22,14 +21,19 @@ func InitDatabase(databaseURL string) {
 		&models.User{},
 		&models.Resume{},
 		&models.OptimizationSession{},
+		&models.OptimizationBatch{},
 		&models.Feedback{},
 		&models.UserAPIKey{},
+		&models.ResumeRevision{},
+		&models.UserSettings{},
+		&models.SkillOverride{},
+		&models.ResumeTemplate{},
 	)
 	if err != nil {
-		log.Fatal("Failed to migrate database:", err)
+		log.Fatal().Err(err).Msg("Failed to migrate database")
 	}
 
-	log.Println("Database connection established and migrated")
+	log.Info().Msg("Database connection established and migrated")
 }
 
 func GetDB() *gorm.DB {
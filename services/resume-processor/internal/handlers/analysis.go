@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/resume-optimizer/resume-processor/internal/services"
+)
+
+// AnalyzeResume returns a structural analysis of a resume: the normalized
+// employment and education timeline with its gaps, overlaps and
+// inconsistently formatted dates, and the canonical skills it mentions
+func AnalyzeResume(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	resume, ok := loadUserResume(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"resume_id": resume.ID,
+		"timeline":  services.NewTimelineAnalyzer().Analyze(resume.ExtractedText),
+		"skills":    services.DefaultSkillTaxonomy().Extract(resume.ExtractedText),
+	})
+}
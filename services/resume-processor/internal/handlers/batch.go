@@ -0,0 +1,693 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/resume-optimizer/resume-processor/internal/database"
+	"github.com/resume-optimizer/resume-processor/internal/models"
+	"github.com/resume-optimizer/resume-processor/internal/services"
+	apperrors "github.com/resume-optimizer/shared/errors"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+const (
+	maxBatchItems           = 25
+	maxBatchItemAttempts    = 2 // Automatic attempts per item before it is marked failed
+	defaultBatchConcurrency = 3
+)
+
+// Batch and batch item statuses
+const (
+	batchStatusPending    = "pending"
+	batchStatusProcessing = "processing"
+	batchStatusCompleted  = "completed"
+	batchStatusPartial    = "partial" // Finished with some items failed
+	batchStatusFailed     = "failed"
+	batchStatusCancelled  = "cancelled"
+)
+
+var (
+	// baseContext parents batch processing, which outlives the request that
+	// started it. main replaces it with the server's shutdown context.
+	baseContext = context.Background()
+
+	// batchRuns tracks running batches, so shutdown can wait for cancelled
+	// items to record their status
+	batchRuns sync.WaitGroup
+
+	slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// SetBaseContext sets the context background work such as batch optimization
+// runs under; cancelling it cancels in-flight batch items
+func SetBaseContext(ctx context.Context) {
+	baseContext = ctx
+}
+
+// WaitForBatches waits until running batches have recorded their final
+// status, or until ctx is done. Call it after cancelling the base context.
+func WaitForBatches(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		batchRuns.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RecoverBatches cancels the pending and processing items of batches left
+// unfinished by a crash or an unclean shutdown, so that they can be retried,
+// and finalizes those batches. It must run before requests are served, as it
+// assumes no batch is running.
+func RecoverBatches() {
+	var batches []models.OptimizationBatch
+	err := database.GetDB().Select("id").
+		Where("status IN ?", []string{batchStatusPending, batchStatusProcessing}).
+		Find(&batches).Error
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load unfinished batches")
+		return
+	}
+	if len(batches) == 0 {
+		return
+	}
+
+	ids := make([]string, len(batches))
+	for i, batch := range batches {
+		ids[i] = batch.ID
+	}
+	result := database.GetDB().Model(&models.OptimizationSession{}).
+		Where("batch_id IN ? AND status IN ?", ids, []string{batchStatusPending, batchStatusProcessing}).
+		Updates(map[string]interface{}{
+			"status":     batchStatusCancelled,
+			"error":      "Interrupted by a service restart",
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		log.Error().Err(result.Error).Msg("Failed to cancel interrupted batch items")
+		return
+	}
+	for _, id := range ids {
+		finalizeBatch(id)
+	}
+	log.Warn().Int("batches", len(ids)).Int64("items", result.RowsAffected).Msg("Cancelled batch items interrupted by a restart")
+}
+
+// startBatch runs batch items in the background under the base context
+func startBatch(batchID string, job batchJob, sessions []models.OptimizationSession) {
+	batchRuns.Add(1)
+	go func() {
+		defer batchRuns.Done()
+		runBatch(baseContext, batchID, job, sessions)
+	}()
+}
+
+// batchJobDescription is a single job description in a batch request
+type batchJobDescription struct {
+	URL  string `json:"url"`
+	Text string `json:"text"`
+}
+
+// batchJob holds everything needed to optimize the items of a batch
+type batchJob struct {
+	ResumeContent string
+	AIModel       string
+	KeepOnePage   bool
+	APIKey        string
+	RedactPII     bool
+	Fallbacks     []services.ModelCandidate
+}
+
+// batchProgress summarizes the item statuses of a batch
+type batchProgress struct {
+	Total      int `json:"total"`
+	Pending    int `json:"pending"`
+	Processing int `json:"processing"`
+	Completed  int `json:"completed"`
+	Failed     int `json:"failed"`
+	Cancelled  int `json:"cancelled"`
+	Percent    int `json:"percent"` // Share of items that reached a final status
+}
+
+// OptimizeBatch optimizes one resume against many job descriptions. Items are
+// processed in the background; poll GetBatch for progress.
+func OptimizeBatch(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req struct {
+		ResumeID        string                `json:"resumeId" binding:"required"`
+		JobDescriptions []batchJobDescription `json:"jobDescriptions" binding:"required"`
+		AIModel         string                `json:"aiModel" binding:"required"`
+		KeepOnePage     bool                  `json:"keepOnePage"`
+		UserAPIKeyID    string                `json:"userApiKey"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if len(req.JobDescriptions) == 0 || len(req.JobDescriptions) > maxBatchItems {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A batch must contain between 1 and %d job descriptions", maxBatchItems)})
+		return
+	}
+	for i, jd := range req.JobDescriptions {
+		if strings.TrimSpace(jd.URL) == "" && strings.TrimSpace(jd.Text) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Job description %d needs either a URL or text", i+1)})
+			return
+		}
+	}
+
+	// Reject URLs the fetch policy blocks now rather than failing their items later
+	scraper := services.NewJobScraper()
+	for i, jd := range req.JobDescriptions {
+		if strings.TrimSpace(jd.Text) != "" || strings.TrimSpace(jd.URL) == "" {
+			continue
+		}
+		if err := scraper.CheckURL(c.Request.Context(), jd.URL); errors.Is(err, services.ErrFetchBlocked) {
+			appErr := apperrors.NewAppErrorWithDetails(apperrors.ErrCodeValidation, fmt.Sprintf("Job description %d URL not allowed", i+1), err.Error(), err)
+			c.JSON(appErr.HTTPStatus, appErr)
+			return
+		}
+	}
+
+	model, ok := services.ResolveModel(req.AIModel)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported AI model: " + req.AIModel})
+		return
+	}
+	req.AIModel = model.ID
+
+	var resume models.Resume
+	if err := database.GetDB().Where("id = ? AND user_id = ?", req.ResumeID, userID.(string)).First(&resume).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resume not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return
+	}
+
+	if resume.ExtractedText == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No extracted text content found for this resume"})
+		return
+	}
+
+	var apiKeyID *string
+	if req.UserAPIKeyID != "" {
+		apiKeyID = &req.UserAPIKeyID
+	}
+
+	batch := models.OptimizationBatch{
+		ID:           uuid.New().String(),
+		UserID:       userID.(string),
+		ResumeID:     resume.ID,
+		AIModel:      req.AIModel,
+		KeepOnePage:  req.KeepOnePage,
+		UserAPIKeyID: apiKeyID,
+		Status:       batchStatusProcessing,
+		TotalItems:   len(req.JobDescriptions),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	job, err := loadBatchJob(batch, resume.ExtractedText)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessions := make([]models.OptimizationSession, len(req.JobDescriptions))
+	for i, jd := range req.JobDescriptions {
+		index := i
+		session := models.OptimizationSession{
+			ID:          uuid.New().String(),
+			UserID:      userID.(string),
+			ResumeID:    resume.ID,
+			AIModel:     req.AIModel,
+			KeepOnePage: req.KeepOnePage,
+			PIIRedacted: job.RedactPII,
+			Status:      batchStatusPending,
+			BatchID:     &batch.ID,
+			BatchIndex:  &index,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		if jdURL := strings.TrimSpace(jd.URL); jdURL != "" {
+			session.JobDescriptionURL = &jdURL
+		}
+		if jdText := strings.TrimSpace(jd.Text); jdText != "" {
+			session.JobDescriptionText = &jdText
+		}
+		sessions[i] = session
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&batch).Error; err != nil {
+			return err
+		}
+		return tx.Create(&sessions).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create optimization batch: " + err.Error()})
+		return
+	}
+
+	startBatch(batch.ID, job, sessions)
+
+	batch.Sessions = sessions
+	c.JSON(http.StatusAccepted, gin.H{
+		"batch":    batch,
+		"progress": summarizeBatch(sessions),
+	})
+}
+
+// GetBatch returns a batch with its items and progress
+func GetBatch(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	batch, ok := loadUserBatch(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"batch":    batch,
+		"progress": summarizeBatch(batch.Sessions),
+	})
+}
+
+// RetryBatchItem re-runs a failed or cancelled item of a batch
+func RetryBatchItem(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	batch, ok := loadUserBatch(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	var session *models.OptimizationSession
+	for i := range batch.Sessions {
+		if batch.Sessions[i].ID == c.Param("itemId") {
+			session = &batch.Sessions[i]
+			break
+		}
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Batch item not found"})
+		return
+	}
+
+	if session.Status != batchStatusFailed && session.Status != batchStatusCancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only failed or cancelled items can be retried (item is " + session.Status + ")"})
+		return
+	}
+
+	var resume models.Resume
+	if err := database.GetDB().Where("id = ?", batch.ResumeID).First(&resume).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resume not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + err.Error()})
+		return
+	}
+
+	job, err := loadBatchJob(*batch, resume.ExtractedText)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Claim the item only if no concurrent retry got there first
+	claim := database.GetDB().Model(&models.OptimizationSession{}).
+		Where("id = ? AND status IN ?", session.ID, []string{batchStatusFailed, batchStatusCancelled}).
+		Updates(map[string]interface{}{"status": batchStatusPending, "error": nil, "updated_at": time.Now()})
+	if claim.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error: " + claim.Error.Error()})
+		return
+	}
+	if claim.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Item is already being retried"})
+		return
+	}
+	session.Status = batchStatusPending
+	session.Error = nil
+
+	database.GetDB().Model(&models.OptimizationBatch{}).Where("id = ?", batch.ID).Updates(map[string]interface{}{
+		"status":     batchStatusProcessing,
+		"updated_at": time.Now(),
+	})
+
+	startBatch(batch.ID, job, []models.OptimizationSession{*session})
+
+	batch.Status = batchStatusProcessing
+	c.JSON(http.StatusAccepted, gin.H{
+		"batch":    batch,
+		"item":     session,
+		"progress": summarizeBatch(batch.Sessions),
+	})
+}
+
+// DownloadBatch streams the optimized results of a batch as a ZIP archive,
+// one text file per completed item
+func DownloadBatch(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	batch, ok := loadUserBatch(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	var completed []models.OptimizationSession
+	for _, session := range batch.Sessions {
+		if session.Status == batchStatusCompleted && session.OptimizedContent != nil {
+			completed = append(completed, session)
+		}
+	}
+	if len(completed) == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Batch has no completed optimizations to download"})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="batch-%s.zip"`, batch.ID))
+	c.Status(http.StatusOK)
+
+	archive := zip.NewWriter(c.Writer)
+	for _, session := range completed {
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     batchItemFilename(session),
+			Method:   zip.Deflate,
+			Modified: session.UpdatedAt,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if _, err := entry.Write([]byte(*session.OptimizedContent)); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		c.Error(err)
+	}
+}
+
+// loadUserBatch loads the batch named by the :id parameter with its items,
+// writing the error response itself when the batch cannot be returned
+func loadUserBatch(c *gin.Context, userID string) (*models.OptimizationBatch, bool) {
+	var batch models.OptimizationBatch
+	err := database.GetDB().
+		Preload("Sessions", func(db *gorm.DB) *gorm.DB { return db.Order("batch_index ASC") }).
+		Where("id = ? AND user_id = ?", c.Param("id"), userID).
+		First(&batch).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Batch not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return nil, false
+	}
+	return &batch, true
+}
+
+// loadBatchJob resolves the model, keys and settings a batch runs with
+func loadBatchJob(batch models.OptimizationBatch, resumeContent string) (batchJob, error) {
+	if resumeContent == "" {
+		return batchJob{}, fmt.Errorf("no extracted text content found for this resume")
+	}
+
+	model, ok := services.ResolveModel(batch.AIModel)
+	if !ok {
+		return batchJob{}, fmt.Errorf("unsupported AI model: %s", batch.AIModel)
+	}
+
+	apiKey := ""
+	if services.ProviderRequiresAPIKey(model.Provider) {
+		keyID := ""
+		if batch.UserAPIKeyID != nil {
+			keyID = *batch.UserAPIKeyID
+		}
+		var err error
+		apiKey, err = getUserAPIKey(batch.UserID, keyID)
+		if err != nil {
+			return batchJob{}, fmt.Errorf("failed to retrieve API key: %v", err)
+		}
+		if apiKey == "" {
+			return batchJob{}, fmt.Errorf("API key is required. Please add an API key in Settings.")
+		}
+	}
+
+	settings, err := loadUserSettings(batch.UserID)
+	if err != nil {
+		return batchJob{}, fmt.Errorf("failed to load user settings: %v", err)
+	}
+
+	return batchJob{
+		ResumeContent: resumeContent,
+		AIModel:       model.ID,
+		KeepOnePage:   batch.KeepOnePage,
+		APIKey:        apiKey,
+		RedactPII:     settings.RedactPII,
+		Fallbacks:     buildFallbackChain(batch.UserID, model.ID, settings.FallbackModels),
+	}, nil
+}
+
+// runBatch processes batch items with at most batchConcurrency() in flight,
+// then records the final batch status
+func runBatch(ctx context.Context, batchID string, job batchJob, sessions []models.OptimizationSession) {
+	sem := make(chan struct{}, batchConcurrency())
+	var wg sync.WaitGroup
+
+	for i := range sessions {
+		session := &sessions[i]
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			finishBatchItem(session, batchStatusCancelled, nil, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			processBatchItem(ctx, job, session)
+		}()
+	}
+
+	wg.Wait()
+	finalizeBatch(batchID)
+}
+
+// processBatchItem fetches the item's job description if needed and runs the
+// optimization, retrying transient failures before giving up
+func processBatchItem(ctx context.Context, job batchJob, session *models.OptimizationSession) {
+	database.GetDB().Model(session).Updates(map[string]interface{}{
+		"status":     batchStatusProcessing,
+		"updated_at": time.Now(),
+	})
+
+	optimizer := services.NewAIOptimizer()
+	scraper := services.NewJobScraper()
+
+	var lastErr error
+	for attempt := 1; attempt <= maxBatchItemAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(time.Duration(attempt-1) * 5 * time.Second):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			finishBatchItem(session, batchStatusCancelled, nil, ctx.Err())
+			return
+		}
+
+		session.Attempts++
+
+		if session.JobDescriptionText == nil || *session.JobDescriptionText == "" {
+			posting, err := scraper.FetchJobPosting(ctx, *session.JobDescriptionURL)
+			if err != nil {
+				lastErr = fmt.Errorf("failed to fetch job description from URL: %v", err)
+				if errors.Is(err, services.ErrFetchBlocked) {
+					break
+				}
+				continue
+			}
+			fetched := posting.Text()
+			session.JobDescriptionText = &fetched
+		}
+
+		result, err := optimizer.OptimizeResume(ctx, services.OptimizationRequest{
+			ResumeContent:  job.ResumeContent,
+			JobDescription: *session.JobDescriptionText,
+			AIModel:        job.AIModel,
+			KeepOnePage:    job.KeepOnePage,
+			UserAPIKey:     job.APIKey,
+			RedactPII:      job.RedactPII,
+			Fallbacks:      job.Fallbacks,
+		})
+		if err == nil {
+			finishBatchItem(session, batchStatusCompleted, result, nil)
+			return
+		}
+
+		lastErr = err
+		if !services.IsRetryableProviderError(err) {
+			break
+		}
+	}
+
+	status := batchStatusFailed
+	if ctx.Err() != nil {
+		status = batchStatusCancelled
+	}
+	finishBatchItem(session, status, nil, lastErr)
+}
+
+// finishBatchItem stores the final status of a batch item
+func finishBatchItem(session *models.OptimizationSession, status string, result *services.OptimizationResponse, err error) {
+	session.Status = status
+	session.UpdatedAt = time.Now()
+	session.Error = nil
+	if err != nil {
+		message := err.Error()
+		session.Error = &message
+	}
+	if result != nil {
+		session.OptimizedContent = &result.OptimizedContent
+		session.InjectionDetections = toModelDetections(result.Detections)
+		session.ModelUsed = &result.Model
+	}
+
+	database.GetDB().Save(session)
+}
+
+// finalizeBatch derives the batch status from its items once none are in flight
+func finalizeBatch(batchID string) {
+	var sessions []models.OptimizationSession
+	if err := database.GetDB().Select("status").Where("batch_id = ?", batchID).Find(&sessions).Error; err != nil {
+		return
+	}
+
+	progress := summarizeBatch(sessions)
+	status := batchStatusProcessing
+	switch {
+	case progress.Pending+progress.Processing > 0:
+		// A retry is still running; it finalizes the batch when done
+	case progress.Completed == progress.Total:
+		status = batchStatusCompleted
+	case progress.Completed > 0:
+		status = batchStatusPartial
+	case progress.Cancelled > 0:
+		status = batchStatusCancelled
+	default:
+		status = batchStatusFailed
+	}
+
+	database.GetDB().Model(&models.OptimizationBatch{}).Where("id = ?", batchID).Updates(map[string]interface{}{
+		"status":     status,
+		"updated_at": time.Now(),
+	})
+}
+
+// summarizeBatch counts batch items by status
+func summarizeBatch(sessions []models.OptimizationSession) batchProgress {
+	progress := batchProgress{Total: len(sessions)}
+	for _, session := range sessions {
+		switch session.Status {
+		case batchStatusPending:
+			progress.Pending++
+		case batchStatusProcessing:
+			progress.Processing++
+		case batchStatusCompleted:
+			progress.Completed++
+		case batchStatusFailed:
+			progress.Failed++
+		case batchStatusCancelled:
+			progress.Cancelled++
+		}
+	}
+	if progress.Total > 0 {
+		progress.Percent = (progress.Completed + progress.Failed + progress.Cancelled) * 100 / progress.Total
+	}
+	return progress
+}
+
+// batchConcurrency is the number of items of one batch optimized at once,
+// configurable with BATCH_CONCURRENCY
+func batchConcurrency() int {
+	if n, err := strconv.Atoi(os.Getenv("BATCH_CONCURRENCY")); err == nil && n > 0 {
+		return n
+	}
+	return defaultBatchConcurrency
+}
+
+// batchItemFilename names a batch item in the ZIP download after its position
+// and the job posting it was optimized for
+func batchItemFilename(session models.OptimizationSession) string {
+	index := 0
+	if session.BatchIndex != nil {
+		index = *session.BatchIndex
+	}
+
+	label := ""
+	if session.JobDescriptionURL != nil {
+		if parsed, err := url.Parse(*session.JobDescriptionURL); err == nil {
+			label = strings.TrimPrefix(parsed.Host, "www.") + " " + parsed.Path
+		}
+	}
+	if label == "" && session.JobDescriptionText != nil {
+		label = strings.SplitN(strings.TrimSpace(*session.JobDescriptionText), "\n", 2)[0]
+	}
+
+	slug := strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(label), "-"), "-")
+	if len(slug) > 60 {
+		slug = strings.TrimRight(slug[:60], "-")
+	}
+	if slug == "" {
+		slug = "job"
+	}
+
+	return fmt.Sprintf("%02d-%s.txt", index+1, slug)
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/resume-optimizer/resume-processor/internal/database"
+	"github.com/resume-optimizer/resume-processor/internal/models"
+	"github.com/resume-optimizer/resume-processor/internal/services"
+	apperrors "github.com/resume-optimizer/shared/errors"
+)
+
+// updateContactRequest is a partial update of a resume's contact details.
+// Omitted fields are left unchanged, an empty string clears a field, and
+// the email and phone lists replace the stored lists.
+type updateContactRequest struct {
+	Name      *string   `json:"name"`
+	Emails    *[]string `json:"emails"`
+	Phones    *[]string `json:"phones"`
+	City      *string   `json:"city"`
+	Region    *string   `json:"region"`
+	LinkedIn  *string   `json:"linkedin"`
+	GitHub    *string   `json:"github"`
+	Portfolio *string   `json:"portfolio"`
+}
+
+// GetResumeContact returns the contact details of a resume, extracting
+// them first for resumes uploaded before contact extraction existed
+func GetResumeContact(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	resume, ok := loadUserResume(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	if resume.ContactInfo == nil {
+		resume.ContactInfo = toModelContactInfo(services.NewContactExtractor().Extract(resume.ExtractedText))
+		if err := database.GetDB().Model(&resume).Update("contact_info", resume.ContactInfo).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"contact": resume.ContactInfo})
+}
+
+// UpdateResumeContact applies user corrections to the contact details of a
+// resume. Corrected values are stored with full confidence.
+func UpdateResumeContact(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req updateContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	resume, ok := loadUserResume(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	contact := resume.ContactInfo
+	if contact == nil {
+		contact = toModelContactInfo(services.NewContactExtractor().Extract(resume.ExtractedText))
+	}
+
+	if err := applyContactUpdate(contact, req); err != nil {
+		c.JSON(err.HTTPStatus, err)
+		return
+	}
+
+	if err := database.GetDB().Model(&resume).Update("contact_info", contact).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"contact": contact})
+}
+
+// applyContactUpdate validates and applies a partial update. Nothing is
+// changed when any field is invalid.
+func applyContactUpdate(contact *models.ContactInfo, req updateContactRequest) *apperrors.AppError {
+	updated := *contact
+	var problems []string
+
+	setText := func(field **models.ContactField, value *string) {
+		if value == nil {
+			return
+		}
+		*field = correctedField(strings.TrimSpace(*value))
+	}
+	setURL := func(name string, field **models.ContactField, value *string, host string) {
+		if value == nil {
+			return
+		}
+		if strings.TrimSpace(*value) == "" {
+			*field = nil
+			return
+		}
+		link, ok := services.NormalizeProfileURL(*value, host)
+		if !ok && host != "" {
+			problems = append(problems, fmt.Sprintf("%s: not a %s URL", name, host))
+			return
+		}
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: not a valid URL", name))
+			return
+		}
+		*field = correctedField(link)
+	}
+
+	setText(&updated.Name, req.Name)
+	setText(&updated.City, req.City)
+	setText(&updated.Region, req.Region)
+	setURL("linkedin", &updated.LinkedIn, req.LinkedIn, "linkedin.com")
+	setURL("github", &updated.GitHub, req.GitHub, "github.com")
+	setURL("portfolio", &updated.Portfolio, req.Portfolio, "")
+
+	if req.Emails != nil {
+		updated.Emails = []models.ContactField{}
+		for i, raw := range *req.Emails {
+			email, ok := services.NormalizeEmail(raw)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("emails[%d]: not a valid email address", i))
+				continue
+			}
+			updated.Emails = append(updated.Emails, *correctedField(email))
+		}
+	}
+
+	if req.Phones != nil {
+		extractor := services.NewContactExtractor()
+		updated.Phones = []models.ContactField{}
+		for i, raw := range *req.Phones {
+			phone, _, ok := extractor.NormalizePhone(raw)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("phones[%d]: not a valid phone number", i))
+				continue
+			}
+			updated.Phones = append(updated.Phones, *correctedField(phone))
+		}
+	}
+
+	if len(problems) > 0 {
+		return apperrors.NewAppErrorWithDetails(apperrors.ErrCodeValidation, "Invalid contact details", strings.Join(problems, "; "), nil)
+	}
+	*contact = updated
+	return nil
+}
+
+// correctedField returns a user-entered contact value, or nil to clear the field
+func correctedField(value string) *models.ContactField {
+	if value == "" {
+		return nil
+	}
+	return &models.ContactField{Value: value, Confidence: 1, Corrected: true}
+}
+
+// toModelContactInfo converts extracted contact details into their stored form
+func toModelContactInfo(info services.ContactInfo) *models.ContactInfo {
+	field := func(f *services.ContactField) *models.ContactField {
+		if f == nil {
+			return nil
+		}
+		return &models.ContactField{Value: f.Value, Confidence: f.Confidence}
+	}
+	fields := func(list []services.ContactField) []models.ContactField {
+		stored := make([]models.ContactField, 0, len(list))
+		for _, f := range list {
+			stored = append(stored, models.ContactField{Value: f.Value, Confidence: f.Confidence})
+		}
+		return stored
+	}
+
+	return &models.ContactInfo{
+		Name:      field(info.Name),
+		Emails:    fields(info.Emails),
+		Phones:    fields(info.Phones),
+		City:      field(info.City),
+		Region:    field(info.Region),
+		LinkedIn:  field(info.LinkedIn),
+		GitHub:    field(info.GitHub),
+		Portfolio: field(info.Portfolio),
+	}
+}
+
+// toServiceContactInfo converts stored contact details back for the services
+func toServiceContactInfo(info *models.ContactInfo) services.ContactInfo {
+	if info == nil {
+		return services.ContactInfo{}
+	}
+	field := func(f *models.ContactField) *services.ContactField {
+		if f == nil {
+			return nil
+		}
+		return &services.ContactField{Value: f.Value, Confidence: f.Confidence}
+	}
+	fields := func(list []models.ContactField) []services.ContactField {
+		converted := make([]services.ContactField, 0, len(list))
+		for _, f := range list {
+			converted = append(converted, services.ContactField{Value: f.Value, Confidence: f.Confidence})
+		}
+		return converted
+	}
+
+	return services.ContactInfo{
+		Name:      field(info.Name),
+		Emails:    fields(info.Emails),
+		Phones:    fields(info.Phones),
+		City:      field(info.City),
+		Region:    field(info.Region),
+		LinkedIn:  field(info.LinkedIn),
+		GitHub:    field(info.GitHub),
+		Portfolio: field(info.Portfolio),
+	}
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/resume-optimizer/resume-processor/internal/database"
+	"github.com/resume-optimizer/resume-processor/internal/models"
+	"github.com/resume-optimizer/resume-processor/internal/services"
+	apperrors "github.com/resume-optimizer/shared/errors"
+	"gorm.io/gorm"
+)
+
+// exportFormatJSONResume exports the jsonresume.org schema
+const exportFormatJSONResume = "jsonresume"
+
+// ExportResume exports a resume in the format given by ?format=: jsonresume
+// (default), latex or markdown, the latter two with the built-in template or
+// the ID of a user-defined template given by ?template=. JSON Resume uploads
+// are returned as uploaded.
+func ExportResume(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	exporter, ok := newTemplateExporter(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	resume, ok := loadUserResume(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	if exporter != nil {
+		sendTemplateExport(c, "resume-"+resume.ID, exporter, resumeDocument(resume))
+		return
+	}
+	if len(resume.SourceJSON) > 0 {
+		sendJSONResume(c, "resume-"+resume.ID, []byte(resume.SourceJSON))
+		return
+	}
+
+	contact := toServiceContactInfo(resume.ContactInfo)
+	if resume.ContactInfo == nil {
+		contact = services.NewContactExtractor().Extract(resume.ExtractedText)
+	}
+	data, err := json.Marshal(services.JSONResumeFromText(resume.ExtractedText, contact))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode resume: " + err.Error()})
+		return
+	}
+	sendJSONResume(c, "resume-"+resume.ID, data)
+}
+
+// ExportOptimization exports the optimized resume of a completed session in
+// the format given by ?format=, as for ExportResume
+func ExportOptimization(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	exporter, ok := newTemplateExporter(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	session, ok := loadCompletedSession(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	if exporter != nil {
+		sendTemplateExport(c, "optimized-"+session.ID, exporter, services.ResumeDocumentFromText(*session.OptimizedContent))
+		return
+	}
+
+	contact, err := sessionContactInfo(session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+	data, err := json.Marshal(services.JSONResumeFromText(*session.OptimizedContent, contact))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode resume: " + err.Error()})
+		return
+	}
+	sendJSONResume(c, "optimized-"+session.ID, data)
+}
+
+// ExportOptimizationPDF renders the optimized resume of a completed session
+// as a PDF with the built-in template given by ?template= (classic, modern
+// or compact; default classic)
+func ExportOptimizationPDF(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	renderer, err := services.NewPDFRenderer(c.Query("template"))
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownPDFTemplate) {
+			appErr := apperrors.NewAppErrorWithDetails(apperrors.ErrCodeValidation, "Unknown template", err.Error(), err)
+			c.JSON(appErr.HTTPStatus, appErr)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create renderer: " + err.Error()})
+		return
+	}
+
+	session, ok := loadCompletedSession(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	data, err := renderer.Render(services.ResumeDocumentFromText(*session.OptimizedContent))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render PDF: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="optimized-%s.pdf"`, session.ID))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// ExportOptimizationDOCX writes the optimized resume of a completed session
+// as a Word document
+func ExportOptimizationDOCX(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	session, ok := loadCompletedSession(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	sendDOCX(c, "optimized-"+session.ID, services.ResumeDocumentFromText(*session.OptimizedContent))
+}
+
+// ExportResumeDOCX writes a stored resume as a Word document. JSON Resume
+// uploads are laid out from their structure, other resumes from their text.
+func ExportResumeDOCX(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	resume, ok := loadUserResume(c, userID.(string))
+	if !ok {
+		return
+	}
+
+	sendDOCX(c, "resume-"+resume.ID, resumeDocument(resume))
+}
+
+// resumeDocument lays out a stored resume for rendering: JSON Resume uploads
+// from their structure, other resumes from their text
+func resumeDocument(resume models.Resume) *services.ResumeDocument {
+	if len(resume.SourceJSON) > 0 {
+		if structured, err := services.ParseJSONResume(resume.SourceJSON); err == nil {
+			return services.ResumeDocumentFromJSONResume(structured)
+		}
+	}
+	return services.ResumeDocumentFromText(resume.ExtractedText)
+}
+
+// newTemplateExporter loads the exporter for ?format= and ?template=, which
+// names a built-in template or holds the ID of a user-defined one. It
+// returns nil for JSON Resume exports, and false after writing the error
+// response for unknown formats and templates.
+func newTemplateExporter(c *gin.Context, userID string) (*services.TemplateExporter, bool) {
+	format := c.DefaultQuery("format", exportFormatJSONResume)
+	if format == exportFormatJSONResume {
+		return nil, true
+	}
+
+	name := c.Query("template")
+	if _, err := uuid.Parse(name); err == nil {
+		template, ok := loadResumeTemplate(c, name, userID)
+		if !ok {
+			return nil, false
+		}
+		if template.Format != format {
+			appErr := apperrors.NewAppError(apperrors.ErrCodeValidation, "Template "+template.Name+" renders "+template.Format+", not "+format, nil)
+			c.JSON(appErr.HTTPStatus, appErr)
+			return nil, false
+		}
+		exporter, err := services.NewUserTemplateExporter(template.Format, template.Body, template.RequiredFields)
+		if err != nil {
+			appErr := apperrors.NewAppErrorWithDetails(apperrors.ErrCodeValidation, "Invalid template", err.Error(), err)
+			c.JSON(appErr.HTTPStatus, appErr)
+			return nil, false
+		}
+		return exporter, true
+	}
+
+	exporter, err := services.NewTemplateExporter(format, name)
+	switch {
+	case err == nil:
+		return exporter, true
+	case errors.Is(err, services.ErrUnknownExportFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export format: " + format})
+	case errors.Is(err, services.ErrUnknownExportTemplate):
+		appErr := apperrors.NewAppErrorWithDetails(apperrors.ErrCodeValidation, "Unknown template", err.Error(), err)
+		c.JSON(appErr.HTTPStatus, appErr)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load template: " + err.Error()})
+	}
+	return nil, false
+}
+
+// loadCompletedSession loads the optimization session named in the path for
+// the user, writing the error response when it is missing or has no output
+func loadCompletedSession(c *gin.Context, userID string) (models.OptimizationSession, bool) {
+	var session models.OptimizationSession
+	if err := database.GetDB().Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Optimization session not found"})
+			return session, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return session, false
+	}
+
+	if session.Status != "completed" || session.OptimizedContent == nil || *session.OptimizedContent == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Optimization session has no completed content to export"})
+		return session, false
+	}
+	return session, true
+}
+
+// sessionContactInfo returns the contact details for an optimized resume:
+// those of the source resume, which carry the user's corrections, or those
+// found in the optimized text when the resume is gone
+func sessionContactInfo(session models.OptimizationSession) (services.ContactInfo, error) {
+	var resume models.Resume
+	err := database.GetDB().Select("id", "contact_info").Where("id = ?", session.ResumeID).First(&resume).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return services.ContactInfo{}, err
+	}
+	if err == nil && resume.ContactInfo != nil {
+		return toServiceContactInfo(resume.ContactInfo), nil
+	}
+	return services.NewContactExtractor().Extract(*session.OptimizedContent), nil
+}
+
+// sendDOCX renders a resume document as a Word file and sends it as a download
+func sendDOCX(c *gin.Context, name string, doc *services.ResumeDocument) {
+	data, err := services.RenderDOCX(doc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render DOCX: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.docx"`, name))
+	c.Data(http.StatusOK, services.MIMEDOCX, data)
+}
+
+// sendTemplateExport renders a resume document with an export template and
+// sends the .tex, .md or .zip file as a download
+func sendTemplateExport(c *gin.Context, name string, exporter *services.TemplateExporter, doc *services.ResumeDocument) {
+	file, err := exporter.Render(doc)
+	if err != nil {
+		if exporter.UserDefined() {
+			writeTemplateRenderError(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render export: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s%s"`, name, file.Ext))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// sendJSONResume sends a JSON Resume document as a download
+func sendJSONResume(c *gin.Context, name string, data []byte) {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err == nil {
+		data = indented.Bytes()
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, name))
+	c.Data(http.StatusOK, "application/json", data)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/resume-optimizer/resume-processor/internal/database"
+	"github.com/resume-optimizer/resume-processor/internal/models"
+	"github.com/resume-optimizer/resume-processor/internal/services"
+)
+
+// catalogModel is a catalog entry annotated for the calling user
+type catalogModel struct {
+	services.ModelInfo
+	Available bool `json:"available"` // The user can call this model with their stored API keys
+}
+
+// catalogProvider groups the catalog models of one provider
+type catalogProvider struct {
+	Provider  string         `json:"provider"`
+	Name      string         `json:"name"`
+	HasAPIKey bool           `json:"has_api_key"`
+	Models    []catalogModel `json:"models"`
+}
+
+// ListModels returns the supported AI models grouped by provider, marking
+// which ones the user can call with their stored API keys
+func ListModels(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var providers []string
+	if err := database.GetDB().Model(&models.UserAPIKey{}).Where("user_id = ?", userID.(string)).Distinct().Pluck("provider", &providers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+
+	hasKey := make(map[string]bool)
+	for _, provider := range providers {
+		hasKey[provider] = true
+	}
+
+	var groups []*catalogProvider
+	byProvider := make(map[string]*catalogProvider)
+	for _, model := range services.ModelCatalog() {
+		group, ok := byProvider[model.Provider]
+		if !ok {
+			group = &catalogProvider{
+				Provider:  model.Provider,
+				Name:      services.ProviderName(model.Provider),
+				HasAPIKey: hasKey[model.Provider] || !services.ProviderRequiresAPIKey(model.Provider),
+			}
+			byProvider[model.Provider] = group
+			groups = append(groups, group)
+		}
+		group.Models = append(group.Models, catalogModel{ModelInfo: model, Available: group.HasAPIKey})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"providers": groups})
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,6 +17,8 @@ import (
 	"github.com/resume-optimizer/resume-processor/internal/database"
 	"github.com/resume-optimizer/resume-processor/internal/models"
 	"github.com/resume-optimizer/resume-processor/internal/services"
+	apperrors "github.com/resume-optimizer/shared/errors"
+	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 )
 
@@ -31,24 +34,19 @@ func init() {
 
 // UploadResume uploads a new resume to the server
 func UploadResume(c *gin.Context) {
-	fmt.Printf("=== UPLOAD DEBUG ===\n")
-	fmt.Printf("Upload request received\n")
-	
+	logger := zerolog.Ctx(c.Request.Context())
+
 	userID, exists := c.Get("userID")
 	if !exists {
-		fmt.Printf("ERROR: User not authenticated\n")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
-	fmt.Printf("User ID: %s\n", userID.(string))
 
 	file, err := c.FormFile("file")
 	if err != nil {
-		fmt.Printf("ERROR: Failed to get form file: %v\n", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Bad request: " + err.Error()})
 		return
 	}
-	fmt.Printf("File received: %s, Size: %d bytes\n", file.Filename, file.Size)
 
 	fileID := uuid.New().String()
 	// Preserve original file extension for proper text extraction
@@ -58,26 +56,42 @@ func UploadResume(c *gin.Context) {
 		filename = fileID + originalExt
 	}
 	destPath := filepath.Join(storagePath, filename)
-	fmt.Printf("Saving file to: %s (original: %s, ext: %s)\n", destPath, file.Filename, originalExt)
+	// The original filename often contains the candidate's name, so only
+	// the extension and size are logged
+	uploadLogger := logger.With().Str("resume_id", fileID).Str("ext", originalExt).Int64("size", file.Size).Logger()
 	if err := c.SaveUploadedFile(file, destPath); err != nil {
-		fmt.Printf("ERROR: Failed to save file: %v\n", err)
+		uploadLogger.Error().Err(err).Msg("Failed to save uploaded file")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not save file: " + err.Error()})
 		return
 	}
-	fmt.Printf("File saved successfully\n")
 
 	// Extract text content from the uploaded file
-	fmt.Printf("Starting text extraction from: %s\n", destPath)
 	textExtractor := services.NewTextExtractor()
-	textContent, err := textExtractor.ExtractText(destPath)
+	document, err := textExtractor.ExtractDocument(uploadLogger.WithContext(c.Request.Context()), destPath)
+	saveExtractionTrace(c, textExtractor.Trace())
 	if err != nil {
-		fmt.Printf("ERROR: Text extraction failed: %v\n", err)
+		uploadLogger.Warn().Err(err).Msg("Text extraction failed")
 		// Clean up the file if text extraction fails
 		os.Remove(destPath)
+		if c.Request.Context().Err() != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Upload cancelled: " + err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrUnsupportedFileType) {
+			appErr := apperrors.NewAppErrorWithDetails(apperrors.ErrCodeFileType, "Unsupported file type", err.Error(), err)
+			c.JSON(appErr.HTTPStatus, appErr)
+			return
+		}
+		var schemaErr *services.JSONResumeValidationError
+		if errors.As(err, &schemaErr) {
+			appErr := apperrors.NewAppErrorWithDetails(apperrors.ErrCodeValidation, "Invalid JSON Resume", schemaErr.Details(), err)
+			c.JSON(appErr.HTTPStatus, appErr)
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to extract text from file: " + err.Error()})
 		return
 	}
-	fmt.Printf("Text extraction completed successfully\n")
+	textContent := document.Text
 
 	// Validate text content
 	if err := textExtractor.ValidateTextLength(textContent); err != nil {
@@ -87,16 +101,34 @@ func UploadResume(c *gin.Context) {
 		return
 	}
 
+	contact := services.NewContactExtractor().Extract(textContent)
+	var sourceJSON models.JSONDocument
+	if document.JSONResume != nil {
+		// Keep the document as uploaded so it can be exported unchanged
+		contact = document.JSONResume.ContactInfo()
+		if sourceJSON, err = os.ReadFile(destPath); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not read file: " + err.Error()})
+			return
+		}
+	}
+
 	fileSize := int(file.Size)
 	userIDStr := userID.(string)
 	resume := models.Resume{
-		ID:              fileID,
-		UserID:          &userIDStr,
-		Title:           file.Filename,
-		OriginalContent: destPath,     // Store file path
-		ExtractedText:   textContent,  // Store extracted text content
-		FileType:        filepath.Ext(file.Filename),
-		FileSize:        &fileSize,
+		ID:               fileID,
+		UserID:           &userIDStr,
+		Title:            file.Filename,
+		OriginalContent:  destPath,    // Store file path
+		ExtractedText:    textContent, // Store extracted text content
+		FileType:         services.FileExtension(document.FileType),
+		DeclaredType:     services.DeclaredFileType(file.Filename),
+		DetectedType:     document.FileType,
+		FileSize:         &fileSize,
+		Outline:          toModelOutline(document.Outline),
+		OCRPages:         toModelOCRPages(document.OCRPages),
+		ExtractionReport: toModelExtractionReport(document.Report),
+		ContactInfo:      toModelContactInfo(contact),
+		SourceJSON:       sourceJSON,
 	}
 
 	if err := database.GetDB().Create(&resume).Error; err != nil {
@@ -130,6 +162,21 @@ func GetResume(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"resume": resume})
 }
 
+// loadUserResume loads the resume named in the path for the user,
+// writing the error response when it cannot
+func loadUserResume(c *gin.Context, userID string) (models.Resume, bool) {
+	var resume models.Resume
+	if err := database.GetDB().Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&resume).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resume not found"})
+			return resume, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return resume, false
+	}
+	return resume, true
+}
+
 // ListResumes lists user's resumes
 func ListResumes(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -180,6 +227,18 @@ func OptimizeResume(c *gin.Context) {
 		return
 	}
 
+	// Reject unknown models before anything is written to the database
+	model, ok := services.ResolveModel(req.AIModel)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported AI model: " + req.AIModel})
+		return
+	}
+	req.AIModel = model.ID
+
+	// The request context is cancelled when the client disconnects or the
+	// server shuts down, which aborts in-flight scrapes and provider calls
+	ctx := c.Request.Context()
+
 	// Validate that either URL or text is provided
 	if req.JobDescriptionURL == "" && req.JobDescriptionText == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Either job description URL or text must be provided"})
@@ -206,14 +265,20 @@ func OptimizeResume(c *gin.Context) {
 
 	// Get job description
 	jobDescription := req.JobDescriptionText
+	var jobPosting *services.JobPosting
 	if req.JobDescriptionURL != "" {
 		scraper := services.NewJobScraper()
-		fetchedDesc, err := scraper.FetchJobDescription(req.JobDescriptionURL)
+		posting, err := scraper.FetchJobPosting(ctx, req.JobDescriptionURL)
+		if errors.Is(err, services.ErrFetchBlocked) {
+			appErr := apperrors.NewAppErrorWithDetails(apperrors.ErrCodeValidation, "Job description URL not allowed", err.Error(), err)
+			c.JSON(appErr.HTTPStatus, appErr)
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to fetch job description from URL: " + err.Error()})
 			return
 		}
-		jobDescription = fetchedDesc
+		jobDescription, jobPosting = posting.Text(), posting
 	}
 
 	// Get user ID from context for API key lookup
@@ -223,18 +288,30 @@ func OptimizeResume(c *gin.Context) {
 		return
 	}
 
-	// Fetch and decrypt the user's API key
-	apiKey, err := getUserAPIKey(userID.(string), req.UserAPIKeyID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to retrieve API key: " + err.Error()})
-		return
+	// Fetch and decrypt the user's API key. Self-hosted models need none.
+	apiKey := ""
+	if services.ProviderRequiresAPIKey(model.Provider) {
+		var err error
+		apiKey, err = getUserAPIKey(userID.(string), req.UserAPIKeyID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to retrieve API key: " + err.Error()})
+			return
+		}
+
+		if apiKey == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "API key is required. Please add an API key in Settings."})
+			return
+		}
 	}
 
-	if apiKey == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "API key is required. Please add an API key in Settings."})
+	settings, err := loadUserSettings(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user settings: " + err.Error()})
 		return
 	}
 
+	fallbacks := buildFallbackChain(userID.(string), model.ID, settings.FallbackModels)
+
 	// Create optimization session in database
 	sessionID := uuid.New().String()
 	sessionUserID := ""
@@ -252,6 +329,7 @@ func OptimizeResume(c *gin.Context) {
 		JobDescriptionText: &jobDescription,
 		AIModel:           req.AIModel,
 		KeepOnePage:       req.KeepOnePage,
+		PIIRedacted:       settings.RedactPII,
 		Status:            "processing",
 		CreatedAt:         time.Now(),
 		UpdatedAt:         time.Now(),
@@ -267,24 +345,37 @@ func OptimizeResume(c *gin.Context) {
 	optimizationReq := services.OptimizationRequest{
 		ResumeContent:  resumeContent,
 		JobDescription: jobDescription,
-		AIModel:       req.AIModel,
-		KeepOnePage:   req.KeepOnePage,
-		UserAPIKey:    apiKey,
+		AIModel:        req.AIModel,
+		KeepOnePage:    req.KeepOnePage,
+		UserAPIKey:     apiKey,
+		RedactPII:      settings.RedactPII,
+		Fallbacks:      fallbacks,
 	}
 
-	result, err := optimizer.OptimizeResume(optimizationReq)
+	result, err := optimizer.OptimizeResume(ctx, optimizationReq)
 	if err != nil {
-		// Update session status to failed
+		// Mark the session cancelled rather than failed when the request
+		// context ended, so it is not left looking like a provider error
+		status := "failed"
+		if ctx.Err() != nil {
+			status = "cancelled"
+		}
 		database.GetDB().Model(&session).Updates(map[string]interface{}{
-			"status":     "failed",
+			"status":     status,
 			"updated_at": time.Now(),
 		})
+		if status == "cancelled" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Optimization cancelled: " + ctx.Err().Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to optimize resume: " + err.Error()})
 		return
 	}
 
 	// Update session with results
 	session.OptimizedContent = &result.OptimizedContent
+	session.InjectionDetections = toModelDetections(result.Detections)
+	session.ModelUsed = &result.Model
 	session.Status = "completed"
 	session.UpdatedAt = time.Now()
 
@@ -295,12 +386,95 @@ func OptimizeResume(c *gin.Context) {
 
 	// Return the completed session
 	c.JSON(http.StatusOK, gin.H{
-		"session": session,
-		"summary": result.Summary,
-		"changes": result.Changes,
+		"session":           session,
+		"summary":           result.Summary,
+		"changes":           result.Changes,
+		"redacted_fields":   result.RedactedFields,
+		"fallback_attempts": result.FallbackAttempts,
+		"skills":            result.Skills,
+		"job_posting":       jobPosting,
 	})
 }
 
+// saveExtractionTrace writes the extraction trace of a request to
+// EXTRACTION_TRACE_DIR when extraction debugging is on
+func saveExtractionTrace(c *gin.Context, trace *services.ExtractionTrace) {
+	if trace == nil {
+		return
+	}
+
+	dir := os.Getenv("EXTRACTION_TRACE_DIR")
+	if dir == "" {
+		dir = "./extraction_traces"
+	}
+	name := c.GetString("request_id")
+	if name == "" {
+		name = uuid.New().String()
+	}
+
+	logger := zerolog.Ctx(c.Request.Context())
+	path, err := trace.WriteFile(dir, name)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Could not save extraction trace")
+		return
+	}
+	logger.Debug().Str("path", path).Msg("Extraction trace saved")
+}
+
+// toModelOutline converts extracted headings into their stored form
+func toModelOutline(outline []services.OutlineEntry) models.DocumentOutline {
+	stored := make(models.DocumentOutline, 0, len(outline))
+	for _, entry := range outline {
+		stored = append(stored, models.OutlineEntry{
+			Title:  entry.Title,
+			Level:  entry.Level,
+			Page:   entry.Page,
+			Source: entry.Source,
+		})
+	}
+	return stored
+}
+
+// toModelExtractionReport converts an extraction report into its stored form
+func toModelExtractionReport(report services.ExtractionReport) *models.ExtractionReport {
+	return &models.ExtractionReport{
+		Method:        report.Method,
+		Pages:         report.Pages,
+		FilteredRatio: report.FilteredRatio,
+		OCRConfidence: report.OCRConfidence,
+		Language:      report.Language,
+		Warnings:      report.Warnings,
+	}
+}
+
+// toModelOCRPages converts per-page OCR results into their stored form
+func toModelOCRPages(pages []services.OCRPage) models.OCRPages {
+	stored := make(models.OCRPages, 0, len(pages))
+	for _, page := range pages {
+		stored = append(stored, models.OCRPage{
+			Page:       page.Page,
+			Confidence: page.Confidence,
+			Characters: page.Characters,
+			Error:      page.Error,
+		})
+	}
+	return stored
+}
+
+// toModelDetections converts prompt guard findings into their stored form
+func toModelDetections(detections []services.InjectionDetection) models.InjectionDetections {
+	stored := make(models.InjectionDetections, 0, len(detections))
+	for _, d := range detections {
+		stored = append(stored, models.InjectionDetection{
+			Source:  d.Source,
+			Rule:    d.Rule,
+			Excerpt: d.Excerpt,
+			Action:  d.Action,
+		})
+	}
+	return stored
+}
+
 // readResumeContent reads the content from a resume file
 func readResumeContent(filePath string) (string, error) {
 	file, err := os.Open(filePath)
@@ -349,6 +523,47 @@ func getUserAPIKey(userID, keyID string) (string, error) {
 	return decryptedKey, nil
 }
 
+// getUserAPIKeyForProvider fetches and decrypts the user's stored key for a provider
+func getUserAPIKeyForProvider(userID, provider string) (string, error) {
+	var apiKey models.UserAPIKey
+	if err := database.GetDB().Where("user_id = ? AND provider = ?", userID, provider).Order("updated_at DESC").First(&apiKey).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", fmt.Errorf("no API key stored for provider %s", provider)
+		}
+		return "", err
+	}
+
+	return decryptAPIKey(apiKey.EncryptedKey)
+}
+
+// buildFallbackChain resolves the user's configured fallback models into
+// callable candidates. Models whose provider has no stored key are skipped.
+func buildFallbackChain(userID, primaryModel string, fallbackModels []string) []services.ModelCandidate {
+	var chain []services.ModelCandidate
+	seen := map[string]bool{primaryModel: true}
+
+	for _, name := range fallbackModels {
+		model, ok := services.ResolveModel(name)
+		if !ok || seen[model.ID] {
+			continue
+		}
+		seen[model.ID] = true
+
+		apiKey := ""
+		if services.ProviderRequiresAPIKey(model.Provider) {
+			var err error
+			apiKey, err = getUserAPIKeyForProvider(userID, model.Provider)
+			if err != nil || apiKey == "" {
+				continue
+			}
+		}
+
+		chain = append(chain, services.ModelCandidate{Model: model.ID, APIKey: apiKey})
+	}
+
+	return chain
+}
+
 // decryptAPIKey decrypts an encrypted API key
 func decryptAPIKey(encryptedKey string) (string, error) {
 	key := []byte(os.Getenv("ENCRYPTION_KEY"))
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/resume-optimizer/resume-processor/internal/database"
+	"github.com/resume-optimizer/resume-processor/internal/models"
+	"github.com/resume-optimizer/resume-processor/internal/services"
+	"gorm.io/gorm"
+)
+
+// maxFallbackModels limits how many models a fallback chain may hold
+const maxFallbackModels = 5
+
+// GetSettings returns the authenticated user's processor settings
+func GetSettings(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	settings, err := loadUserSettings(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// UpdateSettings updates the authenticated user's processor settings. Only
+// the fields present in the request body are changed.
+func UpdateSettings(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req struct {
+		RedactPII      *bool     `json:"redactPii"`
+		FallbackModels *[]string `json:"fallbackModels"` // Ordered, e.g. ["claude-3-5-sonnet", "gpt-4o", "local"]
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	settings, err := loadUserSettings(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+
+	if req.RedactPII != nil {
+		settings.RedactPII = *req.RedactPII
+	}
+	if req.FallbackModels != nil {
+		if len(*req.FallbackModels) > maxFallbackModels {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d fallback models can be configured", maxFallbackModels)})
+			return
+		}
+		fallbackModels := models.StringList{}
+		for _, name := range *req.FallbackModels {
+			model, ok := services.ResolveModel(name)
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported AI model in fallback chain: " + name})
+				return
+			}
+			fallbackModels = append(fallbackModels, model.ID)
+		}
+		settings.FallbackModels = fallbackModels
+	}
+	settings.UpdatedAt = time.Now()
+
+	if err := database.GetDB().Save(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save settings: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// loadUserSettings returns the stored settings for a user, or the defaults if none are stored yet
+func loadUserSettings(userID string) (models.UserSettings, error) {
+	var settings models.UserSettings
+	err := database.GetDB().Where("user_id = ?", userID).First(&settings).Error
+	if err == gorm.ErrRecordNotFound {
+		now := time.Now()
+		return models.UserSettings{UserID: userID, CreatedAt: now, UpdatedAt: now}, nil
+	}
+	return settings, err
+}
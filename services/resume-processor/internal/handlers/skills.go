@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/resume-optimizer/resume-processor/internal/database"
+	"github.com/resume-optimizer/resume-processor/internal/models"
+	"github.com/resume-optimizer/resume-processor/internal/services"
+	apperrors "github.com/resume-optimizer/shared/errors"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// maxNormalizeTerms limits how many terms one normalize request may hold
+const maxNormalizeTerms = 500
+
+// skillRequest is the body of the admin skill endpoints
+type skillRequest struct {
+	ID        string   `json:"id"` // Only read on create; updates take the ID from the path
+	Name      string   `json:"name" binding:"required"`
+	Category  string   `json:"category" binding:"required"`
+	Parent    string   `json:"parent"`
+	Ambiguous bool     `json:"ambiguous"`
+	Aliases   []string `json:"aliases"`
+}
+
+// LoadSkillOverrides applies the admin changes stored in the database to
+// the embedded skills taxonomy. Overrides that no longer fit the taxonomy
+// are logged and skipped.
+func LoadSkillOverrides() {
+	var overrides []models.SkillOverride
+	if err := database.GetDB().Order("updated_at").Find(&overrides).Error; err != nil {
+		log.Error().Err(err).Msg("Failed to load skill overrides")
+		return
+	}
+
+	taxonomy := services.DefaultSkillTaxonomy()
+	for _, override := range overrides {
+		var err error
+		if override.Deleted {
+			err = taxonomy.Remove(override.ID)
+		} else {
+			err = taxonomy.Upsert(skillFromOverride(override))
+		}
+		if err != nil && !(override.Deleted && errors.Is(err, services.ErrUnknownSkill)) {
+			log.Warn().Err(err).Str("skill", override.ID).Msg("Skipping skill override")
+		}
+	}
+	log.Info().Int("overrides", len(overrides)).Msg("Skills taxonomy loaded")
+}
+
+// ListSkills lists the skills of the taxonomy, optionally of one category
+func ListSkills(c *gin.Context) {
+	taxonomy := services.DefaultSkillTaxonomy()
+	c.JSON(http.StatusOK, gin.H{
+		"categories": taxonomy.Categories(),
+		"skills":     taxonomy.Skills(c.Query("category")),
+	})
+}
+
+// GetSkill returns a skill with its place in the hierarchy
+func GetSkill(c *gin.Context) {
+	taxonomy := services.DefaultSkillTaxonomy()
+	skill, ok := taxonomy.Skill(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"skill":     skill,
+		"ancestors": taxonomy.Ancestors(skill.ID),
+		"children":  taxonomy.Children(skill.ID),
+	})
+}
+
+// NormalizeSkills maps skill terms to their canonical skills and, when text
+// is given, extracts the skills mentioned in it
+func NormalizeSkills(c *gin.Context) {
+	var req struct {
+		Terms []string `json:"terms"`
+		Text  string   `json:"text"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if len(req.Terms) == 0 && req.Text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either terms or text must be provided"})
+		return
+	}
+	if len(req.Terms) > maxNormalizeTerms {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many terms"})
+		return
+	}
+
+	taxonomy := services.DefaultSkillTaxonomy()
+	normalized := []gin.H{}
+	unknown := []string{}
+	for _, term := range req.Terms {
+		skill, ok := taxonomy.Normalize(term)
+		if !ok {
+			unknown = append(unknown, term)
+			continue
+		}
+		normalized = append(normalized, gin.H{"term": term, "skill": skill})
+	}
+
+	response := gin.H{"normalized": normalized, "unknown": unknown}
+	if req.Text != "" {
+		response["extracted"] = taxonomy.Extract(req.Text)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateSkill adds a skill to the taxonomy
+func CreateSkill(c *gin.Context) {
+	var req skillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if _, exists := services.DefaultSkillTaxonomy().Skill(req.ID); exists {
+		appErr := apperrors.NewAppError(apperrors.ErrCodeDuplicate, "Skill already exists: "+req.ID, nil)
+		c.JSON(appErr.HTTPStatus, appErr)
+		return
+	}
+	saveSkill(c, req)
+}
+
+// UpdateSkill replaces a skill of the taxonomy, or adds it under the ID in the path
+func UpdateSkill(c *gin.Context) {
+	var req skillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	req.ID = c.Param("id")
+	saveSkill(c, req)
+}
+
+// DeleteSkill removes a skill from the taxonomy
+func DeleteSkill(c *gin.Context) {
+	id := c.Param("id")
+	taxonomy := services.DefaultSkillTaxonomy()
+	if err := taxonomy.ValidateRemove(id); err != nil {
+		if errors.Is(err, services.ErrUnknownSkill) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+			return
+		}
+		appErr := apperrors.NewAppErrorWithDetails(apperrors.ErrCodeValidation, "Skill cannot be deleted", err.Error(), err)
+		c.JSON(appErr.HTTPStatus, appErr)
+		return
+	}
+
+	override := models.SkillOverride{
+		ID:        id,
+		Aliases:   models.StringList{},
+		Deleted:   true,
+		UpdatedBy: c.GetString("userEmail"),
+		UpdatedAt: time.Now(),
+	}
+	if err := saveSkillOverride(&override); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+	if err := taxonomy.Remove(id); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Skill changed while deleting: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Skill deleted", "id": id})
+}
+
+// saveSkill validates a skill, stores it as an override and applies it to
+// the taxonomy
+func saveSkill(c *gin.Context, req skillRequest) {
+	skill := services.Skill{
+		ID:        req.ID,
+		Name:      req.Name,
+		Category:  req.Category,
+		Parent:    req.Parent,
+		Ambiguous: req.Ambiguous,
+		Aliases:   req.Aliases,
+	}
+	if skill.Aliases == nil {
+		skill.Aliases = []string{}
+	}
+
+	taxonomy := services.DefaultSkillTaxonomy()
+	if err := taxonomy.Validate(skill); err != nil {
+		appErr := apperrors.NewAppErrorWithDetails(apperrors.ErrCodeValidation, "Invalid skill", err.Error(), err)
+		c.JSON(appErr.HTTPStatus, appErr)
+		return
+	}
+
+	override := models.SkillOverride{
+		ID:        skill.ID,
+		Name:      skill.Name,
+		Category:  skill.Category,
+		Parent:    skill.Parent,
+		Ambiguous: skill.Ambiguous,
+		Aliases:   models.StringList(skill.Aliases),
+		UpdatedBy: c.GetString("userEmail"),
+		UpdatedAt: time.Now(),
+	}
+	if err := saveSkillOverride(&override); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+	if err := taxonomy.Upsert(skill); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Skill changed while saving: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"skill": skill})
+}
+
+// saveSkillOverride stores an override, replacing an earlier one for the
+// same skill but keeping when it was first created
+func saveSkillOverride(override *models.SkillOverride) error {
+	var existing models.SkillOverride
+	err := database.GetDB().First(&existing, "id = ?", override.ID).Error
+	switch {
+	case err == nil:
+		override.CreatedAt = existing.CreatedAt
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		override.CreatedAt = override.UpdatedAt
+	default:
+		return err
+	}
+	return database.GetDB().Save(override).Error
+}
+
+// skillFromOverride converts a stored override into a taxonomy skill
+func skillFromOverride(override models.SkillOverride) services.Skill {
+	aliases := []string(override.Aliases)
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return services.Skill{
+		ID:        override.ID,
+		Name:      override.Name,
+		Category:  override.Category,
+		Parent:    override.Parent,
+		Ambiguous: override.Ambiguous,
+		Aliases:   aliases,
+	}
+}
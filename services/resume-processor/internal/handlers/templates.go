@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/resume-optimizer/resume-processor/internal/database"
+	"github.com/resume-optimizer/resume-processor/internal/models"
+	"github.com/resume-optimizer/resume-processor/internal/services"
+	apperrors "github.com/resume-optimizer/shared/errors"
+	"gorm.io/gorm"
+)
+
+// templateRequest is the body of the template create and update endpoints
+type templateRequest struct {
+	Name           string   `json:"name" binding:"required,max=100"`
+	Description    string   `json:"description" binding:"max=1000"`
+	Format         string   `json:"format" binding:"required"` // latex or markdown
+	Body           string   `json:"body" binding:"required"`
+	RequiredFields []string `json:"requiredFields"` // e.g. ["name", "contact", "section:Experience"]
+	Public         bool     `json:"public"`
+}
+
+// ListResumeTemplates lists the user's templates and the public ones,
+// optionally of one format
+func ListResumeTemplates(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	query := database.GetDB().Where("user_id = ? OR public = ?", userID.(string), true)
+	if format := c.Query("format"); format != "" {
+		query = query.Where("format = ?", format)
+	}
+	var templates []models.ResumeTemplate
+	if err := query.Order("name").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"builtin": gin.H{
+			services.ExportFormatLaTeX:    services.ExportTemplates(services.ExportFormatLaTeX),
+			services.ExportFormatMarkdown: services.ExportTemplates(services.ExportFormatMarkdown),
+		},
+	})
+}
+
+// GetResumeTemplate returns one of the user's templates or a public one
+func GetResumeTemplate(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	template, ok := loadResumeTemplate(c, c.Param("id"), userID.(string))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+// CreateResumeTemplate stores a new template after validating it
+func CreateResumeTemplate(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req templateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if !checkResumeTemplate(c, req.Format, req.Body, req.RequiredFields) {
+		return
+	}
+
+	template := models.ResumeTemplate{UserID: userID.(string)}
+	applyTemplateRequest(&template, req)
+	if err := database.GetDB().Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save template: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"template": template})
+}
+
+// UpdateResumeTemplate replaces one of the user's templates
+func UpdateResumeTemplate(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req templateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	template, ok := loadOwnResumeTemplate(c, userID.(string))
+	if !ok {
+		return
+	}
+	if !checkResumeTemplate(c, req.Format, req.Body, req.RequiredFields) {
+		return
+	}
+
+	applyTemplateRequest(&template, req)
+	template.UpdatedAt = time.Now()
+	if err := database.GetDB().Save(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save template: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+// DeleteResumeTemplate deletes one of the user's templates
+func DeleteResumeTemplate(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	template, ok := loadOwnResumeTemplate(c, userID.(string))
+	if !ok {
+		return
+	}
+	if err := database.GetDB().Delete(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted", "id": template.ID})
+}
+
+// ValidateResumeTemplate checks a template without saving it: its syntax,
+// the sandbox rules and its required fields, then a trial render of the
+// sample resume to catch references to fields that do not exist
+func ValidateResumeTemplate(c *gin.Context) {
+	var req struct {
+		Format         string   `json:"format" binding:"required"`
+		Body           string   `json:"body" binding:"required"`
+		RequiredFields []string `json:"requiredFields"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	_, err := services.NewUserTemplateExporter(req.Format, req.Body, req.RequiredFields)
+	if err == nil {
+		// Required fields are checked per resume, not against the sample
+		var exporter *services.TemplateExporter
+		if exporter, err = services.NewUserTemplateExporter(req.Format, req.Body, nil); err == nil {
+			_, err = exporter.Render(services.SampleResumeDocument())
+		}
+	}
+
+	var invalid *services.TemplateValidationError
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"valid": true, "errors": []string{}})
+	case errors.Is(err, services.ErrUnknownExportFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export format: " + req.Format})
+	case errors.As(err, &invalid):
+		c.JSON(http.StatusOK, gin.H{"valid": false, "errors": invalid.Problems})
+	default:
+		c.JSON(http.StatusOK, gin.H{"valid": false, "errors": []string{err.Error()}})
+	}
+}
+
+// PreviewResumeTemplate renders a template, stored (templateId) or inline
+// (format and body), with one of the user's resumes (resumeId), the output
+// of an optimization session (sessionId) or the sample resume
+func PreviewResumeTemplate(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req struct {
+		TemplateID     string   `json:"templateId"`
+		Format         string   `json:"format"`
+		Body           string   `json:"body"`
+		RequiredFields []string `json:"requiredFields"`
+		ResumeID       string   `json:"resumeId"`
+		SessionID      string   `json:"sessionId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if req.TemplateID == "" && req.Body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either templateId or format and body must be provided"})
+		return
+	}
+
+	if req.TemplateID != "" {
+		template, ok := loadResumeTemplate(c, req.TemplateID, userID.(string))
+		if !ok {
+			return
+		}
+		req.Format, req.Body, req.RequiredFields = template.Format, template.Body, template.RequiredFields
+	} else if !checkResumeTemplate(c, req.Format, req.Body, req.RequiredFields) {
+		return
+	}
+
+	doc := services.SampleResumeDocument()
+	db := database.GetDB()
+	switch {
+	case req.ResumeID != "":
+		var resume models.Resume
+		if err := db.Where("id = ? AND user_id = ?", req.ResumeID, userID.(string)).First(&resume).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Resume not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+			return
+		}
+		doc = resumeDocument(resume)
+	case req.SessionID != "":
+		var session models.OptimizationSession
+		if err := db.Where("id = ? AND user_id = ?", req.SessionID, userID.(string)).First(&session).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Optimization session not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+			return
+		}
+		if session.OptimizedContent == nil || *session.OptimizedContent == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Optimization session has no completed content to preview"})
+			return
+		}
+		doc = services.ResumeDocumentFromText(*session.OptimizedContent)
+	}
+
+	exporter, err := services.NewUserTemplateExporter(req.Format, req.Body, req.RequiredFields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load template: " + err.Error()})
+		return
+	}
+	file, err := exporter.Render(doc)
+	if err != nil {
+		writeTemplateRenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"format":       req.Format,
+		"content_type": file.ContentType,
+		"output":       string(file.Data),
+	})
+}
+
+// loadResumeTemplate loads a template the user owns or that is public,
+// writing the error response when there is none
+func loadResumeTemplate(c *gin.Context, id, userID string) (models.ResumeTemplate, bool) {
+	var template models.ResumeTemplate
+	err := database.GetDB().Where("id = ? AND (user_id = ? OR public = ?)", id, userID, true).First(&template).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+			return template, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return template, false
+	}
+	return template, true
+}
+
+// loadOwnResumeTemplate loads the template named in the path if the user
+// owns it. Public templates of other users are visible but read-only.
+func loadOwnResumeTemplate(c *gin.Context, userID string) (models.ResumeTemplate, bool) {
+	template, ok := loadResumeTemplate(c, c.Param("id"), userID)
+	if ok && template.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can change a template"})
+		return template, false
+	}
+	return template, ok
+}
+
+// checkResumeTemplate validates a template, writing the error response when
+// it is not usable
+func checkResumeTemplate(c *gin.Context, format, body string, requiredFields []string) bool {
+	_, err := services.NewUserTemplateExporter(format, body, requiredFields)
+	var invalid *services.TemplateValidationError
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, services.ErrUnknownExportFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export format: " + format})
+	case errors.As(err, &invalid):
+		appErr := apperrors.NewAppErrorWithDetails(apperrors.ErrCodeValidation, "Invalid template", err.Error(), err)
+		c.JSON(appErr.HTTPStatus, appErr)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load template: " + err.Error()})
+	}
+	return false
+}
+
+// applyTemplateRequest copies the editable fields of a request to a template
+func applyTemplateRequest(template *models.ResumeTemplate, req templateRequest) {
+	template.Name = req.Name
+	template.Description = req.Description
+	template.Format = req.Format
+	template.Body = req.Body
+	template.RequiredFields = models.StringList(req.RequiredFields)
+	if template.RequiredFields == nil {
+		template.RequiredFields = models.StringList{}
+	}
+	template.Public = req.Public
+}
+
+// writeTemplateRenderError reports why a user-defined template could not
+// render a resume. Missing fields, sandbox limits and template mistakes are
+// all the template author's to fix, so they are validation errors.
+func writeTemplateRenderError(c *gin.Context, err error) {
+	var missing *services.MissingFieldsError
+	message := "Template rendering failed"
+	if errors.As(err, &missing) {
+		message = "Resume is missing fields required by the template"
+	}
+	appErr := apperrors.NewAppErrorWithDetails(apperrors.ErrCodeValidation, message, err.Error(), err)
+	c.JSON(appErr.HTTPStatus, appErr)
+}
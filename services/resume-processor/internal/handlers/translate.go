@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/resume-optimizer/resume-processor/internal/database"
+	"github.com/resume-optimizer/resume-processor/internal/models"
+	"github.com/resume-optimizer/resume-processor/internal/services"
+	"gorm.io/gorm"
+)
+
+// translateRequest is the request body shared by the translation endpoints
+type translateRequest struct {
+	TargetLocale string `json:"targetLocale" binding:"required"`
+	AIModel      string `json:"aiModel" binding:"required"`
+	UserAPIKeyID string `json:"userApiKey"`
+}
+
+// TranslateResume translates a resume's extracted text into another locale
+func TranslateResume(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req translateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	var resume models.Resume
+	if err := database.GetDB().Where("id = ? AND user_id = ?", c.Param("id"), userID.(string)).First(&resume).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resume not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+
+	if resume.ExtractedText == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No extracted text content found for this resume"})
+		return
+	}
+
+	translateContent(c, userID.(string), req, resume.ID, nil, resume.ExtractedText)
+}
+
+// TranslateOptimization translates the optimized content of a completed session into another locale
+func TranslateOptimization(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req translateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	var session models.OptimizationSession
+	if err := database.GetDB().Where("id = ? AND user_id = ?", c.Param("id"), userID.(string)).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Optimization session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+
+	if session.Status != "completed" || session.OptimizedContent == nil || *session.OptimizedContent == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Optimization session has no completed content to translate"})
+		return
+	}
+
+	translateContent(c, userID.(string), req, session.ResumeID, &session.ID, *session.OptimizedContent)
+}
+
+// ListResumeRevisions lists the language-tagged revisions of a resume, optionally filtered by ?language=
+func ListResumeRevisions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	query := database.GetDB().Where("resume_id = ? AND user_id = ?", c.Param("id"), userID.(string))
+	if language := c.Query("language"); language != "" {
+		query = query.Where("language = ?", language)
+	}
+
+	var revisions []models.ResumeRevision
+	if err := query.Order("created_at DESC").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DB error: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
+
+// translateContent runs the translation and stores the result as a language-tagged revision
+func translateContent(c *gin.Context, userID string, req translateRequest, resumeID string, sessionID *string, content string) {
+	model, ok := services.ResolveModel(req.AIModel)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported AI model: " + req.AIModel})
+		return
+	}
+	req.AIModel = model.ID
+
+	if _, ok := services.GetLocaleProfile(req.TargetLocale); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             "Unsupported target locale: " + req.TargetLocale,
+			"supported_locales": services.SupportedLocales(),
+		})
+		return
+	}
+
+	apiKey := ""
+	if services.ProviderRequiresAPIKey(model.Provider) {
+		var err error
+		apiKey, err = getUserAPIKey(userID, req.UserAPIKeyID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to retrieve API key: " + err.Error()})
+			return
+		}
+	}
+
+	settings, err := loadUserSettings(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user settings: " + err.Error()})
+		return
+	}
+
+	optimizer := services.NewAIOptimizer()
+	result, err := optimizer.TranslateResume(c.Request.Context(), services.TranslationRequest{
+		Content:      content,
+		TargetLocale: req.TargetLocale,
+		AIModel:      req.AIModel,
+		UserAPIKey:   apiKey,
+		RedactPII:    settings.RedactPII,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to translate resume: " + err.Error()})
+		return
+	}
+
+	revision := models.ResumeRevision{
+		ID:             uuid.New().String(),
+		ResumeID:       resumeID,
+		SessionID:      sessionID,
+		UserID:         userID,
+		Kind:           "translation",
+		Language:       result.Locale.Code,
+		SourceLanguage: result.SourceLanguage,
+		Content:        result.TranslatedContent,
+		Notes:          strings.Join(result.Notes, "\n"),
+		AIModel:        req.AIModel,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := database.GetDB().Create(&revision).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save translation: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revision":        revision,
+		"locale":          result.Locale,
+		"notes":           result.Notes,
+		"redacted_fields": result.RedactedFields,
+	})
+}
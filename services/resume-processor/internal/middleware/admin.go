@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin allows only the users listed in ADMIN_EMAILS (comma
+// separated) through. It must run after RequireAuth.
+func RequireAdmin() gin.HandlerFunc {
+	admins := map[string]bool{}
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			admins[email] = true
+		}
+	}
+
+	return func(c *gin.Context) {
+		if !admins[strings.ToLower(c.GetString("userEmail"))] {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Admin access required",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
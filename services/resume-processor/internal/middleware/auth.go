@@ -55,6 +55,8 @@ func RequireAuth() gin.HandlerFunc {
 		// Set user information in context
 		c.Set("userID", claims.UserID)
 		c.Set("userEmail", claims.Email)
+		logger := Logger(c).With().Str("user_id", claims.UserID).Logger()
+		setRequestLogger(c, &logger)
 		c.Next()
 	}
 }
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// RequestLogger makes the request-scoped logger set by the shared
+// RequestIDMiddleware available from the request context, so services log
+// with the request ID through zerolog.Ctx
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setRequestLogger(c, Logger(c))
+		c.Next()
+	}
+}
+
+// Logger returns the request-scoped logger, or the global logger outside a request
+func Logger(c *gin.Context) *zerolog.Logger {
+	if value, ok := c.Get("logger"); ok {
+		if logger, ok := value.(*zerolog.Logger); ok {
+			return logger
+		}
+	}
+	return &log.Logger
+}
+
+// setRequestLogger replaces the request-scoped logger in the gin and request contexts
+func setRequestLogger(c *gin.Context, logger *zerolog.Logger) {
+	c.Set("logger", logger)
+	c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context()))
+}
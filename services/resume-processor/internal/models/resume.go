@@ -1,35 +1,54 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 type Resume struct {
-	ID              string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	UserID          *string   `json:"user_id" gorm:"type:uuid"`
-	Title           string    `json:"title"`
-	OriginalContent string    `json:"original_content" gorm:"not null;type:text"` // File path
-	ExtractedText   string    `json:"extracted_text" gorm:"type:text"`         // Extracted text content
-	FileType        string    `json:"file_type" gorm:"not null;default:pdf"`
-	FileSize        *int      `json:"file_size"`
-	IsActive        bool      `json:"is_active" gorm:"default:true"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID               string            `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID           *string           `json:"user_id" gorm:"type:uuid"`
+	Title            string            `json:"title"`
+	OriginalContent  string            `json:"original_content" gorm:"not null;type:text"` // File path
+	ExtractedText    string            `json:"extracted_text" gorm:"type:text"`            // Extracted text content
+	FileType         string            `json:"file_type" gorm:"not null;default:pdf"`
+	DeclaredType     string            `json:"declared_type"` // MIME type implied by the uploaded filename
+	DetectedType     string            `json:"detected_type"` // MIME type detected from the file content
+	FileSize         *int              `json:"file_size"`
+	IsActive         bool              `json:"is_active" gorm:"default:true"`
+	Outline          DocumentOutline   `json:"outline" gorm:"type:jsonb"`           // Headings detected during extraction
+	OCRPages         OCRPages          `json:"ocr_pages" gorm:"type:jsonb"`         // Per-page OCR confidence for image-based PDFs
+	ExtractionReport *ExtractionReport `json:"extraction_report" gorm:"type:jsonb"` // How the text was extracted and what may have gone wrong
+	ContactInfo      *ContactInfo      `json:"contact_info" gorm:"type:jsonb"`      // Contact details extracted from the text, with user corrections
+	SourceJSON       JSONDocument      `json:"-" gorm:"type:jsonb"`                 // The uploaded document, for JSON Resume uploads
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
 	
 	User                User                  `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	OptimizationSessions []OptimizationSession `json:"optimization_sessions,omitempty" gorm:"foreignKey:ResumeID"`
 }
 
 type OptimizationSession struct {
-	ID                 string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	UserID             string    `json:"user_id" gorm:"not null;type:uuid"`
-	ResumeID           string    `json:"resume_id" gorm:"not null;type:uuid"`
-	JobDescriptionURL  *string   `json:"job_description_url"`
-	JobDescriptionText *string   `json:"job_description_text" gorm:"type:text"`
-	AIModel            string    `json:"ai_model" gorm:"not null"`
-	KeepOnePage        bool      `json:"keep_one_page" gorm:"default:false"`
-	OptimizedContent   *string   `json:"optimized_content" gorm:"type:text"`
-	Status             string    `json:"status" gorm:"default:pending"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	ID                  string              `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID              string              `json:"user_id" gorm:"not null;type:uuid"`
+	ResumeID            string              `json:"resume_id" gorm:"not null;type:uuid"`
+	JobDescriptionURL   *string             `json:"job_description_url"`
+	JobDescriptionText  *string             `json:"job_description_text" gorm:"type:text"`
+	AIModel             string              `json:"ai_model" gorm:"not null"`
+	KeepOnePage         bool                `json:"keep_one_page" gorm:"default:false"`
+	OptimizedContent    *string             `json:"optimized_content" gorm:"type:text"`
+	Status              string              `json:"status" gorm:"default:pending"`
+	PIIRedacted         bool                `json:"pii_redacted" gorm:"default:false"`      // Resume PII was replaced with placeholders before the provider call
+	InjectionDetections InjectionDetections `json:"injection_detections" gorm:"type:jsonb"` // Prompt injection and output schema findings
+	ModelUsed           *string             `json:"model_used"`                             // Model that produced the result; differs from AIModel after a fallback
+	BatchID             *string             `json:"batch_id" gorm:"type:uuid;index"`        // Set when the session is an item of a batch optimization
+	BatchIndex          *int                `json:"batch_index"`                            // Position of the job description in the batch request
+	Attempts            int                 `json:"attempts" gorm:"default:0"`              // Optimization attempts made, including automatic retries
+	Error               *string             `json:"error" gorm:"type:text"`                 // Reason for the last failure
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
 	
 	User     User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Resume   Resume     `json:"resume,omitempty" gorm:"foreignKey:ResumeID"`
@@ -47,6 +66,21 @@ type Feedback struct {
 	Session OptimizationSession `json:"session,omitempty" gorm:"foreignKey:SessionID"`
 }
 
+type OptimizationBatch struct {
+	ID           string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID       string    `json:"user_id" gorm:"not null;type:uuid;index"`
+	ResumeID     string    `json:"resume_id" gorm:"not null;type:uuid"`
+	AIModel      string    `json:"ai_model" gorm:"not null"`
+	KeepOnePage  bool      `json:"keep_one_page" gorm:"default:false"`
+	UserAPIKeyID *string   `json:"-" gorm:"type:uuid"` // Key used for the batch, looked up again when an item is retried
+	Status       string    `json:"status" gorm:"default:pending"`
+	TotalItems   int       `json:"total_items"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Sessions []OptimizationSession `json:"sessions,omitempty" gorm:"foreignKey:BatchID"`
+}
+
 type UserAPIKey struct {
 	ID           string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	UserID       string    `json:"user_id" gorm:"not null;type:uuid"`
@@ -56,4 +90,240 @@ type UserAPIKey struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 	
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
-}
\ No newline at end of file
+}
+
+// ResumeRevision is a derived version of a resume's text, such as a
+// translation, tagged with its language
+type ResumeRevision struct {
+	ID             string    `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ResumeID       string    `json:"resume_id" gorm:"not null;type:uuid;index"`
+	SessionID      *string   `json:"session_id" gorm:"type:uuid;index"` // Set when the revision derives from an optimization session
+	UserID         string    `json:"user_id" gorm:"not null;type:uuid"`
+	Kind           string    `json:"kind" gorm:"not null;default:translation"` // How the revision was derived; only translation so far
+	Language       string    `json:"language" gorm:"not null;index"`           // BCP 47 tag, e.g. de-DE
+	SourceLanguage string    `json:"source_language"`                          // BCP 47 tag of the text the revision was derived from
+	Content        string    `json:"content" gorm:"type:text"`
+	Notes          string    `json:"notes" gorm:"type:text"` // Newline-separated conventions applied and things to review
+	AIModel        string    `json:"ai_model"`               // ID of the model that produced the revision
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// InjectionDetection records instruction-like content found in a job
+// description, or a model reply that did not match the expected schema
+type InjectionDetection struct {
+	Source  string `json:"source"` // job_description or model_output
+	Rule    string `json:"rule"`
+	Excerpt string `json:"excerpt"`
+	Action  string `json:"action"` // stripped or flagged
+}
+
+// InjectionDetections is stored as a JSONB column
+type InjectionDetections []InjectionDetection
+
+// Value implements driver.Valuer
+func (d InjectionDetections) Value() (driver.Value, error) {
+	if d == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(d)
+	return string(data), err
+}
+
+// Scan implements sql.Scanner
+func (d *InjectionDetections) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	default:
+		return fmt.Errorf("cannot scan %T into InjectionDetections", value)
+	}
+}
+
+// OutlineEntry is a heading found in an uploaded resume
+type OutlineEntry struct {
+	Title  string `json:"title"`
+	Level  int    `json:"level"`
+	Page   int    `json:"page,omitempty"`
+	Source string `json:"source"` // font_size or bookmark
+}
+
+// DocumentOutline is stored as a JSONB column
+type DocumentOutline []OutlineEntry
+
+// Value implements driver.Valuer
+func (o DocumentOutline) Value() (driver.Value, error) {
+	if o == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(o)
+	return string(data), err
+}
+
+// Scan implements sql.Scanner
+func (o *DocumentOutline) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*o = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, o)
+	case string:
+		return json.Unmarshal([]byte(v), o)
+	default:
+		return fmt.Errorf("cannot scan %T into DocumentOutline", value)
+	}
+}
+
+// OCRPage is the OCR outcome of one page of an uploaded resume
+type OCRPage struct {
+	Page       int     `json:"page"`
+	Confidence float64 `json:"confidence"` // Mean word confidence, 0-100
+	Characters int     `json:"characters"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// OCRPages is stored as a JSONB column
+type OCRPages []OCRPage
+
+// Value implements driver.Valuer
+func (p OCRPages) Value() (driver.Value, error) {
+	if p == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(p)
+	return string(data), err
+}
+
+// Scan implements sql.Scanner
+func (p *OCRPages) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return fmt.Errorf("cannot scan %T into OCRPages", value)
+	}
+}
+
+// ExtractionReport records how the text of an uploaded resume was obtained
+type ExtractionReport struct {
+	Method        string   `json:"method"` // native, aggressive or ocr
+	Pages         int      `json:"pages,omitempty"`
+	FilteredRatio float64  `json:"filtered_ratio"`
+	OCRConfidence *float64 `json:"ocr_confidence,omitempty"`
+	Language      string   `json:"language,omitempty"`
+	Warnings      []string `json:"warnings"`
+}
+
+// Value implements driver.Valuer
+func (r ExtractionReport) Value() (driver.Value, error) {
+	data, err := json.Marshal(r)
+	return string(data), err
+}
+
+// Scan implements sql.Scanner
+func (r *ExtractionReport) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*r = ExtractionReport{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return fmt.Errorf("cannot scan %T into ExtractionReport", value)
+	}
+}
+
+// ContactField is one contact value of a resume
+type ContactField struct {
+	Value      string  `json:"value"`
+	Confidence float64 `json:"confidence"`          // 0-1; 1 for values entered by the user
+	Corrected  bool    `json:"corrected,omitempty"` // Set by the user rather than extracted
+}
+
+// ContactInfo holds the contact details of a resume
+type ContactInfo struct {
+	Name      *ContactField  `json:"name,omitempty"`
+	Emails    []ContactField `json:"emails"`
+	Phones    []ContactField `json:"phones"` // E.164
+	City      *ContactField  `json:"city,omitempty"`
+	Region    *ContactField  `json:"region,omitempty"`
+	LinkedIn  *ContactField  `json:"linkedin,omitempty"`
+	GitHub    *ContactField  `json:"github,omitempty"`
+	Portfolio *ContactField  `json:"portfolio,omitempty"`
+}
+
+// Value implements driver.Valuer
+func (c ContactInfo) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	return string(data), err
+}
+
+// Scan implements sql.Scanner
+func (c *ContactInfo) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = ContactInfo{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return fmt.Errorf("cannot scan %T into ContactInfo", value)
+	}
+}
+
+// JSONDocument is a JSON document stored as uploaded
+type JSONDocument []byte
+
+// Value implements driver.Valuer
+func (d JSONDocument) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return nil, nil
+	}
+	return string(d), nil
+}
+
+// Scan implements sql.Scanner
+func (d *JSONDocument) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = nil
+		return nil
+	case []byte:
+		*d = append(JSONDocument(nil), v...)
+		return nil
+	case string:
+		*d = JSONDocument(v)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into JSONDocument", value)
+	}
+}
+
+// ResumeTemplate is a user-defined export template. Public templates can be
+// used by every user; only the owner can change them.
+type ResumeTemplate struct {
+	ID             string     `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID         string     `json:"user_id" gorm:"not null;type:uuid;index"`
+	Name           string     `json:"name" gorm:"not null"`
+	Description    string     `json:"description" gorm:"type:text"`
+	Format         string     `json:"format" gorm:"not null"`            // Export format the template renders: latex or markdown
+	Body           string     `json:"body" gorm:"type:text;not null"`    // Go text/template source
+	RequiredFields StringList `json:"required_fields" gorm:"type:jsonb"` // Fields a resume must have, e.g. ["name", "section:Experience"]
+	Public         bool       `json:"public" gorm:"default:false;index"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
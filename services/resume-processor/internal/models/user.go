@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -16,4 +19,56 @@ type User struct {
 	Resumes             []Resume             `json:"resumes,omitempty" gorm:"foreignKey:UserID"`
 	OptimizationSessions []OptimizationSession `json:"optimization_sessions,omitempty" gorm:"foreignKey:UserID"`
 	APIKeys             []UserAPIKey         `json:"api_keys,omitempty" gorm:"foreignKey:UserID"`
-}
\ No newline at end of file
+}
+
+// UserSettings holds a user's preferences for AI requests. Users without a
+// stored row get the defaults, see loadUserSettings in the handlers.
+type UserSettings struct {
+	UserID         string     `json:"user_id" gorm:"primaryKey;type:uuid"` // One row per user
+	RedactPII      bool       `json:"redact_pii" gorm:"default:false"`     // Redact PII from resumes before sending them to AI providers
+	FallbackModels StringList `json:"fallback_models" gorm:"type:jsonb"`   // Ordered models to try when the requested one is unavailable
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// StringList is a list of strings stored as a JSONB column
+type StringList []string
+
+// Value implements driver.Valuer
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	return string(data), err
+}
+
+// Scan implements sql.Scanner
+func (l *StringList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+}
+
+// SkillOverride is an admin change to the built-in skills taxonomy, applied
+// on top of the embedded data at startup
+type SkillOverride struct {
+	ID        string     `json:"id" gorm:"primaryKey"` // Skill ID
+	Name      string     `json:"name"`
+	Category  string     `json:"category"`
+	Parent    string     `json:"parent"`
+	Ambiguous bool       `json:"ambiguous" gorm:"default:false"`
+	Aliases   StringList `json:"aliases" gorm:"type:jsonb"`
+	Deleted   bool       `json:"deleted" gorm:"default:false"` // Removes the skill rather than defining it
+	UpdatedBy string     `json:"updated_by"`                   // Email of the admin who made the change
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
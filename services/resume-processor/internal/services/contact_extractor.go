@@ -0,0 +1,404 @@
+package services
+
+import (
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Contact details are expected in the header of a resume; matches further
+// down are more likely to belong to references or employers
+const contactHeaderLines = 12
+
+// Confidence values for contact fields
+const (
+	contactConfidenceCertain   = 0.95 // Unambiguous format in the header
+	contactConfidenceLikely    = 0.8
+	contactConfidenceGuess     = 0.6
+	contactConfidenceOutOfHead = 0.5 // Found outside the header
+)
+
+var (
+	contactLinkedInPattern = regexp.MustCompile(`(?i)\b(?:https?://)?(?:[a-z]{2,3}\.)?linkedin\.com/(?:in|pub)/([A-Za-z0-9_%-]+)`)
+	contactGitHubPattern   = regexp.MustCompile(`(?i)\b(?:https?://)?(?:www\.)?github\.com/([A-Za-z0-9](?:[A-Za-z0-9-]{0,38}))(/[A-Za-z0-9_.-]+)?`)
+	contactURLPattern      = regexp.MustCompile(`(?i)\b(?:https?://)?(?:www\.)?((?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+(?:[a-z]{2,24}))(/[^\s,;|)]*)?`)
+	contactPhonePattern    = regexp.MustCompile(`(?:\+|00)?\d[\d\s().-]{6,20}\d`)
+	contactLocationPattern = regexp.MustCompile(`\b(\p{Lu}[\p{L}.'-]+(?:[ -]\p{Lu}[\p{L}.'-]+){0,2}),\s*(\p{Lu}[\p{L}.]+(?:\s\p{Lu}[\p{L}]+){0,2})\b`)
+	contactLabelPattern    = regexp.MustCompile(`(?i)^\s*(?:e-?mail|phone|mobile|tel|cell|location|address|linkedin|github|portfolio|website|web)\s*:\s*`)
+)
+
+// documentTitles are title lines some resumes open with instead of the name
+var documentTitles = map[string]bool{
+	"resume": true, "résumé": true, "curriculum vitae": true, "cv": true,
+	"lebenslauf": true, "currículum": true, "currículo": true, "curriculum": true,
+}
+
+// portfolioIgnoredHosts are link hosts that are not a personal site
+var portfolioIgnoredHosts = []string{
+	"linkedin.com", "github.com", "gmail.com", "outlook.com", "hotmail.com",
+	"yahoo.com", "icloud.com", "protonmail.com", "proton.me",
+}
+
+// portfolioTLDs are the top-level domains accepted for bare domains without
+// a scheme; anything else is too easily confused with abbreviations
+var portfolioTLDs = map[string]bool{
+	"com": true, "dev": true, "io": true, "me": true, "net": true, "org": true,
+	"app": true, "site": true, "tech": true, "co": true, "page": true, "xyz": true,
+	"design": true, "blog": true, "info": true, "codes": true,
+}
+
+// usRegions maps US state and Canadian province codes to their names
+var usRegions = map[string]string{
+	"AL": "Alabama", "AK": "Alaska", "AZ": "Arizona", "AR": "Arkansas", "CA": "California",
+	"CO": "Colorado", "CT": "Connecticut", "DE": "Delaware", "FL": "Florida", "GA": "Georgia",
+	"HI": "Hawaii", "ID": "Idaho", "IL": "Illinois", "IN": "Indiana", "IA": "Iowa",
+	"KS": "Kansas", "KY": "Kentucky", "LA": "Louisiana", "ME": "Maine", "MD": "Maryland",
+	"MA": "Massachusetts", "MI": "Michigan", "MN": "Minnesota", "MS": "Mississippi", "MO": "Missouri",
+	"MT": "Montana", "NE": "Nebraska", "NV": "Nevada", "NH": "New Hampshire", "NJ": "New Jersey",
+	"NM": "New Mexico", "NY": "New York", "NC": "North Carolina", "ND": "North Dakota", "OH": "Ohio",
+	"OK": "Oklahoma", "OR": "Oregon", "PA": "Pennsylvania", "RI": "Rhode Island", "SC": "South Carolina",
+	"SD": "South Dakota", "TN": "Tennessee", "TX": "Texas", "UT": "Utah", "VT": "Vermont",
+	"VA": "Virginia", "WA": "Washington", "WV": "West Virginia", "WI": "Wisconsin", "WY": "Wyoming",
+	"DC": "District of Columbia",
+	"AB": "Alberta", "BC": "British Columbia", "MB": "Manitoba", "NB": "New Brunswick",
+	"NL": "Newfoundland and Labrador", "NS": "Nova Scotia", "ON": "Ontario", "PE": "Prince Edward Island",
+	"QC": "Quebec", "SK": "Saskatchewan",
+}
+
+// knownRegions are countries and regions commonly written after a city
+var knownRegions = map[string]bool{
+	"usa": true, "united states": true, "canada": true, "uk": true, "united kingdom": true,
+	"england": true, "scotland": true, "wales": true, "ireland": true, "germany": true,
+	"deutschland": true, "france": true, "spain": true, "españa": true, "italy": true,
+	"italia": true, "portugal": true, "brazil": true, "brasil": true, "netherlands": true,
+	"belgium": true, "switzerland": true, "austria": true, "sweden": true, "norway": true,
+	"denmark": true, "finland": true, "poland": true, "japan": true, "india": true,
+	"australia": true, "new zealand": true, "singapore": true, "mexico": true, "argentina": true,
+	"bavaria": true, "bayern": true, "ontario": true, "quebec": true, "catalonia": true,
+}
+
+// phoneCountries holds the calling code and national number lengths of the
+// countries a phone number without a country code may be read as
+var phoneCountries = map[string]struct {
+	code      string
+	minDigits int // National significant number, without the trunk prefix
+	maxDigits int
+	trunkZero bool
+}{
+	"US": {"1", 10, 10, false},
+	"CA": {"1", 10, 10, false},
+	"GB": {"44", 9, 10, true},
+	"DE": {"49", 6, 11, true},
+	"FR": {"33", 9, 9, true},
+	"ES": {"34", 9, 9, false},
+	"IT": {"39", 6, 11, false},
+	"PT": {"351", 9, 9, false},
+	"BR": {"55", 10, 11, true},
+	"JP": {"81", 9, 10, true},
+	"IN": {"91", 10, 10, true},
+	"AU": {"61", 9, 9, true},
+}
+
+// ContactField is one detected contact value
+type ContactField struct {
+	Value      string  `json:"value"`
+	Confidence float64 `json:"confidence"` // 0-1
+}
+
+// ContactInfo holds the contact details found in a resume
+type ContactInfo struct {
+	Name      *ContactField  `json:"name,omitempty"`
+	Emails    []ContactField `json:"emails"`
+	Phones    []ContactField `json:"phones"` // E.164
+	City      *ContactField  `json:"city,omitempty"`
+	Region    *ContactField  `json:"region,omitempty"`
+	LinkedIn  *ContactField  `json:"linkedin,omitempty"`
+	GitHub    *ContactField  `json:"github,omitempty"`
+	Portfolio *ContactField  `json:"portfolio,omitempty"`
+}
+
+// ContactExtractor pulls contact details out of resume text
+type ContactExtractor struct {
+	defaultCountry string // Country assumed for phone numbers without a country code
+}
+
+// NewContactExtractor creates a new ContactExtractor instance. Phone numbers
+// without a country code are read as CONTACT_DEFAULT_COUNTRY (default US).
+func NewContactExtractor() *ContactExtractor {
+	country := strings.ToUpper(os.Getenv("CONTACT_DEFAULT_COUNTRY"))
+	if _, ok := phoneCountries[country]; !ok {
+		country = "US"
+	}
+	return &ContactExtractor{defaultCountry: country}
+}
+
+// Extract finds the candidate's contact details in resume text
+func (e *ContactExtractor) Extract(text string) ContactInfo {
+	info := ContactInfo{Emails: []ContactField{}, Phones: []ContactField{}}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	header := strings.Join(lines[:min(len(lines), contactHeaderLines)], "\n")
+	body := ""
+	if len(lines) > contactHeaderLines {
+		body = strings.Join(lines[contactHeaderLines:], "\n")
+	}
+
+	info.Emails = e.emails(header, body)
+	info.Name = e.name(lines, info.Emails)
+	info.Phones = e.phones(header, body)
+	info.LinkedIn = e.linkedIn(text, header)
+	info.GitHub = e.gitHub(text, header)
+	info.Portfolio = e.portfolio(header)
+	info.City, info.Region = e.location(lines[:min(len(lines), contactHeaderLines)], info.Name)
+
+	return info
+}
+
+// emails returns distinct addresses, header addresses first
+func (e *ContactExtractor) emails(header, body string) []ContactField {
+	fields := []ContactField{}
+	seen := map[string]bool{}
+	add := func(text string, confidence float64) {
+		for _, match := range emailPIIPattern.FindAllString(text, -1) {
+			email := strings.ToLower(strings.TrimRight(match, "."))
+			if !seen[email] {
+				seen[email] = true
+				fields = append(fields, ContactField{Value: email, Confidence: confidence})
+			}
+		}
+	}
+	add(header, contactConfidenceCertain)
+	add(body, contactConfidenceOutOfHead)
+	return fields
+}
+
+// name reads the name from the first line, trusting it more when it
+// matches an email address
+func (e *ContactExtractor) name(lines []string, emails []ContactField) *ContactField {
+	if len(lines) > 1 && documentTitles[strings.ToLower(lines[0])] {
+		lines = lines[1:]
+	}
+	name := NewPIIRedactor().detectName(strings.Join(lines, "\n"))
+	if name == "" {
+		return nil
+	}
+
+	confidence := contactConfidenceLikely
+	parts := strings.Fields(strings.ToLower(name))
+	for _, email := range emails {
+		local := strings.SplitN(email.Value, "@", 2)[0]
+		if strings.Contains(local, parts[0]) || strings.Contains(local, parts[len(parts)-1]) {
+			confidence = contactConfidenceCertain
+			break
+		}
+	}
+	return &ContactField{Value: name, Confidence: confidence}
+}
+
+// phones returns distinct phone numbers in E.164, header numbers first
+func (e *ContactExtractor) phones(header, body string) []ContactField {
+	fields := []ContactField{}
+	seen := map[string]bool{}
+	add := func(text string, inHeader bool) {
+		for _, match := range contactPhonePattern.FindAllString(text, -1) {
+			if !NewPIIRedactor().looksLikePhone(match) {
+				continue
+			}
+			number, international, ok := e.NormalizePhone(match)
+			if !ok || seen[number] {
+				continue
+			}
+			seen[number] = true
+
+			confidence := contactConfidenceLikely
+			if international {
+				confidence = contactConfidenceCertain
+			}
+			if !inHeader {
+				confidence = contactConfidenceOutOfHead
+			}
+			fields = append(fields, ContactField{Value: number, Confidence: confidence})
+		}
+	}
+	add(header, true)
+	add(body, false)
+	return fields
+}
+
+// NormalizePhone converts a phone number to E.164. Numbers without a
+// country code are read as the default country; international reports
+// whether the number carried its own country code.
+func (e *ContactExtractor) NormalizePhone(raw string) (number string, international bool, ok bool) {
+	raw = strings.TrimSpace(raw)
+	// "(0)" marks a trunk prefix that is dropped when dialing internationally
+	raw = strings.ReplaceAll(raw, "(0)", "")
+
+	var digits strings.Builder
+	for _, r := range raw {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	national := digits.String()
+
+	switch {
+	case strings.HasPrefix(raw, "+"):
+		international = true
+	case strings.HasPrefix(raw, "00"):
+		international = true
+		national = national[2:]
+	}
+	if international {
+		if len(national) < 8 || len(national) > 15 {
+			return "", true, false
+		}
+		return "+" + national, true, true
+	}
+
+	country := phoneCountries[e.defaultCountry]
+	if country.code == "1" && len(national) == 11 && strings.HasPrefix(national, "1") {
+		// US numbers are often written with the country code but no plus
+		national = national[1:]
+	}
+	if country.trunkZero {
+		national = strings.TrimPrefix(national, "0")
+	}
+	if len(national) < country.minDigits || len(national) > country.maxDigits {
+		return "", false, false
+	}
+	return "+" + country.code + national, false, true
+}
+
+// linkedIn returns the LinkedIn profile URL
+func (e *ContactExtractor) linkedIn(text, header string) *ContactField {
+	match := contactLinkedInPattern.FindStringSubmatch(text)
+	if match == nil {
+		return nil
+	}
+	confidence := contactConfidenceCertain
+	if !strings.Contains(header, match[0]) {
+		confidence = contactConfidenceLikely
+	}
+	return &ContactField{Value: "https://www.linkedin.com/in/" + match[1], Confidence: confidence}
+}
+
+// gitHub returns the GitHub profile URL; a link to a repository still
+// names the account, but may belong to someone else's project
+func (e *ContactExtractor) gitHub(text, header string) *ContactField {
+	match := contactGitHubPattern.FindStringSubmatch(text)
+	if match == nil {
+		return nil
+	}
+	confidence := contactConfidenceCertain
+	if match[2] != "" {
+		confidence = contactConfidenceGuess
+	}
+	if !strings.Contains(header, match[0]) && confidence > contactConfidenceLikely {
+		confidence = contactConfidenceLikely
+	}
+	return &ContactField{Value: "https://github.com/" + match[1], Confidence: confidence}
+}
+
+// portfolio returns a personal site linked from the header
+func (e *ContactExtractor) portfolio(header string) *ContactField {
+	// Emails would otherwise match as bare domains
+	header = emailPIIPattern.ReplaceAllString(header, " ")
+
+	for _, match := range contactURLPattern.FindAllStringSubmatch(header, -1) {
+		host := strings.ToLower(match[1])
+		if ignoredPortfolioHost(host) {
+			continue
+		}
+
+		hasScheme := strings.HasPrefix(strings.ToLower(match[0]), "http")
+		tld := host[strings.LastIndex(host, ".")+1:]
+		if !hasScheme && !portfolioTLDs[tld] {
+			continue
+		}
+
+		link := match[0]
+		if !hasScheme {
+			link = "https://" + link
+		}
+		parsed, err := url.Parse(strings.TrimRight(link, "."))
+		if err != nil || parsed.Host == "" {
+			continue
+		}
+
+		confidence := contactConfidenceLikely
+		if !hasScheme {
+			confidence = contactConfidenceGuess
+		}
+		return &ContactField{Value: parsed.String(), Confidence: confidence}
+	}
+	return nil
+}
+
+// ignoredPortfolioHost reports whether host is a profile or mail domain
+func ignoredPortfolioHost(host string) bool {
+	for _, ignored := range portfolioIgnoredHosts {
+		if host == ignored || strings.HasSuffix(host, "."+ignored) {
+			return true
+		}
+	}
+	return false
+}
+
+// location finds a "City, Region" pair in the header lines
+func (e *ContactExtractor) location(lines []string, name *ContactField) (*ContactField, *ContactField) {
+	for _, line := range lines {
+		line = contactLabelPattern.ReplaceAllString(line, "")
+		for _, part := range strings.FieldsFunc(line, func(r rune) bool { return r == '|' || r == '•' || r == '·' }) {
+			match := contactLocationPattern.FindStringSubmatch(strings.TrimSpace(part))
+			if match == nil {
+				continue
+			}
+			city, region := match[1], match[2]
+			if name != nil && strings.Contains(name.Value, city) {
+				continue
+			}
+
+			// Only known regions are accepted; "Smith, Senior Engineer" has the same shape
+			if _, ok := usRegions[region]; ok || knownRegions[strings.ToLower(region)] {
+				return &ContactField{Value: city, Confidence: contactConfidenceLikely},
+					&ContactField{Value: region, Confidence: contactConfidenceLikely}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// NormalizeEmail lowercases an email address and reports whether it is valid
+func NormalizeEmail(raw string) (string, bool) {
+	email := strings.ToLower(strings.TrimSpace(raw))
+	if match := emailPIIPattern.FindString(email); match == "" || match != email {
+		return "", false
+	}
+	return email, true
+}
+
+// NormalizeProfileURL adds a missing https scheme to a link and reports
+// whether it is an http(s) URL; host, when set, must be the link's domain
+func NormalizeProfileURL(raw, host string) (string, bool) {
+	link := strings.TrimSpace(raw)
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || !strings.Contains(parsed.Host, ".") {
+		return "", false
+	}
+	if host != "" {
+		h := strings.ToLower(parsed.Hostname())
+		if h != host && !strings.HasSuffix(h, "."+host) {
+			return "", false
+		}
+	}
+	return parsed.String(), true
+}
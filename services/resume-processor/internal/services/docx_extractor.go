@@ -0,0 +1,510 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WordprocessingML namespaces of the transitional and strict OOXML variants.
+// Text boxes and shapes embed DrawingML, whose a:p and a:t elements share
+// local names with w:p and w:t but are not body text.
+var wordMLNamespaces = map[string]bool{
+	"http://schemas.openxmlformats.org/wordprocessingml/2006/main": true,
+	"http://purl.oclc.org/ooxml/wordprocessingml/main":             true,
+}
+
+// maxXMLPartSize caps how much of a single XML part is read, so a small
+// upload cannot expand into an unbounded amount of XML
+const maxXMLPartSize = 32 << 20
+
+// docxNumbering maps list definitions from word/numbering.xml to the number
+// format of each indentation level ("bullet", "decimal", "lowerLetter", ...)
+type docxNumbering map[string]map[int]string
+
+// docxStyle is the part of a paragraph style that affects extracted text
+type docxStyle struct {
+	Name    string
+	Heading bool
+	NumID   string // List the style applies, e.g. for "List Bullet"
+	Level   int
+}
+
+// docxParagraph collects the text and properties of a w:p element
+type docxParagraph struct {
+	text    strings.Builder
+	styleID string
+	numID   string
+	level   int
+	hasNum  bool
+}
+
+// textTable collects the rows of a table while its cells are being read
+type textTable struct {
+	row  []string
+	cell []string
+}
+
+// textLines collects the lines of an extracted document; lines written while
+// a table is open go to its current cell, and each row becomes one line
+type textLines struct {
+	lines  []string
+	tables []*textTable
+}
+
+// table returns the innermost open table
+func (out *textLines) table() *textTable {
+	if len(out.tables) == 0 {
+		return nil
+	}
+	return out.tables[len(out.tables)-1]
+}
+
+// openTable starts a table nested in the current cell, if any
+func (out *textLines) openTable() {
+	out.tables = append(out.tables, &textTable{})
+}
+
+// closeTable ends the innermost table and the block it forms
+func (out *textLines) closeTable() {
+	if len(out.tables) > 0 {
+		out.tables = out.tables[:len(out.tables)-1]
+	}
+	out.emit("")
+}
+
+// endCell adds the current cell to the row of the innermost table
+func (out *textLines) endCell() {
+	if table := out.table(); table != nil {
+		table.row = append(table.row, strings.Join(table.cell, " "))
+		table.cell = nil
+	}
+}
+
+// endRow writes the row of the innermost table as one line
+func (out *textLines) endRow() {
+	table := out.table()
+	if table == nil {
+		return
+	}
+	row := joinTableRow(table.row)
+	table.row = nil
+	// A row belongs to the enclosing cell of a nested table
+	out.tables = out.tables[:len(out.tables)-1]
+	out.emit(row)
+	out.tables = append(out.tables, table)
+}
+
+// emit writes a line to the innermost open table cell, or to the document
+// when outside a table
+func (out *textLines) emit(line string) {
+	if table := out.table(); table != nil {
+		if line = strings.TrimSpace(line); line != "" {
+			table.cell = append(table.cell, line)
+		}
+		return
+	}
+	out.lines = append(out.lines, line)
+}
+
+// docxDocument walks word/document.xml and renders it as plain text
+type docxDocument struct {
+	styles    map[string]docxStyle
+	numbering docxNumbering
+	counters  map[string][]int // Running item number per list and level
+
+	textLines
+	paragraphs []*docxParagraph
+}
+
+// extractFromDOCX extracts text from a Word document, keeping paragraph
+// breaks, list bullets, table rows and headings so section structure survives
+func (te *TextExtractor) extractFromDOCX(ctx context.Context, filePath string) (string, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open DOCX: %v", err)
+	}
+	defer archive.Close()
+
+	parts := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		parts[f.Name] = f
+	}
+
+	documentPart, ok := parts["word/document.xml"]
+	if !ok {
+		return "", fmt.Errorf("failed to open DOCX: word/document.xml is missing")
+	}
+
+	doc := &docxDocument{
+		styles:    map[string]docxStyle{},
+		numbering: docxNumbering{},
+		counters:  map[string][]int{},
+	}
+
+	// Styles and numbering only refine the output, so a damaged part is
+	// ignored rather than failing the whole upload
+	if part, ok := parts["word/styles.xml"]; ok {
+		if styles, err := parseDOCXStyles(part); err == nil {
+			doc.styles = styles
+		}
+	}
+	if part, ok := parts["word/numbering.xml"]; ok {
+		if numbering, err := parseDOCXNumbering(part); err == nil {
+			doc.numbering = numbering
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	rc, err := documentPart.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read DOCX: %v", err)
+	}
+	defer rc.Close()
+
+	if err := doc.parse(ctx, io.LimitReader(rc, maxXMLPartSize)); err != nil {
+		return "", fmt.Errorf("failed to parse DOCX: %v", err)
+	}
+
+	return te.finalCleanup(strings.Join(doc.lines, "\n")), nil
+}
+
+// parse streams the document body, emitting one line per paragraph and table row
+func (doc *docxDocument) parse(ctx context.Context, r io.Reader) error {
+	decoder := xml.NewDecoder(r)
+
+	for count := 0; ; count++ {
+		if count%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "Fallback" {
+				// mc:Fallback repeats the content of mc:Choice for older
+				// readers; reading both would duplicate text box content
+				if err := decoder.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if !wordMLNamespaces[t.Name.Space] {
+				continue
+			}
+			switch t.Name.Local {
+			case "p":
+				doc.paragraphs = append(doc.paragraphs, &docxParagraph{})
+			case "pStyle":
+				if p := doc.paragraph(); p != nil {
+					p.styleID = xmlAttr(t, "val")
+				}
+			case "numId":
+				if p := doc.paragraph(); p != nil {
+					p.numID = xmlAttr(t, "val")
+					p.hasNum = true
+				}
+			case "ilvl":
+				if p := doc.paragraph(); p != nil {
+					p.level, _ = strconv.Atoi(xmlAttr(t, "val"))
+				}
+			case "t":
+				var text string
+				if err := decoder.DecodeElement(&text, &t); err != nil {
+					return err
+				}
+				if p := doc.paragraph(); p != nil {
+					p.text.WriteString(text)
+				}
+			case "tab":
+				// w:tab inside w:tabs is a tab stop definition, not content
+				if p := doc.paragraph(); p != nil && p.text.Len() > 0 {
+					p.text.WriteString("\t")
+				}
+			case "br", "cr":
+				if p := doc.paragraph(); p != nil {
+					p.text.WriteString("\n")
+				}
+			case "noBreakHyphen":
+				if p := doc.paragraph(); p != nil {
+					p.text.WriteString("-")
+				}
+			case "tbl":
+				doc.openTable()
+			case "tc":
+				if table := doc.table(); table != nil {
+					table.cell = nil
+				}
+			}
+
+		case xml.EndElement:
+			if !wordMLNamespaces[t.Name.Space] {
+				continue
+			}
+			switch t.Name.Local {
+			case "p":
+				doc.endParagraph()
+			case "tc":
+				doc.endCell()
+			case "tr":
+				doc.endRow()
+			case "tbl":
+				doc.closeTable()
+			}
+		}
+	}
+}
+
+// paragraph returns the innermost open paragraph
+func (doc *docxDocument) paragraph() *docxParagraph {
+	if len(doc.paragraphs) == 0 {
+		return nil
+	}
+	return doc.paragraphs[len(doc.paragraphs)-1]
+}
+
+// endParagraph renders the closed paragraph with its list marker or heading spacing
+func (doc *docxDocument) endParagraph() {
+	p := doc.paragraph()
+	if p == nil {
+		return
+	}
+	doc.paragraphs = doc.paragraphs[:len(doc.paragraphs)-1]
+
+	text := strings.TrimSpace(p.text.String())
+	style := doc.styles[p.styleID]
+
+	numID, level, isList := p.numID, p.level, p.hasNum
+	if !isList && style.NumID != "" {
+		numID, level, isList = style.NumID, style.Level, true
+	}
+	// numId 0 explicitly removes list formatting inherited from the style
+	if numID == "0" {
+		isList = false
+	}
+
+	if text == "" {
+		// Empty paragraphs are Word's way of adding vertical space
+		if doc.table() == nil {
+			doc.emit("")
+		}
+		return
+	}
+
+	switch {
+	case style.Heading && doc.table() == nil:
+		doc.emit("")
+		doc.emit(text)
+		doc.emit("")
+	case isList:
+		doc.emit(doc.listMarker(numID, level, style.Name) + " " + text)
+	default:
+		doc.emit(text)
+	}
+}
+
+// listMarker returns the bullet or item number for a list paragraph
+func (doc *docxDocument) listMarker(numID string, level int, styleName string) string {
+	if level < 0 || level > 8 {
+		level = 0
+	}
+
+	format := "bullet"
+	if levels, ok := doc.numbering[numID]; ok {
+		if f, ok := levels[level]; ok {
+			format = f
+		}
+	} else if strings.Contains(strings.ToLower(styleName), "number") {
+		format = "decimal"
+	}
+
+	counters := doc.counters[numID]
+	if len(counters) < 9 {
+		counters = make([]int, 9)
+	}
+	counters[level]++
+	for deeper := level + 1; deeper < len(counters); deeper++ {
+		counters[deeper] = 0
+	}
+	doc.counters[numID] = counters
+	n := counters[level]
+
+	switch format {
+	case "bullet", "none", "":
+		return "•"
+	case "lowerLetter":
+		return docxLetter(n, 'a') + "."
+	case "upperLetter":
+		return docxLetter(n, 'A') + "."
+	default:
+		return strconv.Itoa(n) + "."
+	}
+}
+
+// parseDOCXStyles reads paragraph styles from word/styles.xml
+func parseDOCXStyles(part *zip.File) (map[string]docxStyle, error) {
+	rc, err := part.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var parsed struct {
+		Styles []struct {
+			Type    string `xml:"type,attr"`
+			StyleID string `xml:"styleId,attr"`
+			Name    struct {
+				Val string `xml:"val,attr"`
+			} `xml:"name"`
+			BasedOn struct {
+				Val string `xml:"val,attr"`
+			} `xml:"basedOn"`
+			PPr struct {
+				OutlineLvl *struct {
+					Val string `xml:"val,attr"`
+				} `xml:"outlineLvl"`
+				NumPr struct {
+					NumID struct {
+						Val string `xml:"val,attr"`
+					} `xml:"numId"`
+					Ilvl struct {
+						Val string `xml:"val,attr"`
+					} `xml:"ilvl"`
+				} `xml:"numPr"`
+			} `xml:"pPr"`
+		} `xml:"style"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(rc, maxXMLPartSize)).Decode(&parsed); err != nil {
+		return nil, err
+	}
+
+	styles := make(map[string]docxStyle, len(parsed.Styles))
+	basedOn := make(map[string]string, len(parsed.Styles))
+	for _, s := range parsed.Styles {
+		if s.Type != "" && s.Type != "paragraph" {
+			continue
+		}
+		name := strings.ToLower(s.Name.Val)
+		level, _ := strconv.Atoi(s.PPr.NumPr.Ilvl.Val)
+		// Outline levels 0-8 are heading levels; 9 is body text
+		outline := false
+		if s.PPr.OutlineLvl != nil {
+			n, err := strconv.Atoi(s.PPr.OutlineLvl.Val)
+			outline = err == nil && n >= 0 && n <= 8
+		}
+		styles[s.StyleID] = docxStyle{
+			Name: s.Name.Val,
+			// Localized Word versions keep the English built-in style
+			// names, so "heading 1" and "title" identify headings
+			Heading: strings.HasPrefix(name, "heading") || name == "title" || name == "subtitle" || outline,
+			NumID:   s.PPr.NumPr.NumID.Val,
+			Level:   level,
+		}
+		basedOn[s.StyleID] = s.BasedOn.Val
+	}
+
+	// Custom styles such as "Section Title" are often based on a heading
+	for id, style := range styles {
+		parent := basedOn[id]
+		for depth := 0; parent != "" && depth < 10 && !style.Heading; depth++ {
+			style.Heading = styles[parent].Heading
+			parent = basedOn[parent]
+		}
+		styles[id] = style
+	}
+
+	return styles, nil
+}
+
+// parseDOCXNumbering reads list definitions from word/numbering.xml
+func parseDOCXNumbering(part *zip.File) (docxNumbering, error) {
+	rc, err := part.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var parsed struct {
+		AbstractNums []struct {
+			ID     string `xml:"abstractNumId,attr"`
+			Levels []struct {
+				Ilvl   int `xml:"ilvl,attr"`
+				NumFmt struct {
+					Val string `xml:"val,attr"`
+				} `xml:"numFmt"`
+			} `xml:"lvl"`
+		} `xml:"abstractNum"`
+		Nums []struct {
+			NumID         string `xml:"numId,attr"`
+			AbstractNumID struct {
+				Val string `xml:"val,attr"`
+			} `xml:"abstractNumId"`
+		} `xml:"num"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(rc, maxXMLPartSize)).Decode(&parsed); err != nil {
+		return nil, err
+	}
+
+	abstract := make(map[string]map[int]string, len(parsed.AbstractNums))
+	for _, a := range parsed.AbstractNums {
+		levels := make(map[int]string, len(a.Levels))
+		for _, lvl := range a.Levels {
+			levels[lvl.Ilvl] = lvl.NumFmt.Val
+		}
+		abstract[a.ID] = levels
+	}
+
+	numbering := make(docxNumbering, len(parsed.Nums))
+	for _, n := range parsed.Nums {
+		if levels, ok := abstract[n.AbstractNumID.Val]; ok {
+			numbering[n.NumID] = levels
+		}
+	}
+	return numbering, nil
+}
+
+// joinTableRow renders a table row, dropping empty cells used for layout
+func joinTableRow(cells []string) string {
+	var filled []string
+	for _, cell := range cells {
+		if cell = strings.TrimSpace(cell); cell != "" {
+			filled = append(filled, cell)
+		}
+	}
+	return strings.Join(filled, " | ")
+}
+
+// xmlAttr returns the value of an attribute by local name
+func xmlAttr(el xml.StartElement, local string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// docxLetter renders a list item number as a letter sequence: a..z, aa..zz
+func docxLetter(n int, base rune) string {
+	if n < 1 {
+		n = 1
+	}
+	letter := string(base + rune((n-1)%26))
+	return strings.Repeat(letter, (n-1)/26+1)
+}
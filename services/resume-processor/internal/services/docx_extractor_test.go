@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractFromDOCXFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    string
+	}{
+		{
+			// Runs are joined, tabs and line breaks kept, empty paragraphs
+			// become blank lines
+			fixture: "paragraphs.docx",
+			want: "Jordan Lee\n" +
+				"jordan.lee@example.com +1 555 010 4477\n" +
+				"\n" +
+				"Platform engineer with ten years of experience.\n" +
+				"Line one\n" +
+				"Line two\n" +
+				"Site-reliability",
+		},
+		{
+			// Bullets and numbers come from numbering.xml or the paragraph
+			// style; numId 0 removes the list a style applies, and deeper
+			// levels restart when the level above advances
+			fixture: "lists.docx",
+			want: "Highlights\n" +
+				"• Cut deploy time by 40%\n" +
+				"• Owned the on-call rotation\n" +
+				"• Wrote the runbooks\n" +
+				"• Bullet from the style\n" +
+				"Not a list item\n" +
+				"Steps\n" +
+				"1. Design\n" +
+				"2. Build\n" +
+				"a. Unit tests\n" +
+				"b. Load tests\n" +
+				"3. Ship\n" +
+				"a. Canary",
+		},
+		{
+			// One line per row with empty layout cells dropped; a nested
+			// table is rendered inside its cell
+			fixture: "tables.docx",
+			want: "Experience\n" +
+				"Acme Corp | 2019 – 2024\n" +
+				"Staff Engineer Led the storage team | Berlin\n" +
+				"Go | Rust\n" +
+				"\n" +
+				"After the table",
+		},
+		{
+			// Headings by built-in name, by basedOn and by outline level
+			// 0-8 are set apart; outline level 9 is body text
+			fixture: "headings.docx",
+			want: "Jordan Lee\n" +
+				"\n" +
+				"Experience\n" +
+				"\n" +
+				"Acme Corp, Staff Engineer\n" +
+				"\n" +
+				"Skills\n" +
+				"\n" +
+				"Languages: Go, Python\n" +
+				"Platforms: Kubernetes, PostgreSQL\n" +
+				"Tools: Terraform\n" +
+				"\n" +
+				"Education\n" +
+				"\n" +
+				"B.Sc. Computer Science",
+		},
+		{
+			// Text box paragraphs are read once, from mc:Choice; DrawingML
+			// text in shapes and diagrams is not body text
+			fixture: "shapes.docx",
+			want: "Summary\n" +
+				"Open to relocation\n" +
+				"\n" +
+				"Skills chart\n" +
+				"Reliable and curious",
+		},
+	}
+
+	te := NewTextExtractor()
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := te.extractFromDOCX(context.Background(), filepath.Join("testdata", "docx", tt.fixture))
+			if err != nil {
+				t.Fatalf("extractFromDOCX: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("extracted text\n got: %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Namespaces of the parts written to a DOCX package
+const (
+	docxMainNS       = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	docxRelNS        = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	docxPackageRelNS = "http://schemas.openxmlformats.org/package/2006/relationships"
+)
+
+// Page layout of written documents in twentieths of a point: US Letter with
+// 0.75 inch margins
+const (
+	docxPageWidth  = 12240
+	docxPageHeight = 15840
+	docxMargin     = 1080
+)
+
+// Paragraph and character styles of written documents. Built-in style IDs
+// are used where Word has one, so the document outline, list numbering and
+// restyling work as in a hand-made document.
+const (
+	docxStyleTitle      = "Title"
+	docxStyleSubtitle   = "Subtitle"
+	docxStyleContact    = "ContactInfo"
+	docxStyleHeading    = "Heading1"
+	docxStyleEntry      = "EntryTitle"
+	docxStyleEntryDate  = "EntryDate"
+	docxStyleListBullet = "ListBullet"
+)
+
+// docxBulletNumID is the numbering instance used by the List Bullet style
+const docxBulletNumID = 1
+
+// docxStyles is word/styles.xml
+var docxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="` + docxMainNS + `">
+<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:eastAsia="Calibri" w:cs="Calibri"/><w:sz w:val="21"/><w:szCs w:val="21"/></w:rPr></w:rPrDefault><w:pPrDefault><w:pPr><w:spacing w:after="40" w:line="259" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>
+<w:style w:type="character" w:default="1" w:styleId="DefaultParagraphFont"><w:name w:val="Default Paragraph Font"/><w:uiPriority w:val="1"/><w:semiHidden/></w:style>
+<w:style w:type="paragraph" w:styleId="` + docxStyleTitle + `"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:jc w:val="center"/><w:spacing w:after="40"/></w:pPr><w:rPr><w:b/><w:sz w:val="40"/><w:szCs w:val="40"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="` + docxStyleSubtitle + `"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:jc w:val="center"/><w:spacing w:after="40"/></w:pPr><w:rPr><w:color w:val="595959"/><w:sz w:val="24"/><w:szCs w:val="24"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:customStyle="1" w:styleId="` + docxStyleContact + `"><w:name w:val="Contact Info"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:jc w:val="center"/><w:spacing w:after="120"/></w:pPr><w:rPr><w:color w:val="595959"/><w:sz w:val="19"/><w:szCs w:val="19"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="` + docxStyleHeading + `"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:uiPriority w:val="9"/><w:qFormat/><w:pPr><w:keepNext/><w:keepLines/><w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="1F4E79"/></w:pBdr><w:spacing w:before="240" w:after="80"/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:caps/><w:color w:val="1F4E79"/><w:sz w:val="24"/><w:szCs w:val="24"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:customStyle="1" w:styleId="` + docxStyleEntry + `"><w:name w:val="Entry Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/><w:pPr><w:keepNext/><w:tabs><w:tab w:val="right" w:pos="` + fmt.Sprint(docxPageWidth-2*docxMargin) + `"/></w:tabs><w:spacing w:before="120" w:after="20"/></w:pPr><w:rPr><w:b/></w:rPr></w:style>
+<w:style w:type="character" w:customStyle="1" w:styleId="` + docxStyleEntryDate + `"><w:name w:val="Entry Date"/><w:basedOn w:val="DefaultParagraphFont"/><w:qFormat/><w:rPr><w:b w:val="0"/><w:i/><w:color w:val="595959"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="` + docxStyleListBullet + `"><w:name w:val="List Bullet"/><w:basedOn w:val="Normal"/><w:uiPriority w:val="99"/><w:qFormat/><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="` + fmt.Sprint(docxBulletNumID) + `"/></w:numPr><w:ind w:left="360" w:hanging="360"/><w:contextualSpacing/></w:pPr></w:style>
+</w:styles>`
+
+// docxNumberingPart is word/numbering.xml, defining the bullet list
+var docxNumberingPart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:w="` + docxMainNS + `">
+<w:abstractNum w:abstractNumId="0"><w:multiLevelType w:val="singleLevel"/><w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="bullet"/><w:lvlText w:val="•"/><w:lvlJc w:val="left"/><w:pPr><w:ind w:left="360" w:hanging="360"/></w:pPr></w:lvl></w:abstractNum>
+<w:num w:numId="` + fmt.Sprint(docxBulletNumID) + `"><w:abstractNumId w:val="0"/></w:num>
+</w:numbering>`
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>
+<Override PartName="/word/numbering.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"/>
+<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>
+</Types>`
+
+const docxPackageRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="` + docxPackageRelNS + `">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>
+</Relationships>`
+
+const docxDocumentRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="` + docxPackageRelNS + `">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering" Target="numbering.xml"/>
+</Relationships>`
+
+// RenderDOCX writes a resume document as a Word file. The name, headline
+// and contact details use the Title, Subtitle and Contact Info styles,
+// sections are Heading 1, bullets are a List Bullet list and entry dates
+// sit at a right-aligned tab stop.
+func RenderDOCX(doc *ResumeDocument) ([]byte, error) {
+	var body strings.Builder
+	for _, block := range doc.Blocks {
+		writeDOCXBlock(&body, block)
+	}
+
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="` + docxMainNS + `" xmlns:r="` + docxRelNS + `"><w:body>` + body.String() +
+		fmt.Sprintf(`<w:sectPr><w:pgSz w:w="%d" w:h="%d"/><w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="720" w:footer="720" w:gutter="0"/></w:sectPr>`,
+			docxPageWidth, docxPageHeight, docxMargin, docxMargin, docxMargin, docxMargin) +
+		`</w:body></w:document>`
+
+	title := ""
+	if doc.Name != "" {
+		title = doc.Name + " - Resume"
+	}
+	core := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+		`<dc:title>` + docxEscape(title) + `</dc:title><dc:creator>` + docxEscape(doc.Name) + `</dc:creator></cp:coreProperties>`
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	// [Content_Types].xml comes first, as Word writes it
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxPackageRels},
+		{"docProps/core.xml", core},
+		{"word/document.xml", document},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/styles.xml", docxStyles},
+		{"word/numbering.xml", docxNumberingPart},
+	}
+	for _, part := range parts {
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write DOCX: %v", err)
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to write DOCX: %v", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write DOCX: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeDOCXBlock writes one block as a paragraph
+func writeDOCXBlock(body *strings.Builder, block ResumeBlock) {
+	paragraph := func(style string, runs ...string) {
+		body.WriteString("<w:p>")
+		if style != "" {
+			fmt.Fprintf(body, `<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, style)
+		}
+		for _, run := range runs {
+			body.WriteString(run)
+		}
+		body.WriteString("</w:p>")
+	}
+
+	switch block.Kind {
+	case ResumeBlockName:
+		paragraph(docxStyleTitle, docxRun(block.Text, ""))
+	case ResumeBlockLabel:
+		paragraph(docxStyleSubtitle, docxRun(block.Text, ""))
+	case ResumeBlockContact:
+		paragraph(docxStyleContact, docxRun(block.Text, ""))
+	case ResumeBlockHeading:
+		paragraph(docxStyleHeading, docxRun(block.Text, ""))
+	case ResumeBlockEntry:
+		runs := []string{docxRun(block.Text, "")}
+		if block.Detail != "" {
+			if block.Text != "" {
+				runs = append(runs, "<w:r><w:tab/></w:r>")
+			}
+			runs = append(runs, docxRun(block.Detail, `<w:rStyle w:val="`+docxStyleEntryDate+`"/>`))
+		}
+		paragraph(docxStyleEntry, runs...)
+	case ResumeBlockBullet:
+		paragraph(docxStyleListBullet, docxRun(block.Text, ""))
+	default:
+		if block.Label != "" {
+			paragraph("", docxRun(block.Label+": ", "<w:b/>"), docxRun(block.Text, ""))
+			return
+		}
+		paragraph("", docxRun(block.Text, ""))
+	}
+}
+
+// docxRun returns a run of text with the given run properties
+func docxRun(text, properties string) string {
+	if text == "" {
+		return ""
+	}
+	run := "<w:r>"
+	if properties != "" {
+		run += "<w:rPr>" + properties + "</w:rPr>"
+	}
+	return run + `<w:t xml:space="preserve">` + docxEscape(text) + "</w:t></w:r>"
+}
+
+// docxEscape escapes text for XML, dropping characters XML cannot hold
+func docxEscape(text string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' {
+			return ' '
+		}
+		if r < 0x20 || r == 0xFFFE || r == 0xFFFF {
+			return -1
+		}
+		return r
+	}, text)))
+	return sb.String()
+}
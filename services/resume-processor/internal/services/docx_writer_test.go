@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRenderDOCXRoundTrip(t *testing.T) {
+	doc := testResumeDocument()
+	doc.Blocks = append(doc.Blocks,
+		ResumeBlock{Kind: ResumeBlockHeading, Text: "PROJECTS"},
+		ResumeBlock{Kind: ResumeBlockBullet, Text: `Ran R&D on <fast> "zero-copy" parsing`})
+
+	data, err := RenderDOCX(doc)
+	if err != nil {
+		t.Fatalf("RenderDOCX: %v", err)
+	}
+	text, err := NewTextExtractor().extractFromDOCX(context.Background(), writeTestFile(t, "resume.docx", data))
+	if err != nil {
+		t.Fatalf("extractFromDOCX: %v", err)
+	}
+	lines := strings.Split(text, "\n")
+
+	// Title, Subtitle and Contact Info paragraphs open the document
+	header := []string{
+		"Alex Morgan", "",
+		"Senior Software Engineer", "",
+		"alex.morgan@example.com | +1 555 010 2030 | Berlin, Germany",
+	}
+	if len(lines) < len(header) || strings.Join(lines[:len(header)], "\n") != strings.Join(header, "\n") {
+		t.Errorf("header = %q, want %q", lines[:min(len(header), len(lines))], header)
+	}
+
+	// Heading 1 paragraphs are set apart by blank lines, in document order
+	var headings []string
+	for i := 1; i+1 < len(lines); i++ {
+		if lines[i-1] == "" && lines[i+1] == "" && strings.ToUpper(lines[i]) == lines[i] {
+			headings = append(headings, lines[i])
+		}
+	}
+	if want := []string{"SUMMARY", "EXPERIENCE", "EDUCATION", "SKILLS", "PROJECTS"}; strings.Join(headings, ",") != strings.Join(want, ",") {
+		t.Errorf("headings = %q, want %q", headings, want)
+	}
+
+	// List Bullet paragraphs come back as bullets, with text unescaped
+	var bullets []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "• ") {
+			bullets = append(bullets, strings.TrimPrefix(line, "• "))
+		}
+	}
+	var want []string
+	for _, block := range doc.Blocks {
+		if block.Kind == ResumeBlockBullet {
+			want = append(want, block.Text)
+		}
+	}
+	if strings.Join(bullets, "\n") != strings.Join(want, "\n") {
+		t.Errorf("bullets = %q, want %q", bullets, want)
+	}
+
+	if !containsString(lines, "Languages: Go, Python, SQL") {
+		t.Errorf("labelled paragraph missing:\n%s", text)
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"fmt"
+	"math"
+)
+
+// Extraction methods, from most to least faithful to the source
+const (
+	ExtractionMethodNative     = "native"     // Text read from the document structure
+	ExtractionMethodAggressive = "aggressive" // Text scraped from raw PDF streams
+	ExtractionMethodOCR        = "ocr"        // Text recognized from page images
+)
+
+// Report thresholds
+const (
+	reportHighFilteredRatio = 0.5  // Above this, much of the PDF text was discarded as noise
+	reportLowOCRConfidence  = 60.0 // Pages recognized below this are flagged
+	reportMinLanguageRunes  = 200  // Shorter texts are too short to name a language reliably
+)
+
+// ExtractionReport describes how the text of a document was obtained, so
+// poor extractions can be spotted before the text is optimized
+type ExtractionReport struct {
+	Method        string   `json:"method"`
+	Pages         int      `json:"pages,omitempty"`          // Pages processed; 0 for formats without pages
+	FilteredRatio float64  `json:"filtered_ratio"`           // Share of PDF text segments rejected as noise
+	OCRConfidence *float64 `json:"ocr_confidence,omitempty"` // Mean page confidence, 0-100, when OCR was used
+	Language      string   `json:"language,omitempty"`       // ISO 639-1 code of the detected language
+	Warnings      []string `json:"warnings"`
+}
+
+// warn adds a warning to the report
+func (r *ExtractionReport) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// addOCRPages records the confidence of OCR'd pages, warning about pages
+// that failed or were recognized poorly
+func (r *ExtractionReport) addOCRPages(pages []OCRPage) {
+	var sum float64
+	recognized := 0
+	for _, page := range pages {
+		switch {
+		case page.Error != "":
+			r.warn("OCR failed on page %d: %s", page.Page, page.Error)
+		case page.Confidence < reportLowOCRConfidence:
+			r.warn("low OCR confidence on page %d (%.0f%%)", page.Page, page.Confidence)
+		}
+		if page.Error == "" {
+			sum += page.Confidence
+			recognized++
+		}
+	}
+	if recognized > 0 {
+		confidence := math.Round(sum/float64(recognized)*10) / 10
+		r.OCRConfidence = &confidence
+	}
+}
+
+// finish fills in the parts of the report that depend on the final text
+func (r *ExtractionReport) finish(text string) {
+	if r.Method == "" {
+		r.Method = ExtractionMethodNative
+	}
+	if r.Warnings == nil {
+		r.Warnings = []string{}
+	}
+	r.FilteredRatio = math.Round(r.FilteredRatio*1000) / 1000
+	if r.FilteredRatio > reportHighFilteredRatio {
+		r.warn("%.0f%% of the PDF text was discarded as noise; some content may be missing", r.FilteredRatio*100)
+	}
+
+	if len([]rune(text)) < reportMinLanguageRunes {
+		r.warn("too little text to detect the language")
+		return
+	}
+	r.Language = DetectLanguage(text)
+	if r.Language == "" {
+		r.warn("could not detect the language")
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ProviderError is returned when an AI provider call fails, either in
+// transport (Err is set) or with a non-200 response (StatusCode is set)
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+// Error implements the error interface
+func (e *ProviderError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s API request failed: %v", ProviderName(e.Provider), e.Err)
+	}
+	return fmt.Sprintf("%s API error: %d - %s", ProviderName(e.Provider), e.StatusCode, e.Body)
+}
+
+// Unwrap returns the underlying transport error
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether another model might succeed where this one failed:
+// transport failures, rate limits and provider-side outages. Authentication
+// and request errors would fail the same way on every attempt.
+func (e *ProviderError) Retryable() bool {
+	if e.Err != nil {
+		return !errors.Is(e.Err, context.Canceled)
+	}
+
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout,
+		529: // Anthropic "overloaded"
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRetryableProviderError reports whether err is a ProviderError worth retrying with another model
+func IsRetryableProviderError(err error) bool {
+	var providerErr *ProviderError
+	return errors.As(err, &providerErr) && providerErr.Retryable()
+}
+
+// ModelCandidate is a model in a fallback chain together with the key to call it with
+type ModelCandidate struct {
+	Model  string
+	APIKey string
+}
+
+// ModelAttempt records a model that failed before the chain moved on
+type ModelAttempt struct {
+	Model string `json:"model"`
+	Error string `json:"error"`
+}
+
+// optimizeWithFallbacks tries the requested model, then each fallback in
+// order, moving on only when the previous attempt failed with a retryable
+// provider error
+func (ai *AIOptimizer) optimizeWithFallbacks(ctx context.Context, req OptimizationRequest) (*OptimizationResponse, error) {
+	candidates := append([]ModelCandidate{{Model: req.AIModel, APIKey: req.UserAPIKey}}, req.Fallbacks...)
+
+	var attempts []ModelAttempt
+	for i, candidate := range candidates {
+		attemptReq := req
+		attemptReq.AIModel = candidate.Model
+		attemptReq.UserAPIKey = candidate.APIKey
+
+		result, err := ai.optimizeWithProvider(ctx, attemptReq)
+		if err == nil {
+			result.FallbackAttempts = attempts
+			return result, nil
+		}
+
+		if ctx.Err() != nil || !IsRetryableProviderError(err) || i == len(candidates)-1 {
+			return nil, err
+		}
+		attempts = append(attempts, ModelAttempt{Model: candidate.Model, Error: err.Error()})
+	}
+
+	return nil, fmt.Errorf("no AI model available")
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MIME types of the resume formats
+const (
+	MIMEPDF       = "application/pdf"
+	MIMEDOCX      = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MIMEODT       = "application/vnd.oasis.opendocument.text"
+	MIMERTF       = "application/rtf"
+	MIMEHTML      = "text/html"
+	MIMEMarkdown  = "text/markdown"
+	MIMEJSON      = "application/json"
+	MIMEPlainText = "text/plain"
+	MIMEMSWord    = "application/msword"
+	MIMEZip       = "application/zip"
+	MIMEUnknown   = "application/octet-stream"
+)
+
+// sniffLength is how much of a file is read to detect its type
+const sniffLength = 8 << 10
+
+// ErrUnsupportedFileType is returned for files no extractor can read
+var ErrUnsupportedFileType = errors.New("unsupported file type")
+
+// fileExtensions maps file extensions to the MIME type they declare
+var fileExtensions = map[string]string{
+	".pdf":      MIMEPDF,
+	".docx":     MIMEDOCX,
+	".odt":      MIMEODT,
+	".rtf":      MIMERTF,
+	".html":     MIMEHTML,
+	".htm":      MIMEHTML,
+	".md":       MIMEMarkdown,
+	".markdown": MIMEMarkdown,
+	".txt":      MIMEPlainText,
+	".json":     MIMEJSON,
+	".doc":      MIMEMSWord,
+	".zip":      MIMEZip,
+}
+
+// canonicalExtensions is the extension stored for each detected type
+var canonicalExtensions = map[string]string{
+	MIMEPDF:       ".pdf",
+	MIMEDOCX:      ".docx",
+	MIMEODT:       ".odt",
+	MIMERTF:       ".rtf",
+	MIMEHTML:      ".html",
+	MIMEMarkdown:  ".md",
+	MIMEPlainText: ".txt",
+	MIMEJSON:      ".json",
+	MIMEMSWord:    ".doc",
+	MIMEZip:       ".zip",
+}
+
+// htmlSignatures start an HTML document, after leading whitespace and case folding
+var htmlSignatures = []string{"<!doctype html", "<html", "<head", "<body"}
+
+// DeclaredFileType returns the MIME type declared by a filename's
+// extension, or MIMEUnknown
+func DeclaredFileType(filename string) string {
+	if mimeType, ok := fileExtensions[strings.ToLower(filepath.Ext(filename))]; ok {
+		return mimeType
+	}
+	return MIMEUnknown
+}
+
+// FileExtension returns the usual extension for a MIME type
+func FileExtension(mimeType string) string {
+	return canonicalExtensions[mimeType]
+}
+
+// DetectFileType identifies a file from its content: magic bytes for
+// binary formats, the manifest of ZIP containers, and markup for text. The
+// declared type only decides between text formats that look alike, such as
+// Markdown and plain text.
+func DetectFileType(filePath, declared string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %v", err)
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	head = head[:n]
+
+	switch {
+	case len(head) == 0:
+		return MIMEUnknown, nil
+	case bytes.Contains(head[:min(len(head), 1024)], []byte("%PDF-")):
+		// Readers accept a PDF header anywhere in the first kilobyte
+		return MIMEPDF, nil
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return detectZipType(filePath)
+	case bytes.HasPrefix(head, []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")):
+		// OLE compound file, as written by Word 97-2003
+		return MIMEMSWord, nil
+	case bytes.HasPrefix(head, []byte(`{\rtf`)):
+		return MIMERTF, nil
+	}
+
+	text, ok := decodeTextHead(head)
+	if !ok {
+		return MIMEUnknown, nil
+	}
+
+	trimmed := strings.ToLower(strings.TrimSpace(text))
+	for _, signature := range htmlSignatures {
+		if strings.HasPrefix(trimmed, signature) {
+			return MIMEHTML, nil
+		}
+	}
+	switch {
+	case declared == MIMEHTML && strings.HasPrefix(trimmed, "<"):
+		// Fragments saved by site builders start with an element but no <html>
+		return MIMEHTML, nil
+	case strings.HasPrefix(trimmed, "{") && (declared == MIMEJSON || strings.Contains(trimmed, `"basics"`)):
+		// A JSON Resume document
+		return MIMEJSON, nil
+	case declared == MIMEMarkdown:
+		return MIMEMarkdown, nil
+	}
+	return MIMEPlainText, nil
+}
+
+// detectZipType tells OpenDocument and Office Open XML files apart from
+// other ZIP archives by their manifest
+func detectZipType(filePath string) (string, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		// A truncated or damaged archive cannot be extracted either
+		return MIMEZip, nil
+	}
+	defer archive.Close()
+
+	hasContentTypes, hasWordDocument := false, false
+	for _, f := range archive.File {
+		switch f.Name {
+		case "mimetype":
+			// OpenDocument stores its MIME type uncompressed as the first entry
+			rc, err := f.Open()
+			if err != nil {
+				continue
+			}
+			data, _ := io.ReadAll(io.LimitReader(rc, 256))
+			rc.Close()
+			if strings.TrimSpace(string(data)) == MIMEODT {
+				return MIMEODT, nil
+			}
+		case "[Content_Types].xml":
+			hasContentTypes = true
+		case "word/document.xml":
+			hasWordDocument = true
+		}
+	}
+
+	if hasContentTypes && hasWordDocument {
+		return MIMEDOCX, nil
+	}
+	return MIMEZip, nil
+}
+
+// decodeTextHead reports whether the start of a file is text, returning it
+// as a string. UTF-16 is recognized by its byte order mark.
+func decodeTextHead(head []byte) (string, bool) {
+	if text, ok := decodeUTF16(head); ok {
+		return text, !strings.ContainsRune(text, 0)
+	}
+	head = bytes.TrimPrefix(head, utf8BOM)
+
+	if bytes.IndexByte(head, 0) >= 0 {
+		return "", false
+	}
+
+	// The read may end inside a multi-byte character
+	valid := head
+	for i := 0; i < utf8.UTFMax && len(valid) > 0 && !utf8.Valid(valid); i++ {
+		valid = valid[:len(valid)-1]
+	}
+	if utf8.Valid(valid) {
+		return string(valid), true
+	}
+
+	// Legacy 8-bit encodings: accept when control characters are rare
+	control := 0
+	for _, b := range head {
+		if b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != '\f' {
+			control++
+		}
+	}
+	return string(head), control*100 <= len(head)
+}
+
+// utf8BOM is the byte order mark some editors write at the start of UTF-8 files
+var utf8BOM = []byte("\xEF\xBB\xBF")
+
+// decodeText returns the content of a text file as UTF-8, dropping a byte
+// order mark and decoding UTF-16, as saved by Windows Notepad as "Unicode"
+func decodeText(data []byte) string {
+	if text, ok := decodeUTF16(data); ok {
+		return text
+	}
+	return string(bytes.TrimPrefix(data, utf8BOM))
+}
+
+// decodeUTF16 decodes text that starts with a UTF-16 byte order mark. A
+// trailing odd byte or unpaired surrogate, left by a read that ended inside
+// a character, is dropped.
+func decodeUTF16(data []byte) (string, bool) {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte("\xFF\xFE")):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte("\xFE\xFF")):
+		order = binary.BigEndian
+	default:
+		return "", false
+	}
+
+	data = data[2:]
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	if n := len(units); n > 0 && utf16.IsSurrogate(rune(units[n-1])) && units[n-1] < 0xDC00 {
+		units = units[:n-1]
+	}
+	return string(utf16.Decode(units)), true
+}
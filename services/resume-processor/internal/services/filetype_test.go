@@ -0,0 +1,137 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// writeTestFile writes data to a file in a temporary directory
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// zipBytes returns a ZIP archive of the named entries, in order
+func zipBytes(t *testing.T, entries ...[2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, err := archive.Create(entry[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(entry[1]))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// utf16Bytes encodes text as UTF-16 with a byte order mark
+func utf16Bytes(text string, order binary.ByteOrder) []byte {
+	units := utf16.Encode([]rune("\uFEFF" + text))
+	data := make([]byte, 2*len(units))
+	for i, unit := range units {
+		order.PutUint16(data[2*i:], unit)
+	}
+	return data
+}
+
+func TestDetectFileType(t *testing.T) {
+	docx, err := os.ReadFile(filepath.Join("testdata", "docx", "paragraphs.docx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A multi-byte character split by the end of the sniffed head
+	truncated := append(bytes.Repeat([]byte("a"), sniffLength-1), "é résumé"...)
+	binaryData := []byte{0x7F, 'E', 'L', 'F', 2, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0x3E, 0}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		declared string
+		want     string
+	}{
+		{name: "PDF", data: []byte("%PDF-1.7\n1 0 obj\n<< >>\nendobj\n"), want: MIMEPDF},
+		{name: "PDF after junk", data: []byte("\r\n\x00garbage%PDF-1.4\n"), want: MIMEPDF},
+		{name: "DOCX", data: docx, declared: MIMEPDF, want: MIMEDOCX},
+		{name: "ODT", data: zipBytes(t, [2]string{"mimetype", MIMEODT}, [2]string{"content.xml", "<office:document-content/>"}), want: MIMEODT},
+		{name: "other ZIP", data: zipBytes(t, [2]string{"resume.txt", "Jordan Lee"}), declared: MIMEDOCX, want: MIMEZip},
+		{name: "damaged ZIP", data: []byte("PK\x03\x04\x14\x00"), want: MIMEZip},
+		{name: "Word 97", data: []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1\x00\x00"), want: MIMEMSWord},
+		{name: "RTF", data: []byte(`{\rtf1\ansi Jordan Lee\par}`), declared: MIMEPlainText, want: MIMERTF},
+		{name: "HTML", data: []byte("  <!DOCTYPE html>\n<html><body>Jordan Lee</body></html>"), declared: MIMEPlainText, want: MIMEHTML},
+		{name: "HTML fragment", data: []byte("<div><h1>Jordan Lee</h1></div>"), declared: MIMEHTML, want: MIMEHTML},
+		{name: "JSON Resume", data: []byte(`{"basics": {"name": "Jordan Lee"}}`), want: MIMEJSON},
+		{name: "Markdown", data: []byte("# Jordan Lee\n\n## Experience\n"), declared: MIMEMarkdown, want: MIMEMarkdown},
+		{name: "plain text", data: []byte("Jordan Lee\nPlatform engineer\n"), declared: MIMEPDF, want: MIMEPlainText},
+		{name: "UTF-8 with BOM", data: []byte("\xEF\xBB\xBFJordan Lee\nIngénieur plateforme\n"), want: MIMEPlainText},
+		{name: "HTML with BOM", data: []byte("\xEF\xBB\xBF<html><body>Jordan Lee</body></html>"), want: MIMEHTML},
+		{name: "UTF-16LE", data: utf16Bytes("Jordan Lee\nIngénieur plateforme\n", binary.LittleEndian), want: MIMEPlainText},
+		{name: "UTF-16BE HTML", data: utf16Bytes("<html><body>Jordan Lee</body></html>", binary.BigEndian), want: MIMEHTML},
+		{name: "truncated multi-byte character", data: truncated, want: MIMEPlainText},
+		{name: "Windows-1252", data: []byte("Jordan Lee\nIng\xe9nieur plateforme\n"), want: MIMEPlainText},
+		{name: "binary", data: binaryData, declared: MIMEPlainText, want: MIMEUnknown},
+		{name: "control characters", data: bytes.Repeat([]byte("\x01\x02\x03\x80\x81"), 40), want: MIMEUnknown},
+		{name: "empty", data: nil, declared: MIMEPlainText, want: MIMEUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectFileType(writeTestFile(t, "upload", tt.data), tt.declared)
+			if err != nil {
+				t.Fatalf("DetectFileType: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectFileType = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractDocumentDecodesUTF16(t *testing.T) {
+	text := "Jordan Lee\nIngénieur plateforme à Berlin\nGo, Kubernetes, PostgreSQL 😀\n"
+	te := NewTextExtractor()
+
+	for name, data := range map[string][]byte{
+		"le.txt": utf16Bytes(text, binary.LittleEndian),
+		"be.txt": utf16Bytes(text, binary.BigEndian),
+		"le.md":  utf16Bytes("# Jordan Lee\n\nIngénieur plateforme à Berlin\n", binary.LittleEndian),
+	} {
+		doc, err := te.ExtractDocument(context.Background(), writeTestFile(t, name, data))
+		if err != nil {
+			t.Fatalf("%s: ExtractDocument: %v", name, err)
+		}
+		if strings.ContainsRune(doc.Text, 0) || strings.ContainsRune(doc.Text, '\uFEFF') {
+			t.Errorf("%s: text has NULs or a byte order mark: %q", name, doc.Text)
+		}
+		if !strings.Contains(doc.Text, "Jordan Lee") || !strings.Contains(doc.Text, "Ingénieur plateforme à Berlin") {
+			t.Errorf("%s: text = %q", name, doc.Text)
+		}
+	}
+}
+
+func TestDecodeUTF16DropsSplitCharacter(t *testing.T) {
+	data := utf16Bytes("ok 😀", binary.LittleEndian)
+	// Cut inside the surrogate pair, then inside a code unit
+	for _, cut := range []int{len(data) - 2, len(data) - 1, len(data) - 3} {
+		text, ok := decodeUTF16(data[:cut])
+		if !ok || strings.ContainsRune(text, '�') || !strings.HasPrefix(text, "ok") {
+			t.Errorf("cut at %d: %q, %v", cut, text, ok)
+		}
+	}
+}
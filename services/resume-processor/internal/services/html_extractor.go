@@ -0,0 +1,252 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// htmlSkippedElements hold scripts, styling and controls rather than resume text
+var htmlSkippedElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Svg: true, atom.Iframe: true, atom.Object: true,
+	atom.Button: true, atom.Select: true, atom.Input: true, atom.Textarea: true,
+}
+
+// htmlBlockElements start and end on their own line
+var htmlBlockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Header: true, atom.Footer: true, atom.Main: true, atom.Aside: true,
+	atom.Nav: true, atom.Address: true, atom.Blockquote: true, atom.Dl: true,
+	atom.Dt: true, atom.Dd: true, atom.Figure: true, atom.Figcaption: true,
+	atom.Form: true, atom.Fieldset: true, atom.Ul: true, atom.Ol: true,
+	atom.Hr: true, atom.Caption: true, atom.Summary: true, atom.Details: true,
+}
+
+// htmlHeadingElements are rendered with a blank line on either side
+var htmlHeadingElements = map[atom.Atom]bool{
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// htmlWhitespace matches the whitespace runs that collapse to one space
+var htmlWhitespace = regexp.MustCompile(`\s+`)
+
+// htmlList tracks an open ul or ol element
+type htmlList struct {
+	ordered bool
+	n       int
+}
+
+// htmlRenderer renders an HTML tree as plain text
+type htmlRenderer struct {
+	textLines
+	line  strings.Builder
+	lists []htmlList
+	pre   int
+}
+
+// extractFromHTML extracts text from an HTML page, such as a LinkedIn or site
+// builder export, keeping headings, paragraphs, list items, links and table rows
+func (te *TextExtractor) extractFromHTML(ctx context.Context, filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read HTML file: %v", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// Older exports are often Windows-1252; the parser expects UTF-8
+	reader, err := charset.NewReader(bytes.NewReader(content), "text/html")
+	if err != nil {
+		return "", fmt.Errorf("failed to decode HTML: %v", err)
+	}
+
+	doc, err := html.Parse(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %v", err)
+	}
+
+	r := &htmlRenderer{}
+	r.render(doc)
+	r.breakLine()
+
+	return te.finalCleanup(strings.Join(r.lines, "\n")), nil
+}
+
+// render writes a node and its children
+func (r *htmlRenderer) render(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if r.pre > 0 {
+			lines := strings.Split(n.Data, "\n")
+			for i, line := range lines {
+				if i > 0 {
+					r.breakLine()
+				}
+				r.line.WriteString(line)
+			}
+			return
+		}
+		r.line.WriteString(htmlWhitespace.ReplaceAllString(n.Data, " "))
+		return
+	case html.CommentNode, html.DoctypeNode:
+		return
+	case html.ElementNode:
+		if htmlSkippedElements[n.DataAtom] || htmlHasAttr(n, "hidden") || htmlAttr(n, "aria-hidden") == "true" {
+			return
+		}
+	}
+
+	switch {
+	case n.DataAtom == atom.Br:
+		r.breakLine()
+	case n.DataAtom == atom.Img:
+		if alt := strings.TrimSpace(htmlAttr(n, "alt")); alt != "" {
+			r.line.WriteString(" " + alt + " ")
+		}
+	case htmlHeadingElements[n.DataAtom]:
+		r.breakLine()
+		r.emit("")
+		r.renderChildren(n)
+		r.breakLine()
+		r.emit("")
+	case n.DataAtom == atom.Li:
+		r.breakLine()
+		r.line.WriteString(r.listMarker() + " ")
+		r.renderChildren(n)
+		r.breakLine()
+	case n.DataAtom == atom.Ul || n.DataAtom == atom.Ol:
+		r.breakLine()
+		start := 0
+		if value, err := strconv.Atoi(htmlAttr(n, "start")); err == nil {
+			start = value - 1
+		}
+		r.lists = append(r.lists, htmlList{ordered: n.DataAtom == atom.Ol, n: start})
+		r.renderChildren(n)
+		r.lists = r.lists[:len(r.lists)-1]
+		r.breakLine()
+	case n.DataAtom == atom.Pre:
+		r.breakLine()
+		r.pre++
+		r.renderChildren(n)
+		r.pre--
+		r.breakLine()
+	case n.DataAtom == atom.A:
+		r.renderLink(n)
+	case n.DataAtom == atom.Table:
+		r.breakLine()
+		r.openTable()
+		r.renderChildren(n)
+		r.closeTable()
+	case n.DataAtom == atom.Tr:
+		table := r.table()
+		if table == nil {
+			r.breakLine()
+			r.renderChildren(n)
+			r.breakLine()
+			return
+		}
+		table.row = nil
+		r.renderChildren(n)
+		r.endRow()
+	case n.DataAtom == atom.Td || n.DataAtom == atom.Th:
+		table := r.table()
+		if table == nil {
+			r.renderChildren(n)
+			return
+		}
+		r.breakLine()
+		table.cell = nil
+		r.renderChildren(n)
+		r.breakLine()
+		r.endCell()
+	case htmlBlockElements[n.DataAtom]:
+		r.breakLine()
+		r.renderChildren(n)
+		r.breakLine()
+	default:
+		r.renderChildren(n)
+	}
+}
+
+// renderChildren renders the children of a node in order
+func (r *htmlRenderer) renderChildren(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		r.render(child)
+	}
+}
+
+// renderLink renders link text, followed by the target when the text does
+// not already show it, so profile and portfolio URLs are not lost
+func (r *htmlRenderer) renderLink(n *html.Node) {
+	before := r.line.Len()
+	r.renderChildren(n)
+	text := r.line.String()[min(before, r.line.Len()):]
+
+	href := strings.TrimSpace(htmlAttr(n, "href"))
+	target := strings.TrimPrefix(href, "mailto:")
+	if target == href && !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+		return
+	}
+
+	shown := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(target, "https://"), "http://"), "/")
+	if shown != "" && !strings.Contains(text, shown) {
+		r.line.WriteString(" (" + target + ")")
+	}
+}
+
+// listMarker returns the bullet or item number for the next item of the innermost list
+func (r *htmlRenderer) listMarker() string {
+	if len(r.lists) == 0 {
+		return "•"
+	}
+	list := &r.lists[len(r.lists)-1]
+	list.n++
+	if list.ordered {
+		return strconv.Itoa(list.n) + "."
+	}
+	return "•"
+}
+
+// breakLine ends the current line if it holds any text
+func (r *htmlRenderer) breakLine() {
+	line := r.line.String()
+	r.line.Reset()
+	if r.pre == 0 {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return
+		}
+	}
+	r.emit(line)
+}
+
+// htmlAttr returns the value of an attribute of an element
+func htmlAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// htmlHasAttr reports whether an element has an attribute, including
+// boolean attributes such as hidden that carry no value
+func htmlHasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractFromHTMLFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    string
+	}{
+		{
+			// Scripts, styles, noscript, hidden elements and controls are
+			// skipped wherever they appear; lists keep their markers and
+			// start numbers, and link targets the text hides are kept
+			fixture: "scripts.html",
+			want: "Jordan Lee\n" +
+				"\n" +
+				"Platform engineer in Berlin.\n" +
+				"\n" +
+				"Skills\n" +
+				"\n" +
+				"• Go\n" +
+				"• Kubernetes\n" +
+				"3. Third\n" +
+				"4. Fourth\n" +
+				"Profile: my site (https://example.com/jordan)\n" +
+				"line one\n" +
+				"indented line",
+		},
+	}
+
+	te := NewTextExtractor()
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := te.extractFromHTML(context.Background(), filepath.Join("testdata", "html", tt.fixture))
+			if err != nil {
+				t.Fatalf("extractFromHTML: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("extracted text\n got: %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,427 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Names of the built-in job site adapters, used as the Source of their postings
+const (
+	JobSiteGreenhouse = "greenhouse"
+	JobSiteLever      = "lever"
+	JobSiteAshby      = "ashby"
+	JobSiteWorkday    = "workday"
+)
+
+// fetchJobJSON requests a job site API endpoint and decodes its response
+func fetchJobJSON(ctx context.Context, client *http.Client, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", scraperUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("job posting not found; it may have been closed")
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("job API returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode job API response: %w", err)
+	}
+	return nil
+}
+
+// apiBase returns an adapter's configured API base URL, or its default
+func apiBase(configured, fallback string) string {
+	if configured != "" {
+		return strings.TrimSuffix(configured, "/")
+	}
+	return fallback
+}
+
+// pathSegments splits a URL path into its non-empty segments
+func pathSegments(u *url.URL) []string {
+	var segments []string
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// isoDate returns the date part of an ISO 8601 timestamp
+func isoDate(value string) string {
+	if len(value) < 10 {
+		return ""
+	}
+	if _, err := time.Parse("2006-01-02", value[:10]); err != nil {
+		return ""
+	}
+	return value[:10]
+}
+
+// salaryUnit reads the period of a pay interval such as "per-year-salary" or "1 YEAR"
+func salaryUnit(interval string) string {
+	interval = strings.ToUpper(interval)
+	for _, unit := range []string{"HOUR", "DAY", "WEEK", "MONTH", "YEAR"} {
+		if strings.Contains(interval, unit) {
+			return unit
+		}
+	}
+	return ""
+}
+
+// mentionsRemote reports whether a location reads as remote work
+func mentionsRemote(location string) bool {
+	return strings.Contains(strings.ToLower(location), "remote")
+}
+
+// GreenhouseAdapter reads boards.greenhouse.io and job-boards.greenhouse.io
+// postings from the Greenhouse job board API
+type GreenhouseAdapter struct {
+	APIBase string // Defaults to https://boards-api.greenhouse.io
+}
+
+// Name implements JobSiteAdapter
+func (a *GreenhouseAdapter) Name() string { return JobSiteGreenhouse }
+
+// Match implements JobSiteAdapter
+func (a *GreenhouseAdapter) Match(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	return host == "greenhouse.io" || strings.HasSuffix(host, ".greenhouse.io")
+}
+
+// Fetch implements JobSiteAdapter. Postings are addressed as
+// /<board>/jobs/<id> or, when embedded, /embed/job_app?for=<board>&token=<id>.
+func (a *GreenhouseAdapter) Fetch(ctx context.Context, client *http.Client, u *url.URL) (*JobPosting, error) {
+	board, id := "", ""
+	segments := pathSegments(u)
+	switch {
+	case len(segments) >= 3 && segments[1] == "jobs":
+		board, id = segments[0], segments[2]
+	case len(segments) >= 2 && segments[0] == "embed":
+		board, id = u.Query().Get("for"), u.Query().Get("token")
+	}
+	if board == "" || id == "" {
+		return nil, ErrNotJobPage
+	}
+
+	var job struct {
+		Title          string `json:"title"`
+		CompanyName    string `json:"company_name"`
+		AbsoluteURL    string `json:"absolute_url"`
+		Content        string `json:"content"` // HTML, entity-escaped
+		FirstPublished string `json:"first_published"`
+		UpdatedAt      string `json:"updated_at"`
+		Location       struct {
+			Name string `json:"name"`
+		} `json:"location"`
+		PayInputRanges []struct {
+			MinCents     float64 `json:"min_cents"`
+			MaxCents     float64 `json:"max_cents"`
+			CurrencyType string  `json:"currency_type"`
+		} `json:"pay_input_ranges"`
+	}
+	endpoint := fmt.Sprintf("%s/v1/boards/%s/jobs/%s?pay_transparency=true",
+		apiBase(a.APIBase, "https://boards-api.greenhouse.io"), url.PathEscape(board), url.PathEscape(id))
+	if err := fetchJobJSON(ctx, client, endpoint, &job); err != nil {
+		return nil, err
+	}
+
+	posting := &JobPosting{
+		Title:       job.Title,
+		Company:     job.CompanyName,
+		Location:    job.Location.Name,
+		Remote:      mentionsRemote(job.Location.Name),
+		DatePosted:  isoDate(job.FirstPublished),
+		Description: jobDescriptionFromHTML(job.Content),
+		URL:         job.AbsoluteURL,
+	}
+	if posting.Company == "" {
+		posting.Company = board
+	}
+	if posting.DatePosted == "" {
+		posting.DatePosted = isoDate(job.UpdatedAt)
+	}
+	if len(job.PayInputRanges) > 0 {
+		pay := job.PayInputRanges[0]
+		posting.Salary = &JobSalary{Currency: pay.CurrencyType, Min: pay.MinCents / 100, Max: pay.MaxCents / 100}
+	}
+	return posting, nil
+}
+
+// LeverAdapter reads jobs.lever.co postings from the Lever postings API
+type LeverAdapter struct {
+	APIBase string // Defaults to https://api.lever.co, or https://api.eu.lever.co for jobs.eu.lever.co
+}
+
+// Name implements JobSiteAdapter
+func (a *LeverAdapter) Name() string { return JobSiteLever }
+
+// Match implements JobSiteAdapter
+func (a *LeverAdapter) Match(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	return host == "jobs.lever.co" || host == "jobs.eu.lever.co"
+}
+
+// Fetch implements JobSiteAdapter. Postings are addressed as
+// /<company>/<id>, optionally followed by /apply.
+func (a *LeverAdapter) Fetch(ctx context.Context, client *http.Client, u *url.URL) (*JobPosting, error) {
+	segments := pathSegments(u)
+	if len(segments) < 2 {
+		return nil, ErrNotJobPage
+	}
+	fallback := "https://api.lever.co"
+	if strings.EqualFold(u.Hostname(), "jobs.eu.lever.co") {
+		fallback = "https://api.eu.lever.co"
+	}
+
+	var job struct {
+		Text       string `json:"text"`
+		HostedURL  string `json:"hostedUrl"`
+		CreatedAt  int64  `json:"createdAt"` // Milliseconds since the epoch
+		Categories struct {
+			Commitment   string   `json:"commitment"`
+			Location     string   `json:"location"`
+			AllLocations []string `json:"allLocations"`
+		} `json:"categories"`
+		WorkplaceType string `json:"workplaceType"`
+		Description   string `json:"description"`
+		Lists         []struct {
+			Text    string `json:"text"`
+			Content string `json:"content"` // li elements
+		} `json:"lists"`
+		Additional  string `json:"additional"`
+		SalaryRange *struct {
+			Currency string  `json:"currency"`
+			Interval string  `json:"interval"`
+			Min      float64 `json:"min"`
+			Max      float64 `json:"max"`
+		} `json:"salaryRange"`
+	}
+	endpoint := fmt.Sprintf("%s/v0/postings/%s/%s", apiBase(a.APIBase, fallback), url.PathEscape(segments[0]), url.PathEscape(segments[1]))
+	if err := fetchJobJSON(ctx, client, endpoint, &job); err != nil {
+		return nil, err
+	}
+
+	description := job.Description
+	for _, list := range job.Lists {
+		description += "<h3>" + html.EscapeString(list.Text) + "</h3><ul>" + list.Content + "</ul>"
+	}
+	description += job.Additional
+
+	locations := job.Categories.AllLocations
+	if len(locations) == 0 && job.Categories.Location != "" {
+		locations = []string{job.Categories.Location}
+	}
+	posting := &JobPosting{
+		Title:          job.Text,
+		Company:        segments[0], // The API does not name the company
+		Location:       strings.Join(locations, "; "),
+		Remote:         strings.EqualFold(job.WorkplaceType, "remote") || mentionsRemote(job.Categories.Location),
+		EmploymentType: job.Categories.Commitment,
+		Description:    jobDescriptionFromHTML(description),
+		URL:            job.HostedURL,
+	}
+	if job.CreatedAt > 0 {
+		posting.DatePosted = time.UnixMilli(job.CreatedAt).UTC().Format("2006-01-02")
+	}
+	if job.SalaryRange != nil && (job.SalaryRange.Min > 0 || job.SalaryRange.Max > 0) {
+		posting.Salary = &JobSalary{
+			Currency: job.SalaryRange.Currency,
+			Min:      job.SalaryRange.Min,
+			Max:      job.SalaryRange.Max,
+			Unit:     salaryUnit(job.SalaryRange.Interval),
+		}
+	}
+	return posting, nil
+}
+
+// AshbyAdapter reads jobs.ashbyhq.com postings from the Ashby job board API
+type AshbyAdapter struct {
+	APIBase string // Defaults to https://api.ashbyhq.com
+}
+
+// Name implements JobSiteAdapter
+func (a *AshbyAdapter) Name() string { return JobSiteAshby }
+
+// Match implements JobSiteAdapter
+func (a *AshbyAdapter) Match(u *url.URL) bool {
+	return strings.EqualFold(u.Hostname(), "jobs.ashbyhq.com")
+}
+
+// Fetch implements JobSiteAdapter. Postings are addressed as
+// /<organization>/<id>, optionally followed by /application; the API lists
+// the whole board, which is searched for the ID.
+func (a *AshbyAdapter) Fetch(ctx context.Context, client *http.Client, u *url.URL) (*JobPosting, error) {
+	segments := pathSegments(u)
+	if len(segments) < 2 {
+		return nil, ErrNotJobPage
+	}
+	organization, id := segments[0], segments[1]
+
+	var board struct {
+		Jobs []struct {
+			ID                 string `json:"id"`
+			Title              string `json:"title"`
+			Location           string `json:"location"`
+			SecondaryLocations []struct {
+				Location string `json:"location"`
+			} `json:"secondaryLocations"`
+			IsRemote        bool   `json:"isRemote"`
+			EmploymentType  string `json:"employmentType"` // e.g. FullTime
+			DescriptionHTML string `json:"descriptionHtml"`
+			PublishedAt     string `json:"publishedAt"`
+			JobURL          string `json:"jobUrl"`
+			Compensation    *struct {
+				SummaryComponents []struct {
+					CompensationType string  `json:"compensationType"`
+					Interval         string  `json:"interval"` // e.g. "1 YEAR"
+					CurrencyCode     string  `json:"currencyCode"`
+					MinValue         float64 `json:"minValue"`
+					MaxValue         float64 `json:"maxValue"`
+				} `json:"summaryComponents"`
+			} `json:"compensation"`
+		} `json:"jobs"`
+	}
+	endpoint := fmt.Sprintf("%s/posting-api/job-board/%s?includeCompensation=true", apiBase(a.APIBase, "https://api.ashbyhq.com"), url.PathEscape(organization))
+	if err := fetchJobJSON(ctx, client, endpoint, &board); err != nil {
+		return nil, err
+	}
+
+	for _, job := range board.Jobs {
+		if job.ID != id {
+			continue
+		}
+		locations := []string{job.Location}
+		for _, secondary := range job.SecondaryLocations {
+			locations = append(locations, secondary.Location)
+		}
+		posting := &JobPosting{
+			Title:          job.Title,
+			Company:        organization, // The API does not name the organization
+			Location:       joinNonEmpty(locations, "; "),
+			Remote:         job.IsRemote,
+			EmploymentType: splitCamelCase(job.EmploymentType),
+			DatePosted:     isoDate(job.PublishedAt),
+			Description:    jobDescriptionFromHTML(job.DescriptionHTML),
+			URL:            job.JobURL,
+		}
+		if job.Compensation != nil {
+			for _, component := range job.Compensation.SummaryComponents {
+				if strings.EqualFold(component.CompensationType, "Salary") {
+					posting.Salary = &JobSalary{
+						Currency: component.CurrencyCode,
+						Min:      component.MinValue,
+						Max:      component.MaxValue,
+						Unit:     salaryUnit(component.Interval),
+					}
+					break
+				}
+			}
+		}
+		return posting, nil
+	}
+	return nil, fmt.Errorf("job posting not found; it may have been closed")
+}
+
+// splitCamelCase turns values such as FullTime into "Full time"
+func splitCamelCase(value string) string {
+	var words strings.Builder
+	for i, r := range value {
+		if i > 0 && unicode.IsUpper(r) {
+			words.WriteRune(' ')
+			r = unicode.ToLower(r)
+		}
+		words.WriteRune(r)
+	}
+	return words.String()
+}
+
+// workdayLocale matches the optional locale segment of Workday URLs
+var workdayLocale = regexp.MustCompile(`^[a-z]{2}-[A-Z]{2}$`)
+
+// WorkdayAdapter reads *.myworkdayjobs.com postings from the JSON endpoint
+// behind Workday's career sites
+type WorkdayAdapter struct {
+	APIBase string // Replaces the scheme and host of the posting's URL, for tests
+}
+
+// Name implements JobSiteAdapter
+func (a *WorkdayAdapter) Name() string { return JobSiteWorkday }
+
+// Match implements JobSiteAdapter
+func (a *WorkdayAdapter) Match(u *url.URL) bool {
+	return strings.HasSuffix(strings.ToLower(u.Hostname()), ".myworkdayjobs.com")
+}
+
+// Fetch implements JobSiteAdapter. Postings are addressed as
+// [/<locale>]/<site>/job/<location>/<title>_<requisition>; the tenant is
+// the first label of the host.
+func (a *WorkdayAdapter) Fetch(ctx context.Context, client *http.Client, u *url.URL) (*JobPosting, error) {
+	segments := pathSegments(u)
+	if len(segments) > 0 && workdayLocale.MatchString(segments[0]) {
+		segments = segments[1:]
+	}
+	if len(segments) < 3 || segments[1] != "job" {
+		return nil, ErrNotJobPage
+	}
+	tenant := strings.Split(u.Hostname(), ".")[0]
+	site := segments[0]
+
+	var job struct {
+		JobPostingInfo struct {
+			Title               string   `json:"title"`
+			JobDescription      string   `json:"jobDescription"` // HTML
+			Location            string   `json:"location"`
+			AdditionalLocations []string `json:"additionalLocations"`
+			TimeType            string   `json:"timeType"`
+			RemoteType          string   `json:"remoteType"`
+			StartDate           string   `json:"startDate"`
+			ExternalURL         string   `json:"externalUrl"`
+		} `json:"jobPostingInfo"`
+		HiringOrganization struct {
+			Name string `json:"name"`
+		} `json:"hiringOrganization"`
+	}
+	base := apiBase(a.APIBase, u.Scheme+"://"+u.Host)
+	escaped := make([]string, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+	endpoint := fmt.Sprintf("%s/wday/cxs/%s/%s/%s", base, url.PathEscape(tenant), url.PathEscape(site), strings.Join(escaped, "/"))
+	if err := fetchJobJSON(ctx, client, endpoint, &job); err != nil {
+		return nil, err
+	}
+
+	info := job.JobPostingInfo
+	locations := append([]string{info.Location}, info.AdditionalLocations...)
+	return &JobPosting{
+		Title:          info.Title,
+		Company:        job.HiringOrganization.Name,
+		Location:       joinNonEmpty(locations, "; "),
+		Remote:         mentionsRemote(info.RemoteType) || mentionsRemote(info.Location),
+		EmploymentType: info.TimeType,
+		DatePosted:     isoDate(info.StartDate),
+		Description:    jobDescriptionFromHTML(info.JobDescription),
+		URL:            info.ExternalURL,
+	}, nil
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// jobFixtures maps job site API and page paths to recorded responses in
+// testdata/jobs
+var jobFixtures = map[string]string{
+	"/v1/boards/acme/jobs/4012345":                                      "greenhouse.json",
+	"/v0/postings/acme/5ac21346-8e0c-4494-8e7a-3eb92ff77902":            "lever.json",
+	"/posting-api/job-board/acme":                                       "ashby.json",
+	"/wday/cxs/acme/External/job/Munich/Cloud-Security-Engineer_R-1234": "workday.json",
+	// Pages the generic scraper reads when no adapter applies
+	"/acme":                     "board.html",
+	"/External":                 "board.html",
+	"/careers/support-engineer": "board.html",
+}
+
+// routeTransport sends every request to a test server, so that pages on
+// job site hosts can be served by it
+type routeTransport struct {
+	next   http.RoundTripper
+	target *url.URL
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *routeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return t.next.RoundTrip(req)
+}
+
+// newJobFixtureScraper returns a scraper whose adapters call a test server
+// serving jobFixtures, and a function listing the paths requested from it
+func newJobFixtureScraper(t *testing.T) (*JobScraper, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		name, ok := jobFixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", "jobs", name))
+		if err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if filepath.Ext(name) == ".json" {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	policy := testFetchPolicy()
+	policy.MaxBodyBytes = 1 << 20
+	client := loopbackClient(policy)
+	target, _ := url.Parse(server.URL)
+	client.Transport = &routeTransport{next: client.Transport, target: target}
+
+	scraper := NewJobScraper()
+	scraper.client = client
+	scraper.SetAdapters(
+		&GreenhouseAdapter{APIBase: server.URL},
+		&LeverAdapter{APIBase: server.URL},
+		&AshbyAdapter{APIBase: server.URL},
+		&WorkdayAdapter{APIBase: server.URL},
+	)
+	return scraper, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requested...)
+	}
+}
+
+func TestJobSiteAdapters(t *testing.T) {
+	tests := []struct {
+		url  string
+		want JobPosting
+	}{
+		{
+			url: "https://boards.greenhouse.io/acme/jobs/4012345",
+			want: JobPosting{
+				Title:       "Senior Backend Engineer",
+				Company:     "Acme Corp",
+				Location:    "Berlin, Germany",
+				Salary:      &JobSalary{Currency: "EUR", Min: 85000, Max: 105000},
+				DatePosted:  "2024-05-02",
+				Description: "Acme builds billing software for 10,000 companies.\n\nWhat you will do\n\n• Design Go services on Kubernetes\n• Own the Postgres schema",
+				URL:         "https://boards.greenhouse.io/acme/jobs/4012345",
+				Source:      JobSiteGreenhouse,
+			},
+		},
+		{
+			url: "https://jobs.lever.co/acme/5ac21346-8e0c-4494-8e7a-3eb92ff77902/apply",
+			want: JobPosting{
+				Title:          "Platform Engineer",
+				Company:        "acme",
+				Location:       "Lisbon; Porto",
+				EmploymentType: "Full-time",
+				Salary:         &JobSalary{Currency: "EUR", Min: 60000, Max: 75000, Unit: "YEAR"},
+				DatePosted:     "2024-05-02",
+				Description:    "Acme runs the payments platform for small shops.\n\nRequirements\n\n• Five years of Go\n• Experience with Kafka\nWe offer a learning budget and 30 days of leave.",
+				URL:            "https://jobs.lever.co/acme/5ac21346-8e0c-4494-8e7a-3eb92ff77902",
+				Source:         JobSiteLever,
+			},
+		},
+		{
+			// The board lists two jobs; the one in the URL is picked
+			url: "https://jobs.ashbyhq.com/acme/7d4e9b20-2222-4f0e-8f61-4a0c9e3d7b52/application",
+			want: JobPosting{
+				Title:          "Staff Site Reliability Engineer",
+				Company:        "acme",
+				Location:       "Remote - Europe; Amsterdam",
+				Remote:         true,
+				EmploymentType: "Full time",
+				Salary:         &JobSalary{Currency: "EUR", Min: 110000, Max: 140000, Unit: "YEAR"},
+				DatePosted:     "2024-05-06",
+				Description:    "Keep our fleet of 2,000 nodes healthy.\n• Run incident reviews\n• Automate capacity planning",
+				URL:            "https://jobs.ashbyhq.com/acme/7d4e9b20-2222-4f0e-8f61-4a0c9e3d7b52",
+				Source:         JobSiteAshby,
+			},
+		},
+		{
+			// The locale segment is not part of the API path
+			url: "https://acme.wd3.myworkdayjobs.com/en-US/External/job/Munich/Cloud-Security-Engineer_R-1234",
+			want: JobPosting{
+				Title:          "Cloud Security Engineer",
+				Company:        "Acme Corp",
+				Location:       "Munich; Frankfurt",
+				EmploymentType: "Full time",
+				DatePosted:     "2024-05-10",
+				Description:    "About the role\nSecure our AWS estate.\n• Threat modelling\n• IAM reviews",
+				URL:            "https://acme.wd3.myworkdayjobs.com/External/job/Munich/Cloud-Security-Engineer_R-1234",
+				Source:         JobSiteWorkday,
+			},
+		},
+	}
+
+	scraper, _ := newJobFixtureScraper(t)
+	for _, tt := range tests {
+		posting, err := scraper.FetchJobPosting(context.Background(), tt.url)
+		if err != nil {
+			t.Errorf("%s: %v", tt.url, err)
+			continue
+		}
+		if !reflect.DeepEqual(*posting, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.url, *posting, tt.want)
+		}
+	}
+}
+
+func TestJobSiteAdaptersFallBackToPage(t *testing.T) {
+	// Board pages are not postings, and unknown hosts have no adapter; both
+	// are scraped as HTML without calling a job site API
+	for _, rawURL := range []string{
+		"https://boards.greenhouse.io/acme",
+		"https://jobs.lever.co/acme",
+		"https://acme.wd3.myworkdayjobs.com/External",
+		"https://careers.example.com/careers/support-engineer",
+	} {
+		scraper, requested := newJobFixtureScraper(t)
+		posting, err := scraper.FetchJobPosting(context.Background(), rawURL)
+		if err != nil {
+			t.Errorf("%s: %v", rawURL, err)
+			continue
+		}
+		u, _ := url.Parse(rawURL)
+		if paths := requested(); len(paths) != 1 || paths[0] != u.Path {
+			t.Errorf("%s: requested %q, want only the page", rawURL, paths)
+		}
+		if posting.Source != JobPostingSourceJSONLD || posting.Title != "Support Engineer" || posting.Company != "Acme Corp" {
+			t.Errorf("%s: posting = %+v", rawURL, posting)
+		}
+	}
+}
+
+func TestAshbyAdapterMissingJob(t *testing.T) {
+	scraper, _ := newJobFixtureScraper(t)
+	_, err := scraper.FetchJobPosting(context.Background(), "https://jobs.ashbyhq.com/acme/00000000-0000-0000-0000-000000000000")
+	if err == nil || err.Error() != "ashby: job posting not found; it may have been closed" {
+		t.Errorf("err = %v", err)
+	}
+}
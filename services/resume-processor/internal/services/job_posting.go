@@ -0,0 +1,624 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Where the fields of a job posting were read from
+const (
+	JobPostingSourceJSONLD    = "json-ld"   // schema.org JobPosting in an application/ld+json script
+	JobPostingSourceMicrodata = "microdata" // schema.org JobPosting itemscope
+	JobPostingSourceOpenGraph = "opengraph" // og:title, og:description and og:site_name tags
+	JobPostingSourceHeuristic = "heuristic" // Page text around job-related keywords
+)
+
+const (
+	// minJobDescriptionLength is the length below which an og:description
+	// teaser is replaced by the page text
+	minJobDescriptionLength = 200
+	// maxJobDescriptionLength caps descriptions read from structured data
+	maxJobDescriptionLength = 20000
+	// maxLDDepth limits how deep JSON-LD documents are searched
+	maxLDDepth = 10
+)
+
+// JobPosting is a job advertisement read from a web page
+type JobPosting struct {
+	Title          string     `json:"title,omitempty"`
+	Company        string     `json:"company,omitempty"`
+	Location       string     `json:"location,omitempty"`
+	Remote         bool       `json:"remote,omitempty"`
+	EmploymentType string     `json:"employment_type,omitempty"` // e.g. "Full time, Contractor"
+	Salary         *JobSalary `json:"salary,omitempty"`
+	DatePosted     string     `json:"date_posted,omitempty"`
+	ValidThrough   string     `json:"valid_through,omitempty"`
+	Description    string     `json:"description"` // Plain text with line breaks and bullets kept
+	URL            string     `json:"url,omitempty"`
+	Source         string     `json:"source"`
+}
+
+// JobSalary is the pay range of a job posting
+type JobSalary struct {
+	Currency string  `json:"currency,omitempty"`
+	Min      float64 `json:"min,omitempty"`
+	Max      float64 `json:"max,omitempty"`
+	Unit     string  `json:"unit,omitempty"` // HOUR, DAY, WEEK, MONTH or YEAR
+}
+
+// Text renders the posting as the job description given to the optimizer:
+// the structured fields as labelled lines, then the description
+func (p *JobPosting) Text() string {
+	var lines []string
+	add := func(label, value string) {
+		if value != "" {
+			lines = append(lines, label+": "+value)
+		}
+	}
+	add("Title", p.Title)
+	add("Company", p.Company)
+	location := p.Location
+	if p.Remote && !mentionsRemote(location) {
+		location = joinNonEmpty([]string{location, "Remote"}, " / ")
+	}
+	add("Location", location)
+	add("Employment type", p.EmploymentType)
+	if p.Salary != nil {
+		add("Salary", p.Salary.String())
+	}
+
+	if len(lines) == 0 {
+		return p.Description
+	}
+	return strings.Join(lines, "\n") + "\n\n" + p.Description
+}
+
+// String formats a salary, e.g. "USD 120,000–150,000 per year"
+func (s *JobSalary) String() string {
+	amount := formatSalaryAmount(s.Min)
+	if s.Max > 0 && s.Max != s.Min {
+		if s.Min > 0 {
+			amount += "–" + formatSalaryAmount(s.Max)
+		} else {
+			amount = "up to " + formatSalaryAmount(s.Max)
+		}
+	}
+	text := joinNonEmpty([]string{s.Currency, amount}, " ")
+	if s.Unit != "" {
+		text += " per " + strings.ToLower(s.Unit)
+	}
+	return text
+}
+
+// formatSalaryAmount writes an amount with thousands separators
+func formatSalaryAmount(amount float64) string {
+	text := strconv.FormatFloat(amount, 'f', -1, 64)
+	whole, fraction, _ := strings.Cut(text, ".")
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	if fraction != "" {
+		return whole + "." + fraction
+	}
+	return whole
+}
+
+// ParseJobPosting reads a job posting from an HTML page. schema.org
+// JobPosting data embedded as JSON-LD is preferred, then microdata; gaps
+// are filled from the OpenGraph tags, and when no usable description is
+// found the page text around job-related keywords is used instead.
+func (js *JobScraper) ParseJobPosting(page, pageURL string) (*JobPosting, error) {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
+
+	posting := jobPostingFromJSONLD(doc)
+	if posting == nil {
+		posting = jobPostingFromMicrodata(doc)
+	}
+	if posting == nil {
+		posting = &JobPosting{}
+	}
+
+	og := openGraphTags(doc)
+	if posting.Source == "" && (og["og:title"] != "" || og["og:description"] != "") {
+		posting.Source = JobPostingSourceOpenGraph
+	}
+	if posting.Title == "" {
+		posting.Title = og["og:title"]
+	}
+	if posting.Company == "" {
+		posting.Company = og["og:site_name"]
+	}
+	if posting.URL == "" {
+		posting.URL = og["og:url"]
+	}
+	if posting.Description == "" {
+		posting.Description = cleanJobText(og["og:description"])
+	}
+
+	// Structured descriptions are kept however short; a teaser from the
+	// OpenGraph tags is not
+	if posting.Description == "" || posting.Source == JobPostingSourceOpenGraph && len(posting.Description) < minJobDescriptionLength {
+		content, err := js.extractTextFromHTML(page)
+		if err != nil {
+			return nil, err
+		}
+		if text := js.cleanJobDescription(content); len(text) > len(posting.Description) {
+			posting.Description = text
+			if posting.Source == "" || posting.Source == JobPostingSourceOpenGraph {
+				posting.Source = JobPostingSourceHeuristic
+			}
+		}
+	}
+
+	if posting.Title == "" {
+		if title := findElement(doc, atom.Title); title != nil {
+			posting.Title = strings.TrimSpace(htmlWhitespace.ReplaceAllString(nodeText(title), " "))
+		}
+	}
+	if posting.URL == "" {
+		posting.URL = pageURL
+	}
+	if strings.TrimSpace(posting.Description) == "" {
+		return nil, fmt.Errorf("no job description found on the page")
+	}
+	return posting, nil
+}
+
+// jobPostingFromJSONLD reads the first JobPosting of the page's JSON-LD scripts
+func jobPostingFromJSONLD(doc *html.Node) *JobPosting {
+	var posting *JobPosting
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if posting != nil {
+			return
+		}
+		if n.Type == html.ElementNode && n.DataAtom == atom.Script {
+			kind, _, _ := strings.Cut(htmlAttr(n, "type"), ";")
+			if strings.EqualFold(strings.TrimSpace(kind), "application/ld+json") {
+				if item := findLDItem(parseJSONLD(nodeText(n)), "JobPosting", 0); item != nil {
+					posting = jobPostingFromSchema(item, jobDescriptionFromHTML)
+					posting.Source = JobPostingSourceJSONLD
+				}
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return posting
+}
+
+// parseJSONLD decodes a JSON-LD script. Raw line breaks and tabs, which
+// some sites leave in string values, are read as spaces.
+func parseJSONLD(script string) interface{} {
+	script = strings.NewReplacer("\r", " ", "\n", " ", "\t", " ").Replace(strings.TrimSpace(script))
+	script = strings.TrimSuffix(strings.TrimPrefix(script, "<!--"), "-->")
+	var data interface{}
+	if err := json.Unmarshal([]byte(script), &data); err != nil {
+		return nil
+	}
+	return data
+}
+
+// findLDItem searches decoded JSON-LD, including @graph lists and
+// nested entities such as a WebPage's mainEntity, for an item of a type
+func findLDItem(v interface{}, kind string, depth int) map[string]interface{} {
+	if depth > maxLDDepth {
+		return nil
+	}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if ldHasType(value["@type"], kind) {
+			return value
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if item := findLDItem(value[key], kind, depth+1); item != nil {
+				return item
+			}
+		}
+	case []interface{}:
+		for _, child := range value {
+			if item := findLDItem(child, kind, depth+1); item != nil {
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+// ldHasType reports whether an @type value, a string or a list, names a
+// type, with or without the schema.org prefix. Strings may hold several
+// types, as microdata itemtype attributes do.
+func ldHasType(v interface{}, kind string) bool {
+	for _, types := range ldStrings(v) {
+		for _, t := range strings.Fields(types) {
+			if strings.EqualFold(path.Base(t), kind) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jobPostingFromSchema maps a schema.org JobPosting item; description
+// converts the description value to plain text
+func jobPostingFromSchema(item map[string]interface{}, description func(string) string) *JobPosting {
+	posting := &JobPosting{
+		Title:        ldString(item["title"]),
+		Company:      ldName(item["hiringOrganization"]),
+		DatePosted:   ldString(item["datePosted"]),
+		ValidThrough: ldString(item["validThrough"]),
+		URL:          ldString(item["url"]),
+		Description:  description(ldString(item["description"])),
+	}
+	if posting.Title == "" {
+		posting.Title = ldString(item["name"])
+	}
+
+	var locations []string
+	for _, place := range ldList(item["jobLocation"]) {
+		if location := ldLocation(place); location != "" && !containsString(locations, location) {
+			locations = append(locations, location)
+		}
+	}
+	posting.Location = strings.Join(locations, "; ")
+	for _, t := range ldStrings(item["jobLocationType"]) {
+		if strings.EqualFold(t, "TELECOMMUTE") {
+			posting.Remote = true
+		}
+	}
+
+	var types []string
+	for _, t := range ldStrings(item["employmentType"]) {
+		for _, part := range strings.Split(t, ",") {
+			if part = humanizeLDEnum(part); part != "" && !containsString(types, part) {
+				types = append(types, part)
+			}
+		}
+	}
+	posting.EmploymentType = strings.Join(types, ", ")
+
+	salary := item["baseSalary"]
+	if salary == nil {
+		salary = item["estimatedSalary"]
+	}
+	posting.Salary = ldSalary(salary)
+	return posting
+}
+
+// ldLocation formats a Place, a PostalAddress or a plain string
+func ldLocation(v interface{}) string {
+	place, ok := v.(map[string]interface{})
+	if !ok {
+		return ldString(v)
+	}
+	address, ok := place["address"].(map[string]interface{})
+	if !ok {
+		if text := ldString(place["address"]); text != "" {
+			return text
+		}
+		if ldHasType(place["@type"], "PostalAddress") {
+			address = place
+		} else {
+			return ldString(place["name"])
+		}
+	}
+	return joinNonEmpty([]string{
+		ldString(address["addressLocality"]),
+		ldString(address["addressRegion"]),
+		ldName(address["addressCountry"]),
+	}, ", ")
+}
+
+// ldSalary reads a MonetaryAmount whose value is a number or a
+// QuantitativeValue with a range
+func ldSalary(v interface{}) *JobSalary {
+	amounts := ldList(v)
+	if len(amounts) == 0 {
+		return nil
+	}
+	money, ok := amounts[0].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	salary := &JobSalary{Currency: strings.ToUpper(ldString(money["currency"]))}
+	switch value := money["value"].(type) {
+	case map[string]interface{}:
+		salary.Min, _ = ldNumber(value["minValue"])
+		salary.Max, _ = ldNumber(value["maxValue"])
+		if exact, ok := ldNumber(value["value"]); ok && salary.Min == 0 && salary.Max == 0 {
+			salary.Min = exact
+		}
+		salary.Unit = strings.ToUpper(ldString(value["unitText"]))
+	default:
+		salary.Min, _ = ldNumber(value)
+	}
+	if salary.Unit == "" {
+		salary.Unit = strings.ToUpper(ldString(money["unitText"]))
+	}
+	if salary.Min == 0 && salary.Max == 0 {
+		return nil
+	}
+	return salary
+}
+
+// ldString returns a text value, with HTML entities decoded as many sites
+// encode them in JSON-LD too; lists yield their first text
+func ldString(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return strings.TrimSpace(html.UnescapeString(value))
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []interface{}:
+		for _, item := range value {
+			if text := ldString(item); text != "" {
+				return text
+			}
+		}
+	case map[string]interface{}:
+		if text, ok := value["@value"]; ok {
+			return ldString(text)
+		}
+	}
+	return ""
+}
+
+// ldStrings returns the text values of a value or a list
+func ldStrings(v interface{}) []string {
+	var texts []string
+	for _, item := range ldList(v) {
+		if text := ldString(item); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// ldName returns the name of an entity such as an Organization or a
+// Country, which may also be given as a plain string
+func ldName(v interface{}) string {
+	if entity, ok := v.(map[string]interface{}); ok {
+		return ldString(entity["name"])
+	}
+	if list, ok := v.([]interface{}); ok && len(list) > 0 {
+		return ldName(list[0])
+	}
+	return ldString(v)
+}
+
+// ldList returns a list value as is and any other value as a list of one
+func ldList(v interface{}) []interface{} {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return value
+	default:
+		return []interface{}{value}
+	}
+}
+
+// ldNumber reads a number given as a JSON number or as text such as "85,000"
+func ldNumber(v interface{}) (float64, bool) {
+	switch value := v.(type) {
+	case float64:
+		return value, true
+	case string:
+		n, err := strconv.ParseFloat(strings.NewReplacer(",", "", " ", "").Replace(value), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// humanizeLDEnum turns values such as FULL_TIME into "Full time"
+func humanizeLDEnum(value string) string {
+	value = strings.TrimSpace(strings.ToLower(strings.NewReplacer("_", " ", "-", " ").Replace(path.Base(value))))
+	if value == "" {
+		return ""
+	}
+	return strings.ToUpper(value[:1]) + value[1:]
+}
+
+// jobDescriptionFromHTML converts a JSON-LD description, which is usually
+// HTML and sometimes escaped twice, to plain text
+func jobDescriptionFromHTML(description string) string {
+	if !strings.Contains(description, "<") && strings.Contains(description, "&lt;") {
+		description = html.UnescapeString(description)
+	}
+	if !strings.Contains(description, "<") {
+		return cleanJobText(description)
+	}
+
+	doc, err := html.Parse(strings.NewReader(description))
+	if err != nil {
+		return cleanJobText(description)
+	}
+	r := &htmlRenderer{}
+	r.render(doc)
+	r.breakLine()
+	return cleanJobText(strings.Join(r.lines, "\n"))
+}
+
+// jobTextBlankLines matches runs of blank lines
+var jobTextBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// cleanJobText trims the lines of a description, keeps at most one blank
+// line between paragraphs and caps its length
+func cleanJobText(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(htmlWhitespace.ReplaceAllString(line, " "))
+	}
+	text = strings.TrimSpace(jobTextBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+	if len(text) > maxJobDescriptionLength {
+		cut := maxJobDescriptionLength
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "..."
+	}
+	return text
+}
+
+// jobPostingFromMicrodata reads the first schema.org JobPosting itemscope
+func jobPostingFromMicrodata(doc *html.Node) *JobPosting {
+	var scope *html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if scope != nil {
+			return
+		}
+		if n.Type == html.ElementNode && htmlHasAttr(n, "itemscope") && ldHasType(htmlAttr(n, "itemtype"), "JobPosting") {
+			scope = n
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if scope == nil {
+		return nil
+	}
+
+	posting := jobPostingFromSchema(microdataItem(scope), cleanJobText)
+	posting.Source = JobPostingSourceMicrodata
+	return posting
+}
+
+// microdataItem converts an itemscope to the shape of a JSON-LD item, with
+// nested itemscopes as nested items and repeated properties as lists
+func microdataItem(scope *html.Node) map[string]interface{} {
+	item := map[string]interface{}{}
+	if types := strings.Fields(htmlAttr(scope, "itemtype")); len(types) > 0 {
+		item["@type"] = path.Base(types[0])
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			nested := htmlHasAttr(c, "itemscope")
+			if props := strings.Fields(htmlAttr(c, "itemprop")); len(props) > 0 {
+				var value interface{}
+				if nested {
+					value = microdataItem(c)
+				} else {
+					value = microdataValue(c)
+				}
+				for _, prop := range props {
+					switch existing := item[prop].(type) {
+					case nil:
+						item[prop] = value
+					case []interface{}:
+						item[prop] = append(existing, value)
+					default:
+						item[prop] = []interface{}{existing, value}
+					}
+				}
+			}
+			if !nested {
+				walk(c)
+			}
+		}
+	}
+	walk(scope)
+	return item
+}
+
+// microdataValue returns the value of an itemprop element: its content,
+// URL or machine-readable attribute, or else its text
+func microdataValue(n *html.Node) string {
+	if htmlHasAttr(n, "content") {
+		return strings.TrimSpace(htmlAttr(n, "content"))
+	}
+	switch n.DataAtom {
+	case atom.A, atom.Link, atom.Area:
+		return htmlAttr(n, "href")
+	case atom.Img, atom.Audio, atom.Video, atom.Source, atom.Iframe, atom.Embed:
+		return htmlAttr(n, "src")
+	case atom.Object:
+		return htmlAttr(n, "data")
+	case atom.Data, atom.Meter:
+		return htmlAttr(n, "value")
+	case atom.Time:
+		if htmlHasAttr(n, "datetime") {
+			return htmlAttr(n, "datetime")
+		}
+	}
+
+	r := &htmlRenderer{}
+	r.renderChildren(n)
+	r.breakLine()
+	return strings.Join(r.lines, "\n")
+}
+
+// openGraphTags returns the content of the page's og: meta tags
+func openGraphTags(doc *html.Node) map[string]string {
+	tags := map[string]string{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Meta {
+			property := htmlAttr(n, "property")
+			if property == "" {
+				property = htmlAttr(n, "name")
+			}
+			property = strings.ToLower(strings.TrimSpace(property))
+			if strings.HasPrefix(property, "og:") && tags[property] == "" {
+				tags[property] = strings.TrimSpace(htmlAttr(n, "content"))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return tags
+}
+
+// findElement returns the first element of a kind
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// nodeText returns the text of a node's children, such as a script's source
+func nodeText(n *html.Node) string {
+	var text strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			text.WriteString(c.Data)
+		}
+	}
+	return text.String()
+}
@@ -1,58 +1,123 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
-	"time"
 
 	"golang.org/x/net/html"
 )
 
+// scraperUserAgent is sent with job page and API requests
+const scraperUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+
+// ErrNotJobPage is returned by site adapters for URLs on their host that do
+// not name a single posting, such as a company's board; the page is then
+// scraped as HTML
+var ErrNotJobPage = errors.New("not a job posting URL")
+
+// JobSiteAdapter reads postings of a job site from its public API, for
+// sites that render their pages with JavaScript
+type JobSiteAdapter interface {
+	// Name identifies the adapter and is used as the posting's Source
+	Name() string
+	// Match reports whether the adapter handles a URL, judged by its host
+	Match(u *url.URL) bool
+	// Fetch reads the posting a URL points to
+	Fetch(ctx context.Context, client *http.Client, u *url.URL) (*JobPosting, error)
+}
+
 // JobScraper handles fetching and extracting job descriptions from URLs
 type JobScraper struct {
-	client *http.Client
+	client   *http.Client
+	policy   FetchPolicy
+	adapters []JobSiteAdapter
 }
 
-// NewJobScraper creates a new JobScraper instance
+// NewJobScraper creates a new JobScraper instance with the built-in
+// Greenhouse, Lever, Ashby and Workday adapters. Pages and APIs are fetched
+// under DefaultFetchPolicy; blocked fetches fail with ErrFetchBlocked.
 func NewJobScraper() *JobScraper {
+	policy := DefaultFetchPolicy()
 	return &JobScraper{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
+		client: NewSafeHTTPClient(policy),
+		policy: policy,
+		adapters: []JobSiteAdapter{
+			&GreenhouseAdapter{},
+			&LeverAdapter{},
+			&AshbyAdapter{},
+			&WorkdayAdapter{},
 		},
 	}
 }
 
-// FetchJobDescription fetches and extracts job description from a URL
-func (js *JobScraper) FetchJobDescription(url string) (string, error) {
+// SetFetchPolicy replaces the policy pages and APIs are fetched under
+func (js *JobScraper) SetFetchPolicy(policy FetchPolicy) {
+	js.client = NewSafeHTTPClient(policy)
+	js.policy = policy
+}
+
+// CheckURL reports whether a URL may be fetched, see CheckFetchURL
+func (js *JobScraper) CheckURL(ctx context.Context, rawURL string) error {
+	return CheckFetchURL(ctx, js.policy, rawURL)
+}
+
+// SetAdapters replaces the site adapters, which are tried in order
+func (js *JobScraper) SetAdapters(adapters ...JobSiteAdapter) {
+	js.adapters = adapters
+}
+
+// FetchJobPosting reads the posting a URL points to: from the site's API
+// when an adapter matches its host, or else from the page, see
+// ParseJobPosting
+func (js *JobScraper) FetchJobPosting(ctx context.Context, rawURL string) (*JobPosting, error) {
+	if u, err := url.Parse(rawURL); err == nil {
+		for _, adapter := range js.adapters {
+			if !adapter.Match(u) {
+				continue
+			}
+			posting, err := adapter.Fetch(ctx, js.client, u)
+			if errors.Is(err, ErrNotJobPage) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", adapter.Name(), err)
+			}
+			posting.Source = adapter.Name()
+			if posting.URL == "" {
+				posting.URL = rawURL
+			}
+			return posting, nil
+		}
+	}
+
 	// Set user agent to avoid blocking
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	req.Header.Set("User-Agent", scraperUserAgent)
 
 	resp, err := js.client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("job page returned status %d", resp.StatusCode)
 	}
 
-	// Parse HTML and extract meaningful text content
-	content, err := js.extractTextFromHTML(string(body))
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Clean and filter the content to focus on job description
-	cleanContent := js.cleanJobDescription(content)
-	
-	return cleanContent, nil
+	return js.ParseJobPosting(string(body), rawURL)
 }
 
 // extractTextFromHTML extracts text content from HTML
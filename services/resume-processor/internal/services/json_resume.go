@@ -0,0 +1,827 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// JSONResumeSchemaURL is the schema exported documents declare
+const JSONResumeSchemaURL = "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json"
+
+// maxJSONResumeErrors limits how many schema problems are reported at once
+const maxJSONResumeErrors = 50
+
+// maxJSONResumeSize is the largest resume.json accepted
+const maxJSONResumeSize = 5 << 20
+
+// jsonResumeDate is the date pattern of the schema: YYYY, YYYY-MM or YYYY-MM-DD
+var jsonResumeDate = regexp.MustCompile(`^[1-2][0-9]{3}(-[0-1][0-9](-[0-3][0-9])?)?$`)
+
+// ErrNotJSONResume is returned for JSON files that are not a JSON Resume document
+var ErrNotJSONResume = errors.New("not a JSON Resume document")
+
+// JSONResume is a resume in the jsonresume.org schema (v1.0.0)
+type JSONResume struct {
+	Schema       string                  `json:"$schema,omitempty"`
+	Basics       JSONResumeBasics        `json:"basics"`
+	Work         []JSONResumeWork        `json:"work,omitempty"`
+	Volunteer    []JSONResumeVolunteer   `json:"volunteer,omitempty"`
+	Education    []JSONResumeEducation   `json:"education,omitempty"`
+	Awards       []JSONResumeAward       `json:"awards,omitempty"`
+	Certificates []JSONResumeCertificate `json:"certificates,omitempty"`
+	Skills       []JSONResumeSkill       `json:"skills,omitempty"`
+	Languages    []JSONResumeLanguage    `json:"languages,omitempty"`
+	Projects     []JSONResumeProject     `json:"projects,omitempty"`
+	Meta         *JSONResumeMeta         `json:"meta,omitempty"`
+}
+
+// JSONResumeBasics holds the candidate's personal details
+type JSONResumeBasics struct {
+	Name     string              `json:"name,omitempty"`
+	Label    string              `json:"label,omitempty"`
+	Image    string              `json:"image,omitempty"`
+	Email    string              `json:"email,omitempty"`
+	Phone    string              `json:"phone,omitempty"`
+	URL      string              `json:"url,omitempty"`
+	Summary  string              `json:"summary,omitempty"`
+	Location *JSONResumeLocation `json:"location,omitempty"`
+	Profiles []JSONResumeProfile `json:"profiles,omitempty"`
+}
+
+// JSONResumeLocation is the candidate's address
+type JSONResumeLocation struct {
+	Address     string `json:"address,omitempty"`
+	PostalCode  string `json:"postalCode,omitempty"`
+	City        string `json:"city,omitempty"`
+	CountryCode string `json:"countryCode,omitempty"`
+	Region      string `json:"region,omitempty"`
+}
+
+// JSONResumeProfile is a social network profile
+type JSONResumeProfile struct {
+	Network  string `json:"network,omitempty"`
+	Username string `json:"username,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// JSONResumeWork is a position held
+type JSONResumeWork struct {
+	Name       string   `json:"name,omitempty"` // Company
+	Location   string   `json:"location,omitempty"`
+	Position   string   `json:"position,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	StartDate  string   `json:"startDate,omitempty"`
+	EndDate    string   `json:"endDate,omitempty"` // Empty while the position is held
+	Summary    string   `json:"summary,omitempty"`
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+// JSONResumeVolunteer is a volunteering position
+type JSONResumeVolunteer struct {
+	Organization string   `json:"organization,omitempty"`
+	Position     string   `json:"position,omitempty"`
+	URL          string   `json:"url,omitempty"`
+	StartDate    string   `json:"startDate,omitempty"`
+	EndDate      string   `json:"endDate,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+	Highlights   []string `json:"highlights,omitempty"`
+}
+
+// JSONResumeEducation is a course of study
+type JSONResumeEducation struct {
+	Institution string   `json:"institution,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Area        string   `json:"area,omitempty"`
+	StudyType   string   `json:"studyType,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	EndDate     string   `json:"endDate,omitempty"`
+	Score       string   `json:"score,omitempty"`
+	Courses     []string `json:"courses,omitempty"`
+}
+
+// JSONResumeAward is an award received
+type JSONResumeAward struct {
+	Title   string `json:"title,omitempty"`
+	Date    string `json:"date,omitempty"`
+	Awarder string `json:"awarder,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// JSONResumeCertificate is a certification held
+type JSONResumeCertificate struct {
+	Name   string `json:"name,omitempty"`
+	Date   string `json:"date,omitempty"`
+	Issuer string `json:"issuer,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// JSONResumeSkill is a group of skills
+type JSONResumeSkill struct {
+	Name     string   `json:"name,omitempty"`
+	Level    string   `json:"level,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// JSONResumeLanguage is a spoken language
+type JSONResumeLanguage struct {
+	Language string `json:"language,omitempty"`
+	Fluency  string `json:"fluency,omitempty"`
+}
+
+// JSONResumeProject is a project worked on
+type JSONResumeProject struct {
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Highlights  []string `json:"highlights,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	EndDate     string   `json:"endDate,omitempty"`
+	URL         string   `json:"url,omitempty"`
+}
+
+// JSONResumeMeta describes the document itself
+type JSONResumeMeta struct {
+	Canonical    string `json:"canonical,omitempty"`
+	Version      string `json:"version,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// JSONResumeFieldError is a schema violation at a field path such as work[0].startDate
+type JSONResumeFieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// JSONResumeValidationError lists the schema violations of a document
+type JSONResumeValidationError struct {
+	Errors []JSONResumeFieldError `json:"errors"`
+}
+
+// Error implements error
+func (e *JSONResumeValidationError) Error() string {
+	return "invalid JSON Resume: " + e.Details()
+}
+
+// Details lists the violations as "path: message", separated by semicolons
+func (e *JSONResumeValidationError) Details() string {
+	problems := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		problems = append(problems, fieldErr.Path+": "+fieldErr.Message)
+	}
+	return strings.Join(problems, "; ")
+}
+
+// jsonSchemaNode describes the expected shape of one value of the schema
+type jsonSchemaNode struct {
+	kind   string // string, object or array
+	format string // date, email or uri, for strings
+	fields map[string]*jsonSchemaNode
+	items  *jsonSchemaNode
+}
+
+func schemaString() *jsonSchemaNode { return &jsonSchemaNode{kind: "string"} }
+func schemaDate() *jsonSchemaNode   { return &jsonSchemaNode{kind: "string", format: "date"} }
+func schemaEmail() *jsonSchemaNode  { return &jsonSchemaNode{kind: "string", format: "email"} }
+func schemaURI() *jsonSchemaNode    { return &jsonSchemaNode{kind: "string", format: "uri"} }
+func schemaArray(items *jsonSchemaNode) *jsonSchemaNode {
+	return &jsonSchemaNode{kind: "array", items: items}
+}
+func schemaObject(fields map[string]*jsonSchemaNode) *jsonSchemaNode {
+	return &jsonSchemaNode{kind: "object", fields: fields}
+}
+
+// jsonResumeSchema is the part of the JSON Resume schema that is checked.
+// Unknown properties are allowed, as they are by the schema.
+var jsonResumeSchema = schemaObject(map[string]*jsonSchemaNode{
+	"$schema": schemaURI(),
+	"basics": schemaObject(map[string]*jsonSchemaNode{
+		"name": schemaString(), "label": schemaString(), "image": schemaString(),
+		"email": schemaEmail(), "phone": schemaString(), "url": schemaURI(), "summary": schemaString(),
+		"location": schemaObject(map[string]*jsonSchemaNode{
+			"address": schemaString(), "postalCode": schemaString(), "city": schemaString(),
+			"countryCode": schemaString(), "region": schemaString(),
+		}),
+		"profiles": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+			"network": schemaString(), "username": schemaString(), "url": schemaURI(),
+		})),
+	}),
+	"work": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+		"name": schemaString(), "location": schemaString(), "description": schemaString(),
+		"position": schemaString(), "url": schemaURI(), "startDate": schemaDate(), "endDate": schemaDate(),
+		"summary": schemaString(), "highlights": schemaArray(schemaString()),
+	})),
+	"volunteer": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+		"organization": schemaString(), "position": schemaString(), "url": schemaURI(),
+		"startDate": schemaDate(), "endDate": schemaDate(), "summary": schemaString(),
+		"highlights": schemaArray(schemaString()),
+	})),
+	"education": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+		"institution": schemaString(), "url": schemaURI(), "area": schemaString(), "studyType": schemaString(),
+		"startDate": schemaDate(), "endDate": schemaDate(), "score": schemaString(),
+		"courses": schemaArray(schemaString()),
+	})),
+	"awards": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+		"title": schemaString(), "date": schemaDate(), "awarder": schemaString(), "summary": schemaString(),
+	})),
+	"certificates": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+		"name": schemaString(), "date": schemaDate(), "url": schemaURI(), "issuer": schemaString(),
+	})),
+	"publications": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+		"name": schemaString(), "publisher": schemaString(), "releaseDate": schemaDate(),
+		"url": schemaURI(), "summary": schemaString(),
+	})),
+	"skills": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+		"name": schemaString(), "level": schemaString(), "keywords": schemaArray(schemaString()),
+	})),
+	"languages": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+		"language": schemaString(), "fluency": schemaString(),
+	})),
+	"interests": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+		"name": schemaString(), "keywords": schemaArray(schemaString()),
+	})),
+	"references": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+		"name": schemaString(), "reference": schemaString(),
+	})),
+	"projects": schemaArray(schemaObject(map[string]*jsonSchemaNode{
+		"name": schemaString(), "description": schemaString(), "highlights": schemaArray(schemaString()),
+		"keywords": schemaArray(schemaString()), "startDate": schemaDate(), "endDate": schemaDate(),
+		"url": schemaURI(), "roles": schemaArray(schemaString()), "entity": schemaString(), "type": schemaString(),
+	})),
+	"meta": schemaObject(map[string]*jsonSchemaNode{
+		"canonical": schemaURI(), "version": schemaString(), "lastModified": schemaString(),
+	}),
+})
+
+// jsonResumeSections are the top-level properties of which a document
+// needs at least one to be read as a resume
+var jsonResumeSections = []string{"basics", "work", "education", "skills", "projects"}
+
+// ParseJSONResume validates a JSON Resume document against the schema and
+// decodes it. Schema violations are returned as a *JSONResumeValidationError.
+func ParseJSONResume(data []byte) (*JSONResume, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, &JSONResumeValidationError{Errors: []JSONResumeFieldError{{Path: "$", Message: "invalid JSON: " + err.Error()}}}
+	}
+
+	root, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, &JSONResumeValidationError{Errors: []JSONResumeFieldError{{Path: "$", Message: "must be an object"}}}
+	}
+	hasSection := false
+	for _, section := range jsonResumeSections {
+		if _, ok := root[section]; ok {
+			hasSection = true
+			break
+		}
+	}
+	if !hasSection {
+		return nil, fmt.Errorf("%w: none of %s is present", ErrNotJSONResume, strings.Join(jsonResumeSections, ", "))
+	}
+
+	var fieldErrors []JSONResumeFieldError
+	validateJSONValue("", raw, jsonResumeSchema, &fieldErrors)
+	if len(fieldErrors) > 0 {
+		if len(fieldErrors) > maxJSONResumeErrors {
+			fieldErrors = fieldErrors[:maxJSONResumeErrors]
+		}
+		return nil, &JSONResumeValidationError{Errors: fieldErrors}
+	}
+
+	var resume JSONResume
+	if err := json.Unmarshal(data, &resume); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON Resume: %v", err)
+	}
+	return &resume, nil
+}
+
+// validateJSONValue checks a decoded value against a schema node, adding
+// violations with their field path
+func validateJSONValue(path string, value interface{}, node *jsonSchemaNode, fieldErrors *[]JSONResumeFieldError) {
+	report := func(message string) {
+		p := path
+		if p == "" {
+			p = "$"
+		}
+		*fieldErrors = append(*fieldErrors, JSONResumeFieldError{Path: p, Message: message})
+	}
+	if value == nil {
+		// Null is accepted in place of an absent value
+		return
+	}
+
+	switch node.kind {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			report("must be an object")
+			return
+		}
+		// Sorted so errors come out in a stable order
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if field, known := node.fields[key]; known {
+				childPath := key
+				if path != "" {
+					childPath = path + "." + key
+				}
+				validateJSONValue(childPath, object[key], field, fieldErrors)
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			report("must be an array")
+			return
+		}
+		for i, item := range items {
+			validateJSONValue(fmt.Sprintf("%s[%d]", path, i), item, node.items, fieldErrors)
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			report("must be a string")
+			return
+		}
+		if text == "" {
+			return
+		}
+		switch node.format {
+		case "date":
+			if !jsonResumeDate.MatchString(text) {
+				report(fmt.Sprintf("must be a date as YYYY, YYYY-MM or YYYY-MM-DD, got %q", text))
+			}
+		case "email":
+			if address, err := mail.ParseAddress(text); err != nil || address.Address != text {
+				report(fmt.Sprintf("must be an email address, got %q", text))
+			}
+		case "uri":
+			if parsed, err := url.Parse(text); err != nil || parsed.Scheme == "" {
+				report(fmt.Sprintf("must be an absolute URI, got %q", text))
+			}
+		}
+	}
+}
+
+// extractFromJSONResume reads a resume.json upload
+func (te *TextExtractor) extractFromJSONResume(ctx context.Context, filePath string) (*ExtractedDocument, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	if info.Size() > maxJSONResumeSize {
+		return nil, fmt.Errorf("JSON Resume is too large (over %d bytes)", maxJSONResumeSize)
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	resume, err := ParseJSONResume([]byte(decodeText(data)))
+	if err != nil {
+		return nil, err
+	}
+	te.trace.add("JSON Resume: %d work, %d education, %d skill entries", len(resume.Work), len(resume.Education), len(resume.Skills))
+
+	text, outline := resume.Text()
+	return &ExtractedDocument{Text: text, Outline: outline, JSONResume: resume}, nil
+}
+
+// Text renders the resume as plain text in the layout the extractors
+// produce, with the section headings as its outline
+func (r *JSONResume) Text() (string, []OutlineEntry) {
+	var lines []string
+	var outline []OutlineEntry
+	section := func(title string) {
+		lines = append(lines, "", title, "")
+		outline = append(outline, OutlineEntry{Title: title, Level: 1, Source: "json"})
+	}
+	add := func(parts ...string) {
+		if line := joinNonEmpty(parts, " | "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	bullets := func(items []string) {
+		for _, item := range items {
+			if item = strings.TrimSpace(item); item != "" {
+				lines = append(lines, "- "+item)
+			}
+		}
+	}
+
+	b := r.Basics
+	add(b.Name)
+	add(b.Label)
+	location := ""
+	if b.Location != nil {
+		location = joinNonEmpty([]string{b.Location.City, b.Location.Region}, ", ")
+	}
+	add(location, b.Email, b.Phone, b.URL)
+	profiles := make([]string, 0, len(b.Profiles))
+	for _, profile := range b.Profiles {
+		profiles = append(profiles, profile.URL)
+	}
+	add(profiles...)
+
+	if b.Summary != "" {
+		section("Summary")
+		lines = append(lines, b.Summary)
+	}
+	if len(r.Work) > 0 {
+		section("Experience")
+		for _, work := range r.Work {
+			add(joinNonEmpty([]string{work.Position, work.Name}, ", "), work.Location)
+			add(jsonResumeDateRange(work.StartDate, work.EndDate))
+			if work.Summary != "" {
+				lines = append(lines, work.Summary)
+			}
+			bullets(work.Highlights)
+		}
+	}
+	if len(r.Education) > 0 {
+		section("Education")
+		for _, education := range r.Education {
+			add(joinNonEmpty([]string{joinNonEmpty([]string{education.StudyType, education.Area}, " "), education.Institution}, ", "))
+			add(jsonResumeDateRange(education.StartDate, education.EndDate), education.Score)
+			bullets(education.Courses)
+		}
+	}
+	if len(r.Skills) > 0 {
+		section("Skills")
+		for _, skill := range r.Skills {
+			if len(skill.Keywords) > 0 {
+				add(skill.Name + ": " + strings.Join(skill.Keywords, ", "))
+			} else {
+				add(skill.Name)
+			}
+		}
+	}
+	if len(r.Projects) > 0 {
+		section("Projects")
+		for _, project := range r.Projects {
+			add(project.Name, jsonResumeDateRange(project.StartDate, project.EndDate), project.URL)
+			if project.Description != "" {
+				lines = append(lines, project.Description)
+			}
+			bullets(project.Highlights)
+		}
+	}
+	if len(r.Volunteer) > 0 {
+		section("Volunteer Experience")
+		for _, volunteer := range r.Volunteer {
+			add(joinNonEmpty([]string{volunteer.Position, volunteer.Organization}, ", "))
+			add(jsonResumeDateRange(volunteer.StartDate, volunteer.EndDate))
+			if volunteer.Summary != "" {
+				lines = append(lines, volunteer.Summary)
+			}
+			bullets(volunteer.Highlights)
+		}
+	}
+	if len(r.Certificates) > 0 {
+		section("Certifications")
+		for _, certificate := range r.Certificates {
+			add(certificate.Name, certificate.Issuer, formatJSONResumeDate(certificate.Date))
+		}
+	}
+	if len(r.Awards) > 0 {
+		section("Awards")
+		for _, award := range r.Awards {
+			add(award.Title, award.Awarder, formatJSONResumeDate(award.Date))
+			if award.Summary != "" {
+				lines = append(lines, award.Summary)
+			}
+		}
+	}
+	if len(r.Languages) > 0 {
+		section("Languages")
+		for _, language := range r.Languages {
+			add(joinNonEmpty([]string{language.Language, language.Fluency}, " - "))
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), outline
+}
+
+// ContactInfo returns the contact details of the basics section. They are
+// given by the candidate, so they have full confidence.
+func (r *JSONResume) ContactInfo() ContactInfo {
+	b := r.Basics
+	field := func(value string) *ContactField {
+		if value = strings.TrimSpace(value); value == "" {
+			return nil
+		}
+		return &ContactField{Value: value, Confidence: 1}
+	}
+
+	info := ContactInfo{Name: field(b.Name), Portfolio: field(b.URL)}
+	if email, ok := NormalizeEmail(b.Email); ok {
+		info.Emails = append(info.Emails, ContactField{Value: email, Confidence: 1})
+	}
+	if b.Phone != "" {
+		phone, _, ok := NewContactExtractor().NormalizePhone(b.Phone)
+		if !ok {
+			phone = strings.TrimSpace(b.Phone)
+		}
+		info.Phones = append(info.Phones, ContactField{Value: phone, Confidence: 1})
+	}
+	if b.Location != nil {
+		info.City = field(b.Location.City)
+		info.Region = field(b.Location.Region)
+	}
+	for _, profile := range b.Profiles {
+		if link, ok := NormalizeProfileURL(profile.URL, "linkedin.com"); ok && info.LinkedIn == nil {
+			info.LinkedIn = field(link)
+		} else if link, ok := NormalizeProfileURL(profile.URL, "github.com"); ok && info.GitHub == nil {
+			info.GitHub = field(link)
+		}
+	}
+	return info
+}
+
+// jsonResumeDateRange formats a start and end date for text, e.g.
+// "Jan 2020 – Present"
+func jsonResumeDateRange(start, end string) string {
+	if start == "" {
+		return formatJSONResumeDate(end)
+	}
+	endText := "Present"
+	if end != "" {
+		endText = formatJSONResumeDate(end)
+	}
+	return formatJSONResumeDate(start) + " – " + endText
+}
+
+// formatJSONResumeDate formats a schema date as "Jan 2020" or "2020"
+func formatJSONResumeDate(date string) string {
+	for _, layout := range []string{"2006-01-02", "2006-01"} {
+		if parsed, err := time.Parse(layout, date); err == nil {
+			return parsed.Format("Jan 2006")
+		}
+	}
+	return date
+}
+
+// joinNonEmpty joins the non-empty parts with sep
+func joinNonEmpty(parts []string, sep string) string {
+	kept := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+// jsonResumeTitleSeparators split an entry title into its role and
+// organization, in order of preference
+var jsonResumeTitleSeparators = []string{" at ", " @ ", " | ", " — ", " – ", " - ", ", "}
+
+// jsonResumeBullet matches the marker of a bullet point
+var jsonResumeBullet = regexp.MustCompile(`^[-•*◦▪‣]\s*`)
+
+// jsonResumeSectionHeaders maps lowercased section headers of every locale
+// to the JSON Resume section they fill
+var jsonResumeSectionHeaders = func() map[string]string {
+	sections := map[string]string{
+		"Summary": "summary", "Experience": "work", "Work Experience": "work",
+		"Professional Experience": "work", "Leadership": "work", "Education": "education",
+		"Skills": "skills", "Technical Skills": "skills", "Projects": "projects",
+		"Certifications": "certificates",
+	}
+	headers := map[string]string{}
+	for canonical, section := range sections {
+		headers[strings.ToLower(canonical)] = section
+		for _, profile := range localeProfiles {
+			if localized, ok := profile.SectionHeaders[canonical]; ok {
+				headers[strings.ToLower(localized)] = section
+			}
+		}
+	}
+	return headers
+}()
+
+// JSONResumeFromText builds a JSON Resume document from resume text. The
+// sections are found by their headers and the positions by their dates;
+// contact details come from contact, so user corrections are kept.
+func JSONResumeFromText(text string, contact ContactInfo) *JSONResume {
+	resume := &JSONResume{Schema: JSONResumeSchemaURL}
+	resume.Basics = jsonResumeBasics(contact)
+
+	timeline := NewTimelineAnalyzer().Analyze(text)
+	entriesByLine := map[int]TimelineEntry{}
+	for _, entry := range timeline.Entries {
+		if _, taken := entriesByLine[entry.Line]; !taken {
+			entriesByLine[entry.Line] = entry
+		}
+	}
+
+	var summary []string
+	var skillKeywords []string
+	section := ""
+	pending := "" // Line that may be the title of the next dated entry
+	var work *JSONResumeWork
+	var education *JSONResumeEducation
+	var project *JSONResumeProject
+
+	flushPending := func() {
+		if pending == "" {
+			return
+		}
+		switch {
+		case section == "work" && work != nil:
+			work.Highlights = append(work.Highlights, pending)
+		case section == "education" && education != nil:
+			education.Courses = append(education.Courses, pending)
+		case section == "projects" && project != nil:
+			project.Highlights = append(project.Highlights, pending)
+		}
+		pending = ""
+	}
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		header := strings.ToLower(strings.TrimSpace(strings.TrimSuffix(line, ":")))
+		if next, ok := jsonResumeSectionHeaders[header]; ok {
+			flushPending()
+			section, work, education, project = next, nil, nil, nil
+			continue
+		}
+		if kind, ok := timelineSection(line); ok {
+			flushPending()
+			section, work, education, project = map[string]string{TimelineSectionExperience: "work", TimelineSectionEducation: "education"}[kind], nil, nil, nil
+			continue
+		}
+
+		entry, dated := entriesByLine[i+1]
+		bullet := jsonResumeBullet.MatchString(line)
+		content := strings.TrimSpace(jsonResumeBullet.ReplaceAllString(line, ""))
+
+		switch section {
+		case "summary":
+			summary = append(summary, line)
+		case "skills":
+			if idx := strings.Index(content, ":"); idx > 0 {
+				resume.Skills = append(resume.Skills, JSONResumeSkill{Name: strings.TrimSpace(content[:idx]), Keywords: splitSkillList(content[idx+1:])})
+			} else {
+				skillKeywords = append(skillKeywords, splitSkillList(content)...)
+			}
+		case "work":
+			switch {
+			case dated:
+				title := entry.Title
+				if title == "" || title == pending {
+					title, pending = pending, ""
+				}
+				flushPending()
+				position, company := splitEntryTitle(title)
+				resume.Work = append(resume.Work, JSONResumeWork{Position: position, Name: company, StartDate: jsonResumeEntryDate(entry, false), EndDate: jsonResumeEntryDate(entry, true)})
+				work = &resume.Work[len(resume.Work)-1]
+			case bullet:
+				flushPending()
+				if work != nil {
+					work.Highlights = append(work.Highlights, content)
+				}
+			default:
+				flushPending()
+				pending = content
+			}
+		case "education":
+			switch {
+			case dated:
+				title := entry.Title
+				if title == "" || title == pending {
+					title, pending = pending, ""
+				}
+				flushPending()
+				area, institution := splitEntryTitle(title)
+				resume.Education = append(resume.Education, JSONResumeEducation{Area: area, Institution: institution, StartDate: jsonResumeEntryDate(entry, false), EndDate: jsonResumeEntryDate(entry, true)})
+				education = &resume.Education[len(resume.Education)-1]
+			case bullet:
+				flushPending()
+				if education != nil {
+					education.Courses = append(education.Courses, content)
+				}
+			default:
+				flushPending()
+				pending = content
+			}
+		case "projects":
+			if bullet && project != nil {
+				project.Highlights = append(project.Highlights, content)
+				continue
+			}
+			resume.Projects = append(resume.Projects, JSONResumeProject{Name: content})
+			project = &resume.Projects[len(resume.Projects)-1]
+		case "certificates":
+			resume.Certificates = append(resume.Certificates, JSONResumeCertificate{Name: content})
+		}
+	}
+	flushPending()
+
+	if len(skillKeywords) > 0 {
+		resume.Skills = append(resume.Skills, JSONResumeSkill{Name: "Skills", Keywords: skillKeywords})
+	}
+	resume.Basics.Summary = strings.Join(summary, " ")
+	return resume
+}
+
+// jsonResumeBasics converts contact details into the basics section
+func jsonResumeBasics(contact ContactInfo) JSONResumeBasics {
+	value := func(field *ContactField) string {
+		if field == nil {
+			return ""
+		}
+		return field.Value
+	}
+
+	basics := JSONResumeBasics{
+		Name: value(contact.Name),
+		URL:  value(contact.Portfolio),
+	}
+	if len(contact.Emails) > 0 {
+		basics.Email = contact.Emails[0].Value
+	}
+	if len(contact.Phones) > 0 {
+		basics.Phone = contact.Phones[0].Value
+	}
+	if contact.City != nil || contact.Region != nil {
+		basics.Location = &JSONResumeLocation{City: value(contact.City), Region: value(contact.Region)}
+	}
+	for _, profile := range []struct {
+		network string
+		field   *ContactField
+	}{{"LinkedIn", contact.LinkedIn}, {"GitHub", contact.GitHub}} {
+		if profile.field == nil {
+			continue
+		}
+		username := ""
+		if parsed, err := url.Parse(profile.field.Value); err == nil {
+			segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+			username = segments[len(segments)-1]
+		}
+		basics.Profiles = append(basics.Profiles, JSONResumeProfile{Network: profile.network, Username: username, URL: profile.field.Value})
+	}
+	return basics
+}
+
+// jsonResumeEntryDate returns the start or end date of a timeline entry in
+// the schema's format; ongoing entries have no end date
+func jsonResumeEntryDate(entry TimelineEntry, end bool) string {
+	date := entry.Start
+	if end {
+		if entry.Current {
+			return ""
+		}
+		date = entry.End
+	}
+	if entry.Precision == DatePrecisionYear {
+		return date[:4]
+	}
+	return date
+}
+
+// splitEntryTitle splits "Senior Engineer, Acme Corp" into its role and organization
+func splitEntryTitle(title string) (string, string) {
+	for _, sep := range jsonResumeTitleSeparators {
+		if idx := strings.Index(title, sep); idx > 0 {
+			return strings.TrimSpace(title[:idx]), strings.TrimSpace(title[idx+len(sep):])
+		}
+	}
+	return strings.TrimSpace(title), ""
+}
+
+// splitSkillList splits a comma or bullet separated list of skills. Skills
+// in the taxonomy are written by their canonical name ("k8s" → Kubernetes),
+// and a skill listed twice under different names is kept once.
+func splitSkillList(text string) []string {
+	taxonomy := DefaultSkillTaxonomy()
+	var skills []string
+	for _, skill := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ';' || r == '•' || r == '|' }) {
+		if skill = strings.TrimSpace(skill); skill == "" {
+			continue
+		}
+		if canonical, ok := taxonomy.Normalize(skill); ok {
+			skill = canonical.Name
+		}
+		if !containsString(skills, skill) {
+			skills = append(skills, skill)
+		}
+	}
+	return skills
+}
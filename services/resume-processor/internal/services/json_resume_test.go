@@ -0,0 +1,24 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONResumeFromTextNormalizesSkills(t *testing.T) {
+	text := "Alex Morgan\n" +
+		"SKILLS\n" +
+		"Languages: JS, Golang, JavaScript, Elvish\n" +
+		"k8s, Terraform\n"
+
+	resume := JSONResumeFromText(text, ContactInfo{})
+	// Known skills take their canonical names and are listed once; others
+	// are kept as written
+	want := []JSONResumeSkill{
+		{Name: "Languages", Keywords: []string{"JavaScript", "Go", "Elvish"}},
+		{Name: "Skills", Keywords: []string{"Kubernetes", "Terraform"}},
+	}
+	if !reflect.DeepEqual(resume.Skills, want) {
+		t.Errorf("skills = %+v, want %+v", resume.Skills, want)
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// languageStopwords are frequent short words of the languages with a
+// locale profile; resumes are terse, so articles and prepositions carry
+// most of the signal
+var languageStopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "for", "with", "on", "at", "by", "from", "as", "is", "an", "using", "team", "experience"},
+	"de": {"und", "der", "die", "das", "mit", "für", "von", "im", "in", "zu", "auf", "bei", "den", "des", "ein", "eine", "berufserfahrung"},
+	"fr": {"et", "de", "la", "le", "les", "des", "du", "en", "pour", "avec", "dans", "sur", "un", "une", "au", "expérience"},
+	"es": {"y", "de", "la", "el", "los", "las", "en", "para", "con", "del", "por", "un", "una", "al", "experiencia"},
+	"pt": {"e", "de", "da", "do", "dos", "das", "em", "para", "com", "no", "na", "um", "uma", "ao", "experiência"},
+	"it": {"e", "di", "il", "la", "le", "gli", "del", "della", "in", "per", "con", "un", "una", "nel", "esperienza"},
+}
+
+// languageMinWords is the fewest stopword hits needed to name a language
+const languageMinWords = 5
+
+// DetectLanguage returns the ISO 639-1 code of the main language of a
+// text, or "" when there is too little text to tell
+func DetectLanguage(text string) string {
+	// Japanese is identified by script; kana do not occur in other languages
+	kana, letters := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+			letters++
+		case unicode.IsLetter(r):
+			letters++
+		}
+	}
+	if letters > 0 && kana*10 >= letters {
+		return "ja"
+	}
+
+	counts := make(map[string]int, len(languageStopwords))
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for language, stopwords := range languageStopwords {
+			for _, stopword := range stopwords {
+				if word == stopword {
+					counts[language]++
+					break
+				}
+			}
+		}
+	}
+
+	best, bestCount := "", 0
+	for _, language := range []string{"en", "de", "fr", "es", "pt", "it"} {
+		if counts[language] > bestCount {
+			best, bestCount = language, counts[language]
+		}
+	}
+	if bestCount < languageMinWords {
+		return ""
+	}
+	return best
+}
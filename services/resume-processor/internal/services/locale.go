@@ -0,0 +1,196 @@
+package services
+
+import (
+	"sort"
+	"strings"
+)
+
+// LocaleProfile describes the resume conventions of a target locale
+type LocaleProfile struct {
+	Code               string            `json:"code"`     // BCP 47 tag, e.g. "de-DE"
+	Language           string            `json:"language"` // English name of the language
+	DocumentTerm       string            `json:"document_term"`
+	DateFormat         string            `json:"date_format"`
+	PhoneFormat        string            `json:"phone_format"`
+	IncludePhoto       bool              `json:"include_photo"`
+	IncludeDateOfBirth bool              `json:"include_date_of_birth"`
+	SectionHeaders     map[string]string `json:"section_headers"` // canonical English header -> localized header
+}
+
+// canonicalSectionHeaders are the English headers recognised by isSectionHeader
+var canonicalSectionHeaders = []string{
+	"Summary", "Technical Skills", "Experience", "Education",
+	"Certifications", "Projects", "Skills", "Work Experience",
+	"Professional Experience", "Leadership", "Activity",
+}
+
+var localeProfiles = map[string]LocaleProfile{
+	"en-US": {
+		Code:         "en-US",
+		Language:     "English",
+		DocumentTerm: "Resume",
+		DateFormat:   "MM/YYYY (e.g. 01/2020 - Present)",
+		PhoneFormat:  "+1 (555) 123-4567",
+		SectionHeaders: map[string]string{
+			"Summary": "Summary", "Technical Skills": "Technical Skills", "Experience": "Experience",
+			"Education": "Education", "Certifications": "Certifications", "Projects": "Projects",
+			"Skills": "Skills", "Work Experience": "Work Experience", "Professional Experience": "Professional Experience",
+			"Leadership": "Leadership", "Activity": "Activities",
+		},
+	},
+	"en-GB": {
+		Code:         "en-GB",
+		Language:     "British English",
+		DocumentTerm: "CV",
+		DateFormat:   "Month YYYY (e.g. January 2020 - Present)",
+		PhoneFormat:  "+44 20 7946 0958",
+		SectionHeaders: map[string]string{
+			"Summary": "Personal Profile", "Technical Skills": "Technical Skills", "Experience": "Experience",
+			"Education": "Education", "Certifications": "Certifications", "Projects": "Projects",
+			"Skills": "Skills", "Work Experience": "Employment History", "Professional Experience": "Professional Experience",
+			"Leadership": "Leadership", "Activity": "Interests and Activities",
+		},
+	},
+	"de-DE": {
+		Code:               "de-DE",
+		Language:           "German",
+		DocumentTerm:       "Lebenslauf",
+		DateFormat:         "MM/YYYY (e.g. 01/2020 - heute)",
+		PhoneFormat:        "+49 30 12345678",
+		IncludePhoto:       true,
+		IncludeDateOfBirth: true,
+		SectionHeaders: map[string]string{
+			"Summary": "Profil", "Technical Skills": "Technische Kenntnisse", "Experience": "Berufserfahrung",
+			"Education": "Ausbildung", "Certifications": "Zertifikate", "Projects": "Projekte",
+			"Skills": "Kenntnisse", "Work Experience": "Berufserfahrung", "Professional Experience": "Berufserfahrung",
+			"Leadership": "Führungserfahrung", "Activity": "Engagement",
+		},
+	},
+	"fr-FR": {
+		Code:         "fr-FR",
+		Language:     "French",
+		DocumentTerm: "CV",
+		DateFormat:   "MM/AAAA (e.g. 01/2020 - aujourd'hui)",
+		PhoneFormat:  "+33 1 23 45 67 89",
+		IncludePhoto: true,
+		SectionHeaders: map[string]string{
+			"Summary": "Profil", "Technical Skills": "Compétences techniques", "Experience": "Expérience professionnelle",
+			"Education": "Formation", "Certifications": "Certifications", "Projects": "Projets",
+			"Skills": "Compétences", "Work Experience": "Expérience professionnelle", "Professional Experience": "Expérience professionnelle",
+			"Leadership": "Leadership", "Activity": "Centres d'intérêt",
+		},
+	},
+	"es-ES": {
+		Code:         "es-ES",
+		Language:     "Spanish",
+		DocumentTerm: "Currículum Vitae",
+		DateFormat:   "MM/AAAA (e.g. 01/2020 - actualidad)",
+		PhoneFormat:  "+34 912 345 678",
+		IncludePhoto: true,
+		SectionHeaders: map[string]string{
+			"Summary": "Perfil", "Technical Skills": "Conocimientos técnicos", "Experience": "Experiencia",
+			"Education": "Formación", "Certifications": "Certificaciones", "Projects": "Proyectos",
+			"Skills": "Habilidades", "Work Experience": "Experiencia laboral", "Professional Experience": "Experiencia profesional",
+			"Leadership": "Liderazgo", "Activity": "Actividades",
+		},
+	},
+	"pt-BR": {
+		Code:         "pt-BR",
+		Language:     "Brazilian Portuguese",
+		DocumentTerm: "Currículo",
+		DateFormat:   "MM/AAAA (e.g. 01/2020 - atual)",
+		PhoneFormat:  "+55 11 91234-5678",
+		SectionHeaders: map[string]string{
+			"Summary": "Resumo", "Technical Skills": "Competências técnicas", "Experience": "Experiência",
+			"Education": "Formação acadêmica", "Certifications": "Certificações", "Projects": "Projetos",
+			"Skills": "Competências", "Work Experience": "Experiência profissional", "Professional Experience": "Experiência profissional",
+			"Leadership": "Liderança", "Activity": "Atividades",
+		},
+	},
+	"it-IT": {
+		Code:               "it-IT",
+		Language:           "Italian",
+		DocumentTerm:       "Curriculum Vitae",
+		DateFormat:         "MM/AAAA (e.g. 01/2020 - oggi)",
+		PhoneFormat:        "+39 02 1234 5678",
+		IncludePhoto:       true,
+		IncludeDateOfBirth: true,
+		SectionHeaders: map[string]string{
+			"Summary": "Profilo", "Technical Skills": "Competenze tecniche", "Experience": "Esperienza",
+			"Education": "Istruzione", "Certifications": "Certificazioni", "Projects": "Progetti",
+			"Skills": "Competenze", "Work Experience": "Esperienza lavorativa", "Professional Experience": "Esperienza professionale",
+			"Leadership": "Leadership", "Activity": "Attività",
+		},
+	},
+	"ja-JP": {
+		Code:               "ja-JP",
+		Language:           "Japanese",
+		DocumentTerm:       "履歴書",
+		DateFormat:         "YYYY年MM月 (e.g. 2020年01月 - 現在)",
+		PhoneFormat:        "090-1234-5678",
+		IncludePhoto:       true,
+		IncludeDateOfBirth: true,
+		SectionHeaders: map[string]string{
+			"Summary": "自己PR", "Technical Skills": "技術スキル", "Experience": "職歴",
+			"Education": "学歴", "Certifications": "資格", "Projects": "プロジェクト",
+			"Skills": "スキル", "Work Experience": "職歴", "Professional Experience": "職務経歴",
+			"Leadership": "リーダーシップ", "Activity": "活動",
+		},
+	},
+}
+
+// GetLocaleProfile looks up a locale profile by tag, falling back to the
+// first profile that shares the language subtag (e.g. "de" -> "de-DE")
+func GetLocaleProfile(code string) (LocaleProfile, bool) {
+	if profile, ok := localeProfiles[code]; ok {
+		return profile, true
+	}
+
+	language := strings.ToLower(strings.SplitN(strings.ReplaceAll(code, "_", "-"), "-", 2)[0])
+	for _, key := range SupportedLocales() {
+		if strings.ToLower(strings.SplitN(key, "-", 2)[0]) == language {
+			return localeProfiles[key], true
+		}
+	}
+	return LocaleProfile{}, false
+}
+
+// SupportedLocales returns the tags of all known locale profiles in sorted order
+func SupportedLocales() []string {
+	codes := make([]string, 0, len(localeProfiles))
+	for code := range localeProfiles {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// localizedHeaders returns the lower-cased section headers of every locale profile
+func localizedHeaders() map[string]bool {
+	headers := make(map[string]bool)
+	for _, profile := range localeProfiles {
+		for _, localized := range profile.SectionHeaders {
+			headers[strings.ToLower(localized)] = true
+		}
+	}
+	return headers
+}
+
+// localizeSectionHeaders replaces lines that consist solely of a canonical
+// English header with the locale's header, so that translated output keeps
+// headers the extractor can recognise even if the model left them untranslated
+func localizeSectionHeaders(text string, profile LocaleProfile) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), ":"))
+		for _, canonical := range canonicalSectionHeaders {
+			if strings.EqualFold(trimmed, canonical) {
+				if localized, ok := profile.SectionHeaders[canonical]; ok {
+					lines[i] = localized
+				}
+				break
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
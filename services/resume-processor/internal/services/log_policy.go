@@ -0,0 +1,115 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+)
+
+// Resume content logging policies, selected with LOG_CONTENT_POLICY
+const (
+	ContentPolicyNone     = "none"     // Only the length and a fingerprint of resume content
+	ContentPolicyRedacted = "redacted" // Also a short excerpt with personal details replaced
+)
+
+// contentExcerptLength is the longest excerpt logged under ContentPolicyRedacted
+const contentExcerptLength = 200
+
+// traceFileName matches request IDs that are safe to use as file names
+var traceFileName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ContentLogPolicy returns the configured policy for logging resume content;
+// anything but "redacted" logs no content at all
+func ContentLogPolicy() string {
+	if os.Getenv("LOG_CONTENT_POLICY") == ContentPolicyRedacted {
+		return ContentPolicyRedacted
+	}
+	return ContentPolicyNone
+}
+
+// contentSummary logs resume content under the configured policy
+type contentSummary string
+
+// LogContent describes resume content for a log event without writing the
+// content itself, e.g. logger.Info().Object("text", LogContent(text))
+func LogContent(text string) zerolog.LogObjectMarshaler {
+	return contentSummary(text)
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler
+func (s contentSummary) MarshalZerologObject(e *zerolog.Event) {
+	text := string(s)
+	sum := sha256.Sum256([]byte(text))
+	e.Int("chars", utf8.RuneCountInString(text)).
+		Int("words", len(strings.Fields(text))).
+		Str("sha256", hex.EncodeToString(sum[:6]))
+
+	if ContentLogPolicy() == ContentPolicyRedacted {
+		redacted, _ := NewPIIRedactor().Redact(text)
+		if runes := []rune(redacted); len(runes) > contentExcerptLength {
+			redacted = string(runes[:contentExcerptLength]) + "…"
+		}
+		e.Str("excerpt", redacted)
+	}
+}
+
+// ExtractionTrace records every decision of one extraction, including the
+// text segments kept and dropped. It holds resume content, so it is only
+// kept when EXTRACTION_DEBUG is set and is never written to the log.
+type ExtractionTrace struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// extractionDebugEnabled reports whether EXTRACTION_DEBUG is set
+func extractionDebugEnabled() bool {
+	switch strings.ToLower(os.Getenv("EXTRACTION_DEBUG")) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// add appends a line to the trace; a nil trace records nothing
+func (t *ExtractionTrace) add(format string, args ...interface{}) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lines = append(t.lines, fmt.Sprintf(format, args...))
+}
+
+// WriteFile saves the trace as <name>.log in dir, readable only by the
+// service user. Names that are not plain identifiers are rejected so a
+// client-supplied request ID cannot choose the path.
+func (t *ExtractionTrace) WriteFile(dir, name string) (string, error) {
+	if !traceFileName.MatchString(name) {
+		return "", fmt.Errorf("invalid trace name %q", name)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create trace dir: %v", err)
+	}
+
+	path := filepath.Join(dir, name+".log")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create trace file: %v", err)
+	}
+	defer f.Close()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, err := f.WriteString(strings.Join(t.lines, "\n") + "\n"); err != nil {
+		return "", fmt.Errorf("failed to write trace file: %v", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Markdown syntax patterns, applied line by line
+var (
+	mdATXHeading     = regexp.MustCompile(`^ {0,3}#{1,6}\s+(.*?)(?:\s+#+)?\s*$`)
+	mdSetextRule     = regexp.MustCompile(`^ {0,3}(=+|-+)\s*$`)
+	mdThematicBreak  = regexp.MustCompile(`^ {0,3}((\*\s*){3,}|(-\s*){3,}|(_\s*){3,})$`)
+	mdBulletItem     = regexp.MustCompile(`^(\s*)[-*+]\s+(?:\[[ xX]\]\s+)?(.*)$`)
+	mdOrderedItem    = regexp.MustCompile(`^(\s*)(\d{1,9})[.)]\s+(.*)$`)
+	mdBlockquote     = regexp.MustCompile(`^ {0,3}>\s?`)
+	mdFence          = regexp.MustCompile("^ {0,3}(```|~~~)")
+	mdTableDelimiter = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdImage          = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink           = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	mdAutolink       = regexp.MustCompile(`<((?:https?://|mailto:)[^>\s]+|[^<>\s@]+@[^<>\s]+)>`)
+	mdHTMLTag        = regexp.MustCompile(`</?[A-Za-z][^>]*>`)
+	mdStrong         = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	mdEmphasis       = regexp.MustCompile(`(^|[^\w*])[*_](\S(?:[^*_]*?\S)?)[*_]([^\w*]|$)`)
+	mdStrikethrough  = regexp.MustCompile(`~~(.+?)~~`)
+	mdInlineCode     = regexp.MustCompile("`+([^`]+)`+")
+	mdEscape         = regexp.MustCompile(`\\([\\` + "`" + `*_{}\[\]()#+\-.!|>~])`)
+)
+
+// extractFromMarkdown extracts text from a Markdown resume, turning headings,
+// lists, tables and links into plain text and dropping the markup
+func (te *TextExtractor) extractFromMarkdown(ctx context.Context, filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Markdown file: %v", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return te.finalCleanup(renderMarkdown(decodeText(content))), nil
+}
+
+// renderMarkdown converts Markdown source to structured plain text
+func renderMarkdown(source string) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	lines = skipFrontMatter(lines)
+
+	var out []string
+	heading := func(text string) {
+		out = append(out, "", mdInline(text), "")
+	}
+
+	inFence := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if mdFence.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			out = append(out, line)
+			continue
+		}
+
+		line = mdBlockquote.ReplaceAllString(line, "")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			out = append(out, "")
+		case mdATXHeading.MatchString(line):
+			heading(mdATXHeading.FindStringSubmatch(line)[1])
+		case i+1 < len(lines) && mdSetextRule.MatchString(lines[i+1]) && !mdBulletItem.MatchString(line):
+			// "Title\n=====" and "Title\n-----" headings
+			heading(trimmed)
+			i++
+		case mdThematicBreak.MatchString(line):
+			out = append(out, "")
+		case i+1 < len(lines) && strings.Contains(line, "|") && mdTableDelimiter.MatchString(lines[i+1]):
+			out = append(out, mdTableRow(line))
+			i++ // Skip the delimiter row
+			for i+1 < len(lines) && strings.Contains(lines[i+1], "|") && strings.TrimSpace(lines[i+1]) != "" {
+				i++
+				out = append(out, mdTableRow(lines[i]))
+			}
+		case mdBulletItem.MatchString(line):
+			match := mdBulletItem.FindStringSubmatch(line)
+			out = append(out, match[1]+"• "+mdInline(match[2]))
+		case mdOrderedItem.MatchString(line):
+			match := mdOrderedItem.FindStringSubmatch(line)
+			out = append(out, match[1]+match[2]+". "+mdInline(match[3]))
+		default:
+			out = append(out, mdInline(trimmed))
+		}
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// skipFrontMatter drops a leading YAML front matter block used by site generators
+func skipFrontMatter(lines []string) []string {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return lines
+	}
+	for i := 1; i < len(lines); i++ {
+		if trimmed := strings.TrimSpace(lines[i]); trimmed == "---" || trimmed == "..." {
+			return lines[i+1:]
+		}
+	}
+	return lines
+}
+
+// mdTableRow renders a pipe table row as cells separated by " | "
+func mdTableRow(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+
+	var cells []string
+	for _, cell := range strings.Split(line, "|") {
+		cells = append(cells, mdInline(cell))
+	}
+	return joinTableRow(cells)
+}
+
+// mdInline strips inline markup, keeping link targets that the text does not show
+func mdInline(text string) string {
+	text = mdInlineCode.ReplaceAllString(text, "$1")
+	text = mdImage.ReplaceAllString(text, "$1")
+	text = mdLink.ReplaceAllStringFunc(text, func(match string) string {
+		parts := mdLink.FindStringSubmatch(match)
+		label, target := parts[1], strings.TrimPrefix(parts[2], "mailto:")
+		if strings.HasPrefix(target, "#") || strings.Contains(label, strings.TrimPrefix(strings.TrimPrefix(target, "https://"), "http://")) {
+			return label
+		}
+		return label + " (" + target + ")"
+	})
+	text = mdAutolink.ReplaceAllStringFunc(text, func(match string) string {
+		return strings.TrimPrefix(mdAutolink.FindStringSubmatch(match)[1], "mailto:")
+	})
+	text = mdHTMLTag.ReplaceAllString(text, "")
+	text = mdStrong.ReplaceAllString(text, "$2")
+	text = mdEmphasis.ReplaceAllString(text, "$1$2$3")
+	text = mdStrikethrough.ReplaceAllString(text, "$1")
+	text = mdEscape.ReplaceAllString(text, "$1")
+	return strings.TrimSpace(text)
+}
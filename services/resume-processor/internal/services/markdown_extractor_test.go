@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractFromMarkdownFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    string
+	}{
+		{
+			// Front matter is dropped; -, * and + items and task items become
+			// bullets, ordered items keep their numbers, and inline markup
+			// is stripped
+			fixture: "lists.md",
+			want: "Jordan Lee\n" +
+				"\n" +
+				"Platform engineer with ten years of experience.\n" +
+				"\n" +
+				"Experience\n" +
+				"\n" +
+				"• Cut deploy time by 40%\n" +
+				"• Owned the on-call (https://example.com/oncall) rotation\n" +
+				"• Wrote the runbooks\n" +
+				"• Migrated to Kubernetes\n" +
+				"\n" +
+				"1. Design\n" +
+				"2. Build",
+		},
+		{
+			// Fenced code is kept as written, without reading headings,
+			// bullets or emphasis in it; blockquotes and pipe tables are
+			// rendered as text
+			fixture: "code.md",
+			want: "Skills\n" +
+				"\n" +
+				"func main() {\n" +
+				"# not a heading\n" +
+				"- not a bullet\n" +
+				"}\n" +
+				"\n" +
+				"**kept as written**\n" +
+				"\n" +
+				"Quoted testimonial\n" +
+				"\n" +
+				"Language | Years\n" +
+				"Go | 8\n" +
+				"Rust | 2",
+		},
+	}
+
+	te := NewTextExtractor()
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := te.extractFromMarkdown(context.Background(), filepath.Join("testdata", "md", tt.fixture))
+			if err != nil {
+				t.Fatalf("extractFromMarkdown: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("extracted text\n got: %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
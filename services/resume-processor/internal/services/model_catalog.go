@@ -0,0 +1,168 @@
+package services
+
+import (
+	"os"
+	"strings"
+)
+
+// AI providers, matching UserAPIKey.Provider
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderLocal     = "local" // Self-hosted OpenAI-compatible server; needs no user key
+)
+
+// ModelCapabilities lists optional features a model supports
+type ModelCapabilities struct {
+	Streaming        bool `json:"streaming"`
+	StructuredOutput bool `json:"structured_output"` // Native JSON schema constrained output
+	JSONMode         bool `json:"json_mode"`
+	Vision           bool `json:"vision"`
+}
+
+// ModelPricing holds list prices in USD per million tokens. They are hints
+// for the UI only; the provider bills the user's own key.
+type ModelPricing struct {
+	InputPerMTok  float64 `json:"input_per_mtok"`
+	OutputPerMTok float64 `json:"output_per_mtok"`
+}
+
+// ModelInfo describes a model the optimizer can call
+type ModelInfo struct {
+	ID            string            `json:"id"` // Identifier sent to the provider API
+	Name          string            `json:"name"`
+	Provider      string            `json:"provider"`
+	Aliases       []string          `json:"aliases,omitempty"` // Other names accepted in requests
+	ContextWindow int               `json:"context_window"`
+	Pricing       ModelPricing      `json:"pricing"`
+	Capabilities  ModelCapabilities `json:"capabilities"`
+}
+
+// providerNames are the display names of the supported providers
+var providerNames = map[string]string{
+	ProviderOpenAI:    "OpenAI",
+	ProviderAnthropic: "Anthropic",
+	ProviderLocal:     "Local model",
+}
+
+// modelCatalog lists every supported model, grouped by provider in display order
+var modelCatalog = []ModelInfo{
+	{
+		ID: "gpt-4o", Name: "GPT-4o", Provider: ProviderOpenAI,
+		ContextWindow: 128000,
+		Pricing:       ModelPricing{InputPerMTok: 2.50, OutputPerMTok: 10.00},
+		Capabilities:  ModelCapabilities{Streaming: true, StructuredOutput: true, JSONMode: true, Vision: true},
+	},
+	{
+		ID: "gpt-4o-mini", Name: "GPT-4o mini", Provider: ProviderOpenAI,
+		ContextWindow: 128000,
+		Pricing:       ModelPricing{InputPerMTok: 0.15, OutputPerMTok: 0.60},
+		Capabilities:  ModelCapabilities{Streaming: true, StructuredOutput: true, JSONMode: true, Vision: true},
+	},
+	{
+		ID: "gpt-4-turbo", Name: "GPT-4 Turbo", Provider: ProviderOpenAI,
+		ContextWindow: 128000,
+		Pricing:       ModelPricing{InputPerMTok: 10.00, OutputPerMTok: 30.00},
+		Capabilities:  ModelCapabilities{Streaming: true, JSONMode: true, Vision: true},
+	},
+	{
+		ID: "gpt-4", Name: "GPT-4", Provider: ProviderOpenAI,
+		ContextWindow: 8192,
+		Pricing:       ModelPricing{InputPerMTok: 30.00, OutputPerMTok: 60.00},
+		Capabilities:  ModelCapabilities{Streaming: true},
+	},
+	{
+		ID: "gpt-3.5-turbo", Name: "GPT-3.5 Turbo", Provider: ProviderOpenAI,
+		ContextWindow: 16385,
+		Pricing:       ModelPricing{InputPerMTok: 0.50, OutputPerMTok: 1.50},
+		Capabilities:  ModelCapabilities{Streaming: true, JSONMode: true},
+	},
+	{
+		ID: "claude-3-5-sonnet-20241022", Name: "Claude 3.5 Sonnet", Provider: ProviderAnthropic,
+		Aliases:       []string{"claude-3-5-sonnet", "claude-3.5-sonnet", "claude-3-5-sonnet-latest"},
+		ContextWindow: 200000,
+		Pricing:       ModelPricing{InputPerMTok: 3.00, OutputPerMTok: 15.00},
+		Capabilities:  ModelCapabilities{Streaming: true, Vision: true},
+	},
+	{
+		ID: "claude-3-5-haiku-20241022", Name: "Claude 3.5 Haiku", Provider: ProviderAnthropic,
+		Aliases:       []string{"claude-3-5-haiku", "claude-3.5-haiku", "claude-3-5-haiku-latest"},
+		ContextWindow: 200000,
+		Pricing:       ModelPricing{InputPerMTok: 0.80, OutputPerMTok: 4.00},
+		Capabilities:  ModelCapabilities{Streaming: true},
+	},
+	{
+		ID: "claude-3-opus-20240229", Name: "Claude 3 Opus", Provider: ProviderAnthropic,
+		Aliases:       []string{"claude-3-opus", "claude-3-opus-latest"},
+		ContextWindow: 200000,
+		Pricing:       ModelPricing{InputPerMTok: 15.00, OutputPerMTok: 75.00},
+		Capabilities:  ModelCapabilities{Streaming: true, Vision: true},
+	},
+	{
+		ID: "claude-3-sonnet-20240229", Name: "Claude 3 Sonnet", Provider: ProviderAnthropic,
+		Aliases:       []string{"claude-3-sonnet"},
+		ContextWindow: 200000,
+		Pricing:       ModelPricing{InputPerMTok: 3.00, OutputPerMTok: 15.00},
+		Capabilities:  ModelCapabilities{Streaming: true, Vision: true},
+	},
+	{
+		ID: "claude-3-haiku-20240307", Name: "Claude 3 Haiku", Provider: ProviderAnthropic,
+		Aliases:       []string{"claude-3-haiku"},
+		ContextWindow: 200000,
+		Pricing:       ModelPricing{InputPerMTok: 0.25, OutputPerMTok: 1.25},
+		Capabilities:  ModelCapabilities{Streaming: true, Vision: true},
+	},
+	{
+		ID: "llama3.1", Name: "Llama 3.1 (self-hosted)", Provider: ProviderLocal,
+		Aliases:       []string{"local", "local-model"},
+		ContextWindow: 128000,
+		Capabilities:  ModelCapabilities{Streaming: true, JSONMode: true},
+	},
+}
+
+// ModelCatalog returns every supported model. Local models are only listed
+// when LOCAL_MODEL_URL is configured.
+func ModelCatalog() []ModelInfo {
+	catalog := make([]ModelInfo, 0, len(modelCatalog))
+	for _, model := range modelCatalog {
+		if model.Provider == ProviderLocal && !localModelsEnabled() {
+			continue
+		}
+		catalog = append(catalog, model)
+	}
+	return catalog
+}
+
+// ResolveModel looks up a model by ID or alias, ignoring case
+func ResolveModel(name string) (ModelInfo, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, model := range ModelCatalog() {
+		if model.ID == name {
+			return model, true
+		}
+		for _, alias := range model.Aliases {
+			if alias == name {
+				return model, true
+			}
+		}
+	}
+	return ModelInfo{}, false
+}
+
+// ProviderRequiresAPIKey reports whether calling the provider needs a stored user API key
+func ProviderRequiresAPIKey(provider string) bool {
+	return provider != ProviderLocal
+}
+
+// localModelsEnabled reports whether a self-hosted model endpoint is configured
+func localModelsEnabled() bool {
+	return os.Getenv("LOCAL_MODEL_URL") != ""
+}
+
+// ProviderName returns the display name of a provider
+func ProviderName(provider string) string {
+	if name, ok := providerNames[provider]; ok {
+		return name
+	}
+	return provider
+}
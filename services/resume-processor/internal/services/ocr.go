@@ -0,0 +1,258 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// OCR defaults, overridable with OCR_CONCURRENCY and OCR_PAGE_TIMEOUT
+const (
+	defaultOCRConcurrency = 2
+	maxOCRConcurrency     = 16
+	defaultOCRPageTimeout = 60 * time.Second
+	ocrRasterDPI          = 300
+)
+
+// OCREngine renders a PDF to page images and recognizes the text of each page
+type OCREngine interface {
+	// RasterizePages writes one image per page into dir and returns their
+	// paths in page order
+	RasterizePages(ctx context.Context, pdfPath, dir string) ([]string, error)
+	// RecognizePage returns the text of one page image and a confidence
+	// between 0 and 100
+	RecognizePage(ctx context.Context, imagePath string) (OCRPageResult, error)
+}
+
+// OCRPageResult is the recognized text of one page
+type OCRPageResult struct {
+	Text       string
+	Confidence float64
+}
+
+// OCRPage is the outcome of OCR for one page of a document
+type OCRPage struct {
+	Page       int     `json:"page"`
+	Confidence float64 `json:"confidence"`
+	Characters int     `json:"characters"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// OCROutput is the text recognized across all pages
+type OCROutput struct {
+	Text  string
+	Pages []OCRPage
+}
+
+// runOCR rasterizes a PDF into a private temporary directory and recognizes
+// its pages in parallel, each under its own timeout. Pages that fail are
+// reported with their error and left out of the text.
+func (te *TextExtractor) runOCR(ctx context.Context, pdfPath string) (*OCROutput, error) {
+	if te.ocr == nil {
+		return nil, fmt.Errorf("no OCR engine configured")
+	}
+
+	tempDir, err := os.MkdirTemp("", "resume-ocr-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	images, err := te.ocr.RasterizePages(ctx, pdfPath, tempDir)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to convert PDF to images: %v", err)
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no image files generated from PDF")
+	}
+
+	texts := make([]string, len(images))
+	pages := make([]OCRPage, len(images))
+	sem := make(chan struct{}, te.ocrConcurrency)
+	var wg sync.WaitGroup
+
+	for i, image := range images {
+		wg.Add(1)
+		go func(i int, image string) {
+			defer wg.Done()
+			pages[i].Page = i + 1
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				pages[i].Error = ctx.Err().Error()
+				return
+			}
+
+			pageCtx, cancel := context.WithTimeout(ctx, te.ocrPageTimeout)
+			defer cancel()
+
+			result, err := te.ocr.RecognizePage(pageCtx, image)
+			switch {
+			case err != nil && errors.Is(pageCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil:
+				pages[i].Error = fmt.Sprintf("timed out after %s", te.ocrPageTimeout)
+			case err != nil:
+				pages[i].Error = err.Error()
+			default:
+				texts[i] = strings.TrimSpace(result.Text)
+				pages[i].Confidence = result.Confidence
+				pages[i].Characters = len(texts[i])
+			}
+		}(i, image)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var allText strings.Builder
+	recognized := 0
+	for i, text := range texts {
+		if pages[i].Error != "" {
+			zerolog.Ctx(ctx).Warn().Int("page", pages[i].Page).Str("error", pages[i].Error).Msg("OCR failed for page")
+			continue
+		}
+		recognized++
+		if text != "" {
+			allText.WriteString(text)
+			allText.WriteString("\n\n")
+		}
+	}
+	if recognized == 0 {
+		return nil, fmt.Errorf("OCR failed on all %d pages: %s", len(pages), pages[0].Error)
+	}
+
+	return &OCROutput{Text: allText.String(), Pages: pages}, nil
+}
+
+// ocrConcurrency reads OCR_CONCURRENCY
+func ocrConcurrency() int {
+	n, err := strconv.Atoi(os.Getenv("OCR_CONCURRENCY"))
+	if err != nil || n < 1 {
+		return defaultOCRConcurrency
+	}
+	return min(n, maxOCRConcurrency)
+}
+
+// ocrPageTimeout reads OCR_PAGE_TIMEOUT, in seconds
+func ocrPageTimeout() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("OCR_PAGE_TIMEOUT"))
+	if err != nil || seconds < 1 {
+		return defaultOCRPageTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// TesseractEngine runs OCR with the pdftoppm (or ImageMagick) and tesseract
+// command line tools
+type TesseractEngine struct {
+	Language string // Tesseract language code, "eng" when empty
+}
+
+// RasterizePages renders each page at 300 DPI with pdftoppm, falling back to
+// ImageMagick convert
+func (e *TesseractEngine) RasterizePages(ctx context.Context, pdfPath, dir string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "pdftoppm", "-r", strconv.Itoa(ocrRasterDPI), "-png", pdfPath, filepath.Join(dir, "page"))
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		zerolog.Ctx(ctx).Debug().Err(err).Msg("pdftoppm failed, trying ImageMagick convert")
+		cmd = exec.CommandContext(ctx, "convert", "-density", strconv.Itoa(ocrRasterDPI), pdfPath, filepath.Join(dir, "page-%04d.png"))
+		if err := cmd.Run(); err != nil {
+			return nil, err
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "page-*.png"))
+	if err != nil {
+		return nil, err
+	}
+	// Both tools zero-pad page numbers per document, so names sort by page
+	sort.Strings(files)
+	return files, nil
+}
+
+// RecognizePage runs tesseract with TSV output and rebuilds the page text
+// from its words; the confidence is the mean of the word confidences
+func (e *TesseractEngine) RecognizePage(ctx context.Context, imagePath string) (OCRPageResult, error) {
+	language := e.Language
+	if language == "" {
+		language = "eng"
+	}
+
+	cmd := exec.CommandContext(ctx, "tesseract", imagePath, "stdout", "-l", language, "-c", "preserve_interword_spaces=1", "tsv")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return OCRPageResult{}, ctx.Err()
+		}
+		return OCRPageResult{}, fmt.Errorf("tesseract failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseTesseractTSV(output), nil
+}
+
+// parseTesseractTSV reads the word rows of tesseract TSV output
+func parseTesseractTSV(output []byte) OCRPageResult {
+	var text strings.Builder
+	var confidenceSum float64
+	words := 0
+	lastLine, lastParagraph := "", ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		// level page block par line word left top width height conf text
+		fields := strings.SplitN(scanner.Text(), "\t", 12)
+		if len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+		word := strings.TrimSpace(fields[11])
+		confidence, err := strconv.ParseFloat(fields[10], 64)
+		if word == "" || err != nil || confidence < 0 {
+			continue
+		}
+
+		paragraph := fields[2] + "." + fields[3]
+		line := paragraph + "." + fields[4]
+		switch {
+		case lastLine == "":
+		case paragraph != lastParagraph:
+			text.WriteString("\n\n")
+		case line != lastLine:
+			text.WriteString("\n")
+		default:
+			text.WriteString(" ")
+		}
+		lastLine, lastParagraph = line, paragraph
+
+		text.WriteString(word)
+		confidenceSum += confidence
+		words++
+	}
+
+	result := OCRPageResult{Text: text.String()}
+	if words > 0 {
+		result.Confidence = confidenceSum / float64(words)
+	}
+	return result
+}
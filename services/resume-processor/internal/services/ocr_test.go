@@ -0,0 +1,252 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOCREngine returns canned pages without external tools
+type fakeOCREngine struct {
+	Pages  []OCRPageResult
+	Errs   map[int]error         // Errors by 1-based page number
+	Delays map[int]time.Duration // Time spent recognizing a page, by 1-based page number
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+// RasterizePages returns one placeholder path per canned page
+func (e *fakeOCREngine) RasterizePages(ctx context.Context, pdfPath, dir string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	images := make([]string, len(e.Pages))
+	for i := range e.Pages {
+		images[i] = filepath.Join(dir, fmt.Sprintf("page-%04d.png", i+1))
+	}
+	return images, nil
+}
+
+// RecognizePage returns the canned result for the page in imagePath
+func (e *fakeOCREngine) RecognizePage(ctx context.Context, imagePath string) (OCRPageResult, error) {
+	var page int
+	if _, err := fmt.Sscanf(filepath.Base(imagePath), "page-%04d.png", &page); err != nil || page < 1 || page > len(e.Pages) {
+		return OCRPageResult{}, fmt.Errorf("unknown page image %s", imagePath)
+	}
+
+	e.mu.Lock()
+	e.inFlight++
+	e.maxInFlight = max(e.maxInFlight, e.inFlight)
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		e.inFlight--
+		e.mu.Unlock()
+	}()
+
+	if delay := e.Delays[page]; delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return OCRPageResult{}, ctx.Err()
+		}
+	}
+	if err := e.Errs[page]; err != nil {
+		return OCRPageResult{}, err
+	}
+	return e.Pages[page-1], nil
+}
+
+// newOCRTestExtractor returns an extractor that OCRs with the engine
+func newOCRTestExtractor(engine OCREngine, concurrency int, pageTimeout time.Duration) *TextExtractor {
+	te := NewTextExtractor().WithOCREngine(engine)
+	te.ocrConcurrency = concurrency
+	te.ocrPageTimeout = pageTimeout
+	return te
+}
+
+func TestRunOCRKeepsPageOrder(t *testing.T) {
+	engine := &fakeOCREngine{
+		Pages: []OCRPageResult{
+			{Text: "page one", Confidence: 90},
+			{Text: "page two", Confidence: 80},
+			{Text: "page three", Confidence: 70},
+			{Text: "page four", Confidence: 60},
+		},
+		// Earlier pages take longer, so they finish last
+		Delays: map[int]time.Duration{1: 80 * time.Millisecond, 2: 60 * time.Millisecond, 3: 40 * time.Millisecond, 4: 20 * time.Millisecond},
+	}
+	te := newOCRTestExtractor(engine, 2, time.Second)
+
+	out, err := te.runOCR(context.Background(), "resume.pdf")
+	if err != nil {
+		t.Fatalf("runOCR: %v", err)
+	}
+
+	if want := "page one\n\npage two\n\npage three\n\npage four\n\n"; out.Text != want {
+		t.Errorf("text = %q, want %q", out.Text, want)
+	}
+	for i, page := range out.Pages {
+		if page.Page != i+1 || page.Confidence != engine.Pages[i].Confidence || page.Characters != len(engine.Pages[i].Text) {
+			t.Errorf("page %d = %+v", i+1, page)
+		}
+	}
+	if engine.maxInFlight != 2 {
+		t.Errorf("%d pages recognized at once, want 2", engine.maxInFlight)
+	}
+}
+
+func TestRunOCRPageTimeout(t *testing.T) {
+	engine := &fakeOCREngine{
+		Pages: []OCRPageResult{
+			{Text: "page one", Confidence: 90},
+			{Text: "page two", Confidence: 90},
+			{Text: "page three", Confidence: 90},
+		},
+		Delays: map[int]time.Duration{2: 5 * time.Second},
+	}
+	te := newOCRTestExtractor(engine, 3, 50*time.Millisecond)
+
+	start := time.Now()
+	out, err := te.runOCR(context.Background(), "resume.pdf")
+	if err != nil {
+		t.Fatalf("runOCR: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("runOCR took %s; the slow page was not cut off", elapsed)
+	}
+
+	if out.Text != "page one\n\npage three\n\n" {
+		t.Errorf("text = %q", out.Text)
+	}
+	if got := out.Pages[1].Error; got != "timed out after 50ms" {
+		t.Errorf("page 2 error = %q", got)
+	}
+	if out.Pages[0].Error != "" || out.Pages[2].Error != "" {
+		t.Errorf("pages 1 and 3 failed: %+v", out.Pages)
+	}
+}
+
+func TestRunOCRFailedPage(t *testing.T) {
+	engine := &fakeOCREngine{
+		Pages: []OCRPageResult{
+			{Text: "page one", Confidence: 90},
+			{Text: "page two", Confidence: 90},
+			{Text: "page three", Confidence: 90},
+		},
+		Errs: map[int]error{2: errors.New("image too blurry")},
+	}
+	te := newOCRTestExtractor(engine, 2, time.Second)
+
+	out, err := te.runOCR(context.Background(), "resume.pdf")
+	if err != nil {
+		t.Fatalf("runOCR: %v", err)
+	}
+	if out.Text != "page one\n\npage three\n\n" {
+		t.Errorf("text = %q", out.Text)
+	}
+	if page := out.Pages[1]; page.Error != "image too blurry" || page.Confidence != 0 || page.Characters != 0 {
+		t.Errorf("page 2 = %+v", page)
+	}
+}
+
+func TestRunOCRAllPagesFailed(t *testing.T) {
+	engine := &fakeOCREngine{
+		Pages: []OCRPageResult{{Text: "page one"}, {Text: "page two"}},
+		Errs:  map[int]error{1: errors.New("unreadable"), 2: errors.New("unreadable")},
+	}
+	te := newOCRTestExtractor(engine, 2, time.Second)
+
+	if _, err := te.runOCR(context.Background(), "resume.pdf"); err == nil || !strings.Contains(err.Error(), "OCR failed on all 2 pages") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestExtractDocumentFallsBackToOCR(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.pdf")
+	if err := os.WriteFile(path, blankPDF(3), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := &fakeOCREngine{
+		Pages: []OCRPageResult{
+			{Text: "Jordan Lee\nPlatform engineer with ten years of experience", Confidence: 91},
+			{Text: "Experience\nAcme Corp, Staff Engineer, 2019 to 2024", Confidence: 45},
+			{Text: "Education\nB.Sc. Computer Science"},
+		},
+		Errs: map[int]error{3: errors.New("image too blurry")},
+	}
+	te := newOCRTestExtractor(engine, 2, time.Second)
+
+	doc, err := te.ExtractDocument(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ExtractDocument: %v", err)
+	}
+
+	if len(doc.OCRPages) != 3 || doc.OCRPages[0].Confidence != 91 || doc.OCRPages[1].Confidence != 45 || doc.OCRPages[2].Error != "image too blurry" {
+		t.Errorf("OCR pages = %+v", doc.OCRPages)
+	}
+	if doc.Report.Method != ExtractionMethodOCR {
+		t.Errorf("method = %q, want %q", doc.Report.Method, ExtractionMethodOCR)
+	}
+	// The mean covers the recognized pages only
+	if doc.Report.OCRConfidence == nil || *doc.Report.OCRConfidence != 68 {
+		t.Errorf("confidence = %v, want 68", doc.Report.OCRConfidence)
+	}
+	warnings := strings.Join(doc.Report.Warnings, "\n")
+	for _, want := range []string{"low OCR confidence on page 2 (45%)", "OCR failed on page 3: image too blurry"} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("warnings %q lack %q", doc.Report.Warnings, want)
+		}
+	}
+	if !strings.Contains(doc.Text, "Jordan Lee") || !strings.Contains(doc.Text, "Acme Corp") || strings.Contains(doc.Text, "Education") {
+		t.Errorf("text = %q", doc.Text)
+	}
+}
+
+// blankPDF returns a PDF of empty pages, as an image-only scan looks to a
+// text extractor
+func blankPDF(pages int) []byte {
+	var objects []string
+	kids := make([]string, pages)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", i+3)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages))
+	// Pages draw without showing text, like a page painting a scanned image
+	const content = "q 0.5 g 72 72 468 648 re f Q"
+	for i := 0; i < pages; i++ {
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> /Contents %d 0 R >>", pages+3+i))
+	}
+	for i := 0; i < pages; i++ {
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// odtWhitespace matches the whitespace runs that collapse to one space
+var odtWhitespace = regexp.MustCompile(`\s+`)
+
+// odtDocument walks content.xml of an OpenDocument text file and renders it
+// as plain text
+type odtDocument struct {
+	textLines
+	paragraphs []*strings.Builder
+	headings   []bool
+	listDepth  int
+}
+
+// extractFromODT extracts text from an OpenDocument text file, keeping
+// paragraphs, headings, list items and table rows
+func (te *TextExtractor) extractFromODT(ctx context.Context, filePath string) (string, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open ODT: %v", err)
+	}
+	defer archive.Close()
+
+	var contentPart *zip.File
+	for _, f := range archive.File {
+		if f.Name == "content.xml" {
+			contentPart = f
+			break
+		}
+	}
+	if contentPart == nil {
+		return "", fmt.Errorf("failed to open ODT: content.xml is missing")
+	}
+
+	rc, err := contentPart.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read ODT: %v", err)
+	}
+	defer rc.Close()
+
+	doc := &odtDocument{}
+	if err := doc.parse(ctx, io.LimitReader(rc, maxXMLPartSize)); err != nil {
+		return "", fmt.Errorf("failed to parse ODT: %v", err)
+	}
+
+	return te.finalCleanup(strings.Join(doc.lines, "\n")), nil
+}
+
+// parse streams the document body, emitting one line per paragraph and table row
+func (doc *odtDocument) parse(ctx context.Context, r io.Reader) error {
+	decoder := xml.NewDecoder(r)
+	inBody := false
+
+	for count := 0; ; count++ {
+		if count%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "body" {
+				inBody = true
+			}
+			if !inBody {
+				continue
+			}
+
+			switch t.Name.Local {
+			case "p", "h":
+				doc.paragraphs = append(doc.paragraphs, &strings.Builder{})
+				doc.headings = append(doc.headings, t.Name.Local == "h")
+			case "list":
+				doc.listDepth++
+			case "s":
+				// text:s collapses a run of spaces into one element
+				n, err := strconv.Atoi(xmlAttr(t, "c"))
+				if err != nil || n < 1 {
+					n = 1
+				}
+				doc.write(strings.Repeat(" ", min(n, 100)))
+			case "tab":
+				doc.write("\t")
+			case "line-break":
+				doc.write("\n")
+			case "note", "annotation", "tracked-changes":
+				// Footnotes, comments and change history are not resume text
+				if err := decoder.Skip(); err != nil {
+					return err
+				}
+			case "table":
+				doc.openTable()
+			case "table-cell":
+				if table := doc.table(); table != nil {
+					table.cell = nil
+				}
+			}
+
+		case xml.CharData:
+			// Whitespace in content.xml collapses as in HTML; explicit
+			// breaks and spaces are elements handled above
+			if inBody {
+				doc.write(odtWhitespace.ReplaceAllString(string(t), " "))
+			}
+
+		case xml.EndElement:
+			if !inBody {
+				continue
+			}
+
+			switch t.Name.Local {
+			case "body":
+				inBody = false
+			case "p", "h":
+				doc.endParagraph()
+			case "list":
+				if doc.listDepth > 0 {
+					doc.listDepth--
+				}
+			case "table-cell":
+				doc.endCell()
+			case "table-row":
+				doc.endRow()
+			case "table":
+				doc.closeTable()
+			}
+		}
+	}
+}
+
+// write appends text to the innermost open paragraph; text outside
+// paragraphs is layout whitespace
+func (doc *odtDocument) write(text string) {
+	if len(doc.paragraphs) > 0 {
+		doc.paragraphs[len(doc.paragraphs)-1].WriteString(text)
+	}
+}
+
+// endParagraph renders the closed paragraph or heading
+func (doc *odtDocument) endParagraph() {
+	if len(doc.paragraphs) == 0 {
+		return
+	}
+	last := len(doc.paragraphs) - 1
+	text := strings.TrimSpace(doc.paragraphs[last].String())
+	heading := doc.headings[last]
+	doc.paragraphs = doc.paragraphs[:last]
+	doc.headings = doc.headings[:last]
+
+	if text == "" {
+		if doc.table() == nil {
+			doc.emit("")
+		}
+		return
+	}
+
+	switch {
+	case heading && doc.table() == nil:
+		doc.emit("")
+		doc.emit(text)
+		doc.emit("")
+	case doc.listDepth > 0:
+		doc.emit("• " + text)
+	default:
+		doc.emit(text)
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractFromODTFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    string
+	}{
+		{
+			// Headings are set apart by blank lines; whitespace collapses,
+			// text:line-break is kept, and footnotes and annotations are
+			// not body text
+			fixture: "headings.odt",
+			want: "Jordan Lee\n" +
+				"\n" +
+				"Platform engineer in Berlin\n" +
+				"Go Rust SQL\n" +
+				"Line one\n" +
+				"Line two\n" +
+				"\n" +
+				"Experience\n" +
+				"\n" +
+				"Acme Corp\n" +
+				"\n" +
+				"Staff Engineer",
+		},
+		{
+			// List items at any depth become bullets; a table row is one
+			// line with its paragraphs joined and empty cells dropped
+			fixture: "lists.odt",
+			want: "Highlights\n" +
+				"\n" +
+				"• Cut deploy time by 40%\n" +
+				"• Owned the on-call rotation\n" +
+				"• Wrote the runbooks\n" +
+				"Not a list item\n" +
+				"Acme Corp Staff Engineer | 2019 – 2024\n" +
+				"\n" +
+				"After the table",
+		},
+	}
+
+	te := NewTextExtractor()
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := te.extractFromODT(context.Background(), filepath.Join("testdata", "odt", tt.fixture))
+			if err != nil {
+				t.Fatalf("extractFromODT: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("extracted text\n got: %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,551 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Layout analysis thresholds, relative to the font size of the glyphs involved
+const (
+	layoutLineTolerance  = 0.5  // Max baseline difference for glyphs on one line
+	layoutWordGap        = 0.2  // Min horizontal gap that separates two words
+	layoutSpanGap        = 2.0  // Min horizontal gap that separates two spans of a line
+	layoutEstimatedWidth = 0.5  // Glyph width in ems assumed when a font has no widths
+	layoutParagraphGap   = 1.8  // Min line spacing that starts a new paragraph
+	layoutHeadingRatio   = 1.15 // Min font size over body size for a heading
+	layoutMaxHeadingLen  = 80   // Longer lines are body text even in a large font
+	layoutMinGutterWidth = 12.0 // Points of empty space between two columns
+	layoutMinColumnLines = 5    // Lines of text each column needs, so a few right-aligned dates are not a column
+	layoutMinColumnShare = 0.25 // Min width of the widest span of each column, as a share of the text width
+	layoutMaxOutlineSize = 500  // Bookmarks read before giving up on a damaged outline
+)
+
+// Outline entry sources
+const (
+	OutlineSourceFontSize = "font_size" // Detected from a font size larger than the body text
+	OutlineSourceBookmark = "bookmark"  // Read from the PDF document outline
+)
+
+// OutlineEntry is a heading in the structural outline of a document
+type OutlineEntry struct {
+	Title  string `json:"title"`
+	Level  int    `json:"level"` // 1 for the most prominent headings
+	Page   int    `json:"page,omitempty"`
+	Source string `json:"source"`
+}
+
+// ExtractedDocument is the text of a document together with its structure
+type ExtractedDocument struct {
+	Text       string           `json:"text"`
+	FileType   string           `json:"file_type"` // MIME type detected from the content
+	Outline    []OutlineEntry   `json:"outline,omitempty"`
+	OCRPages   []OCRPage        `json:"ocr_pages,omitempty"` // Set when the text was recognized with OCR
+	Report     ExtractionReport `json:"report"`
+	JSONResume *JSONResume      `json:"-"` // Set for JSON Resume uploads
+}
+
+// layoutGlyph is a single positioned character from a page content stream
+type layoutGlyph struct {
+	x, y, w, size float64
+	s             string
+	space         bool // Explicit space; it separates words but does not extend a span
+}
+
+// layoutSpan is a run of glyphs on one line without a column-sized gap
+type layoutSpan struct {
+	x0, x1, y, size float64
+	text            string
+}
+
+// layoutLine is a horizontal line of text; it may hold several spans when
+// it crosses columns
+type layoutLine struct {
+	y, size float64
+	spans   []layoutSpan
+}
+
+// layoutBlock is a line of text in reading order
+type layoutBlock struct {
+	text    string
+	y, size float64
+	gap     bool // Preceded by paragraph spacing or a column change
+}
+
+// extractPDFLayout extracts text from a PDF by glyph position rather than
+// content stream order. Glyphs are grouped into lines and columns, columns
+// are read top to bottom and left to right, and lines set in a larger font
+// than the body text become headings in the outline.
+func (te *TextExtractor) extractPDFLayout(ctx context.Context, filePath string) (doc *ExtractedDocument, err error) {
+	file, reader, err := pdf.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %v", err)
+	}
+	defer file.Close()
+
+	// The PDF reader panics on some malformed content streams
+	defer func() {
+		if r := recover(); r != nil {
+			doc, err = nil, fmt.Errorf("failed to analyze PDF layout: %v", r)
+		}
+	}()
+
+	report := ExtractionReport{Method: ExtractionMethodNative, Pages: reader.NumPage()}
+	var pages [][]layoutBlock
+	for pageNum := 1; pageNum <= reader.NumPage(); pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		page := reader.Page(pageNum)
+		if page.V.IsNull() {
+			pages = append(pages, nil)
+			continue
+		}
+		blocks, columns := layoutPage(page.Content().Text)
+		switch {
+		case len(blocks) == 0:
+			report.warn("image-only page %d", pageNum)
+		case columns:
+			report.warn("multi-column layout on page %d; columns were read left to right", pageNum)
+		}
+		pages = append(pages, blocks)
+	}
+
+	bodySize := layoutBodySize(pages)
+	headingLevels := layoutHeadingLevels(pages, bodySize)
+
+	var lines []string
+	var outline []OutlineEntry
+	for i, blocks := range pages {
+		for _, block := range blocks {
+			if level, ok := headingLevels[layoutSizeKey(block.size)]; ok && layoutIsHeading(block) {
+				lines = append(lines, "", block.text, "")
+				outline = append(outline, OutlineEntry{Title: block.text, Level: level, Page: i + 1, Source: OutlineSourceFontSize})
+				continue
+			}
+			if block.gap {
+				lines = append(lines, "")
+			}
+			lines = append(lines, block.text)
+		}
+		lines = append(lines, "")
+	}
+
+	outline = append(outline, pdfBookmarks(reader)...)
+
+	return &ExtractedDocument{
+		Text:    te.finalCleanup(strings.Join(lines, "\n")),
+		Outline: outline,
+		Report:  report,
+	}, nil
+}
+
+// layoutPage arranges the glyphs of one page into lines of text in reading
+// order, reporting whether the page is set in two columns
+func layoutPage(texts []pdf.Text) ([]layoutBlock, bool) {
+	lines := layoutLines(layoutGlyphs(texts))
+	if len(lines) == 0 {
+		return nil, false
+	}
+	gutter, ok := layoutGutter(lines)
+	if !ok {
+		return layoutSingleColumn(lines), false
+	}
+	return layoutColumns(lines, gutter), true
+}
+
+// layoutHasColumns reports whether a page is set in two columns
+func layoutHasColumns(texts []pdf.Text) bool {
+	lines := layoutLines(layoutGlyphs(texts))
+	if len(lines) == 0 {
+		return false
+	}
+	_, ok := layoutGutter(lines)
+	return ok
+}
+
+// layoutGlyphs converts the text elements of a page into glyphs
+func layoutGlyphs(texts []pdf.Text) []layoutGlyph {
+	glyphs := make([]layoutGlyph, 0, len(texts))
+	var prev pdf.Text
+	runStart := 0 // First glyph of the string being estimated
+	for _, t := range texts {
+		if t.S == "" || t.FontSize <= 0 {
+			continue
+		}
+		g := layoutGlyph{x: t.X, y: t.Y, w: t.W, size: t.FontSize, s: t.S, space: strings.TrimSpace(t.S) == ""}
+		if t.W == 0 {
+			// The PDF library has no widths for composite (CID) fonts and
+			// puts every glyph of a string at its start, so the glyphs are
+			// spread out by an estimated width
+			g.w = layoutEstimatedWidth * t.FontSize
+			if g.space {
+				g.w /= 2
+			}
+			n := len(glyphs)
+			switch {
+			case n > 0 && prev.W == 0 && prev.X == t.X && prev.Y == t.Y && prev.Font == t.Font:
+				g.x = glyphs[n-1].x + glyphs[n-1].w
+			case n > 0 && prev.W == 0 && prev.Y == t.Y:
+				// A string continuing the line shows where the previous one
+				// really ended; an overlong estimate is shrunk to fit
+				fitLayoutRun(glyphs[runStart:], t.X)
+				runStart = n
+			default:
+				runStart = n
+			}
+		}
+		glyphs = append(glyphs, g)
+		prev = t
+	}
+	return glyphs
+}
+
+// fitLayoutRun shrinks the estimated glyphs of a string so that it ends by
+// end, where the next string on the line starts
+func fitLayoutRun(run []layoutGlyph, end float64) {
+	if len(run) == 0 {
+		return
+	}
+	start := run[0].x
+	last := run[len(run)-1]
+	estimated := last.x + last.w
+	if end <= start || estimated <= end {
+		return
+	}
+	scale := (end - start) / (estimated - start)
+	for i := range run {
+		run[i].x = start + (run[i].x-start)*scale
+		run[i].w *= scale
+	}
+}
+
+// layoutLines groups glyphs that share a baseline into lines, top to bottom,
+// and splits each line into spans at column-sized gaps
+func layoutLines(glyphs []layoutGlyph) []layoutLine {
+	sort.SliceStable(glyphs, func(i, j int) bool { return glyphs[i].y > glyphs[j].y })
+
+	var lines []layoutLine
+	var current []layoutGlyph
+	flush := func() {
+		if line, ok := buildLayoutLine(current); ok {
+			lines = append(lines, line)
+		}
+		current = nil
+	}
+
+	for _, g := range glyphs {
+		if len(current) > 0 {
+			first := current[0]
+			if math.Abs(g.y-first.y) > layoutLineTolerance*math.Min(g.size, first.size) {
+				flush()
+			}
+		}
+		current = append(current, g)
+	}
+	flush()
+
+	return lines
+}
+
+// buildLayoutLine joins the glyphs of a line into words and spans. It
+// reports false for a line of only spaces.
+func buildLayoutLine(glyphs []layoutGlyph) (layoutLine, bool) {
+	sort.SliceStable(glyphs, func(i, j int) bool { return glyphs[i].x < glyphs[j].x })
+
+	var line layoutLine
+	var span *layoutSpan
+	var text strings.Builder
+	end := 0.0
+	pendingSpace := false
+
+	for _, g := range glyphs {
+		if g.space {
+			pendingSpace = span != nil
+			continue
+		}
+
+		gap := g.x - end
+		if span != nil && gap > layoutSpanGap*g.size {
+			span.text = text.String()
+			line.spans = append(line.spans, *span)
+			span = nil
+		}
+		if span == nil {
+			span = &layoutSpan{x0: g.x, y: g.y}
+			line.y = g.y
+			text.Reset()
+		} else if pendingSpace || gap > layoutWordGap*g.size {
+			text.WriteString(" ")
+		}
+		pendingSpace = false
+
+		text.WriteString(g.s)
+		span.x1 = math.Max(span.x1, g.x+g.w)
+		span.size = math.Max(span.size, g.size)
+		line.size = math.Max(line.size, g.size)
+		end = math.Max(end, g.x+g.w)
+	}
+	if span == nil {
+		return line, false
+	}
+	span.text = text.String()
+	line.spans = append(line.spans, *span)
+
+	return line, true
+}
+
+// layoutGutter finds the x coordinate of the empty vertical strip between
+// two columns, if the page has one. A gutter must be crossed by few lines
+// and have text on both sides on a good share of lines, and each side must
+// hold enough lines of reasonable width to be a column.
+func layoutGutter(lines []layoutLine) (float64, bool) {
+	if len(lines) < 2*layoutMinColumnLines {
+		return 0, false
+	}
+
+	left, right := math.Inf(1), math.Inf(-1)
+	for _, line := range lines {
+		for _, span := range line.spans {
+			left = math.Min(left, span.x0)
+			right = math.Max(right, span.x1)
+		}
+	}
+	width := right - left
+	if width < 4*layoutMinGutterWidth {
+		return 0, false
+	}
+
+	// Only the middle of the text area can hold a gutter; a wide left
+	// margin next to indented text is not a column break
+	bestStart, bestWidth := 0.0, 0.0
+	runStart, inRun := 0.0, false
+	for x := left + 0.15*width; x <= left+0.85*width; x++ {
+		crossing, before, after := 0, 0, 0
+		for _, line := range lines {
+			for _, span := range line.spans {
+				switch {
+				case span.x0 < x && span.x1 > x:
+					crossing++
+				case span.x1 <= x:
+					before++
+				default:
+					after++
+				}
+			}
+		}
+
+		open := crossing*10 <= len(lines) && before*5 >= len(lines) && after*5 >= len(lines)
+		switch {
+		case open && !inRun:
+			runStart, inRun = x, true
+		case !open && inRun:
+			if x-runStart > bestWidth {
+				bestStart, bestWidth = runStart, x-runStart
+			}
+			inRun = false
+		}
+	}
+	if inRun && left+0.85*width-runStart > bestWidth {
+		bestStart, bestWidth = runStart, left+0.85*width-runStart
+	}
+
+	if bestWidth < layoutMinGutterWidth {
+		return 0, false
+	}
+	gutter := bestStart + bestWidth/2
+	if !layoutIsColumn(lines, gutter, width, true) || !layoutIsColumn(lines, gutter, width, false) {
+		return 0, false
+	}
+	return gutter, true
+}
+
+// layoutIsColumn reports whether the text on one side of a gutter is a
+// column rather than a few short spans, such as right-aligned dates beside
+// entry titles or a centered name beside left-aligned headings
+func layoutIsColumn(lines []layoutLine, gutter, width float64, left bool) bool {
+	count, widest := 0, 0.0
+	for _, line := range lines {
+		found := false
+		for _, span := range line.spans {
+			if (left && span.x1 <= gutter) || (!left && span.x0 >= gutter) {
+				found = true
+				widest = math.Max(widest, span.x1-span.x0)
+			}
+		}
+		if found {
+			count++
+		}
+	}
+	return count >= layoutMinColumnLines && widest >= layoutMinColumnShare*width
+}
+
+// layoutSingleColumn emits the spans of each line left to right
+func layoutSingleColumn(lines []layoutLine) []layoutBlock {
+	var blocks []layoutBlock
+	prevY, prevSize := math.Inf(1), 0.0
+	for _, line := range lines {
+		texts := make([]string, 0, len(line.spans))
+		for _, span := range line.spans {
+			texts = append(texts, span.text)
+		}
+		blocks = append(blocks, layoutBlock{
+			text: strings.Join(texts, "  "),
+			y:    line.y,
+			size: line.size,
+			gap:  len(blocks) > 0 && prevY-line.y > layoutParagraphGap*math.Max(prevSize, line.size),
+		})
+		prevY, prevSize = line.y, line.size
+	}
+	return blocks
+}
+
+// layoutColumns reads a two-column page. Lines that cross the gutter, such
+// as a name banner, split the page into bands; within a band the left column
+// is read before the right.
+func layoutColumns(lines []layoutLine, gutter float64) []layoutBlock {
+	var blocks []layoutBlock
+	var leftLines, rightLines []layoutLine
+
+	flushBand := func() {
+		for _, column := range [][]layoutLine{leftLines, rightLines} {
+			columnBlocks := layoutSingleColumn(column)
+			if len(columnBlocks) > 0 && len(blocks) > 0 {
+				columnBlocks[0].gap = true
+			}
+			blocks = append(blocks, columnBlocks...)
+		}
+		leftLines, rightLines = nil, nil
+	}
+
+	for _, line := range lines {
+		crosses := false
+		for _, span := range line.spans {
+			if span.x0 < gutter && span.x1 > gutter {
+				crosses = true
+				break
+			}
+		}
+
+		if crosses {
+			flushBand()
+			full := layoutSingleColumn([]layoutLine{line})
+			if len(blocks) > 0 {
+				prev := blocks[len(blocks)-1]
+				full[0].gap = prev.y-line.y > layoutParagraphGap*math.Max(prev.size, line.size)
+			}
+			blocks = append(blocks, full...)
+			continue
+		}
+
+		var leftSpans, rightSpans []layoutSpan
+		for _, span := range line.spans {
+			if span.x1 <= gutter {
+				leftSpans = append(leftSpans, span)
+			} else {
+				rightSpans = append(rightSpans, span)
+			}
+		}
+		if len(leftSpans) > 0 {
+			leftLines = append(leftLines, layoutLineOf(line.y, leftSpans))
+		}
+		if len(rightSpans) > 0 {
+			rightLines = append(rightLines, layoutLineOf(line.y, rightSpans))
+		}
+	}
+	flushBand()
+
+	return blocks
+}
+
+// layoutLineOf builds a line from a subset of another line's spans
+func layoutLineOf(y float64, spans []layoutSpan) layoutLine {
+	line := layoutLine{y: y, spans: spans}
+	for _, span := range spans {
+		line.size = math.Max(line.size, span.size)
+	}
+	return line
+}
+
+// layoutSizeKey rounds a font size so sizes that differ only by rendering
+// precision compare equal
+func layoutSizeKey(size float64) float64 {
+	return math.Round(size*2) / 2
+}
+
+// layoutBodySize returns the font size used by most of the text
+func layoutBodySize(pages [][]layoutBlock) float64 {
+	weights := map[float64]int{}
+	for _, blocks := range pages {
+		for _, block := range blocks {
+			weights[layoutSizeKey(block.size)] += len(block.text)
+		}
+	}
+
+	bodySize, best := 0.0, -1
+	for size, weight := range weights {
+		if weight > best || (weight == best && size < bodySize) {
+			bodySize, best = size, weight
+		}
+	}
+	return bodySize
+}
+
+// layoutHeadingLevels ranks the font sizes larger than the body text, the
+// largest becoming level 1
+func layoutHeadingLevels(pages [][]layoutBlock, bodySize float64) map[float64]int {
+	var sizes []float64
+	seen := map[float64]bool{}
+	for _, blocks := range pages {
+		for _, block := range blocks {
+			key := layoutSizeKey(block.size)
+			if !seen[key] && key >= bodySize*layoutHeadingRatio && layoutIsHeading(block) {
+				seen[key] = true
+				sizes = append(sizes, key)
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(sizes)))
+
+	levels := make(map[float64]int, len(sizes))
+	for i, size := range sizes {
+		levels[size] = min(i+1, 6)
+	}
+	return levels
+}
+
+// layoutIsHeading reports whether a line is short and worded like a heading
+func layoutIsHeading(block layoutBlock) bool {
+	text := strings.TrimSpace(block.text)
+	if text == "" || len([]rune(text)) > layoutMaxHeadingLen {
+		return false
+	}
+	return strings.IndexFunc(text, unicode.IsLetter) >= 0
+}
+
+// pdfBookmarks reads the document outline, bounding the walk because a
+// damaged outline can link back to itself
+func pdfBookmarks(reader *pdf.Reader) []OutlineEntry {
+	var entries []OutlineEntry
+	visited := 0
+	var walk func(node pdf.Value, level int)
+	walk = func(node pdf.Value, level int) {
+		for child := node.Key("First"); child.Kind() == pdf.Dict && visited < layoutMaxOutlineSize; child = child.Key("Next") {
+			visited++
+			if title := strings.TrimSpace(child.Key("Title").Text()); title != "" {
+				entries = append(entries, OutlineEntry{Title: title, Level: level, Source: OutlineSourceBookmark})
+			}
+			if level < 6 {
+				walk(child, level+1)
+			}
+		}
+	}
+	walk(reader.Trailer().Key("Root").Key("Outlines"), 1)
+	return entries
+}
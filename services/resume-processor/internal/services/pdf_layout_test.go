@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+)
+
+func TestLayoutGlyphsSpreadsGlyphsWithoutWidths(t *testing.T) {
+	// A composite font: every glyph of "Go, SQL" sits at the string start,
+	// and the next string starts at x 130
+	var texts []pdf.Text
+	for _, s := range []string{"G", "o", ",", " ", "S", "Q", "L"} {
+		texts = append(texts, pdf.Text{Font: "F1", FontSize: 10, X: 100, Y: 700, S: s})
+	}
+	texts = append(texts, pdf.Text{Font: "F1", FontSize: 10, X: 130, Y: 700, S: "!"})
+
+	glyphs := layoutGlyphs(texts)
+	if len(glyphs) != len(texts) {
+		t.Fatalf("%d glyphs, want %d", len(glyphs), len(texts))
+	}
+	for i := 1; i < 7; i++ {
+		if glyphs[i].x <= glyphs[i-1].x {
+			t.Errorf("glyph %d at x %.2f, not after glyph %d at %.2f", i, glyphs[i].x, i-1, glyphs[i-1].x)
+		}
+	}
+	// The estimate (32.5pt) overruns the next string and is shrunk to fit
+	if end := glyphs[6].x + glyphs[6].w; end > 130.001 {
+		t.Errorf("string ends at x %.2f, past the next string at 130", end)
+	}
+	if glyphs[7].x != 130 {
+		t.Errorf("next string at x %.2f, want 130", glyphs[7].x)
+	}
+}
+
+// layoutTexts returns one text element per character of s, as a content
+// stream with font widths would
+func layoutTexts(x, y, size float64, s string) []pdf.Text {
+	var texts []pdf.Text
+	for _, r := range s {
+		w := 0.5 * size
+		texts = append(texts, pdf.Text{Font: "F1", FontSize: size, X: x, Y: y, W: w, S: string(r)})
+		x += w
+	}
+	return texts
+}
+
+// layoutBlockTexts returns the text of blocks
+func layoutBlockTexts(blocks []layoutBlock) []string {
+	texts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		texts = append(texts, block.text)
+	}
+	return texts
+}
+
+func TestLayoutPageReadsColumnsInOrder(t *testing.T) {
+	// A name banner across the page over a sidebar and a main column whose
+	// lines share baselines
+	texts := layoutTexts(40, 760, 20, "Jordan Lee, Platform Engineer")
+	sidebar := []string{"CONTACT", "jordan@example.com", "Berlin, Germany", "SKILLS", "Go, Rust, SQL", "Kubernetes", "Terraform", "LANGUAGES", "English", "German"}
+	main := []string{"EXPERIENCE", "Staff Engineer, Acme Corp", "Led the storage team of six", "Cut p99 latency by half", "Senior Engineer, Initech", "Built the billing pipeline", "Ran the on-call rotation", "EDUCATION", "B.Sc. Computer Science", "Technical University of Munich"}
+	for i := range sidebar {
+		y := 720 - float64(i)*14
+		texts = append(texts, layoutTexts(40, y, 10, sidebar[i])...)
+		texts = append(texts, layoutTexts(220, y, 10, main[i])...)
+	}
+
+	blocks, columns := layoutPage(texts)
+	if !columns {
+		t.Fatal("two-column page read as one column")
+	}
+	want := append(append([]string{"Jordan Lee, Platform Engineer"}, sidebar...), main...)
+	if got := layoutBlockTexts(blocks); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("reading order\n got: %q\nwant: %q", got, want)
+	}
+	if !blocks[len(sidebar)+1].gap {
+		t.Error("main column does not start a new paragraph")
+	}
+}
+
+func TestLayoutPageKeepsRightAlignedDates(t *testing.T) {
+	// Entry titles with dates set flush right are one column, however few
+	// other lines the page has
+	var texts []pdf.Text
+	var want []string
+	y := 720.0
+	for _, entry := range [][2]string{
+		{"Senior Software Engineer, Northwind Analytics", "Mar 2020 – Present"},
+		{"Software Engineer, Contoso Ltd", "Jun 2016 – Feb 2020"},
+		{"B.Sc. Computer Science, Technical University of Munich", "2012 – 2016"},
+	} {
+		texts = append(texts, layoutTexts(40, y, 10, entry[0])...)
+		texts = append(texts, layoutTexts(570-5*float64(len([]rune(entry[1]))), y, 10, entry[1])...)
+		texts = append(texts, layoutTexts(40, y-14, 10, "• Shipped the quarterly roadmap")...)
+		want = append(want, entry[0]+"  "+entry[1], "• Shipped the quarterly roadmap")
+		y -= 42
+	}
+
+	blocks, columns := layoutPage(texts)
+	if columns {
+		t.Error("right-aligned dates read as a second column")
+	}
+	if got := layoutBlockTexts(blocks); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("reading order\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestExtractPDFLayoutTemplates(t *testing.T) {
+	full := testResumeDocument().Blocks
+	short := full[:10]
+
+	tests := []struct {
+		template string
+		blocks   []ResumeBlock
+		want     []string // Lines that must appear in this order
+	}{
+		{
+			template: PDFTemplateClassic,
+			blocks:   []ResumeBlock{full[0], full[1], full[3], full[5], full[9], full[11], full[13]},
+			want:     []string{"Alex Morgan", "Senior Software Engineer", "SUMMARY", "EXPERIENCE", "Software Engineer, Contoso Ltd", "Jun 2016 – Feb 2020", "EDUCATION", "SKILLS"},
+		},
+		{
+			// Symbols and accented letters are kept as written
+			template: PDFTemplateClassic,
+			blocks:   append(full[:10:10], ResumeBlock{Kind: ResumeBlockBullet, Text: "Rewrote the C# SDK and a naïve Bayes filter for R&D"}),
+			want:     []string{"Alex Morgan", "EXPERIENCE", "Senior Software Engineer, Northwind Analytics", "Mar 2020 – Present", "Software Engineer, Contoso Ltd", "Jun 2016 – Feb 2020", "• Rewrote the C# SDK and a naïve Bayes filter for R&D"},
+		},
+		{
+			template: PDFTemplateModern,
+			blocks:   short,
+			want:     []string{"Alex Morgan", "EXPERIENCE", "Senior Software Engineer, Northwind Analytics Mar 2020 – Present", "Software Engineer, Contoso Ltd Jun 2016 – Feb 2020"},
+		},
+		{
+			template: PDFTemplateCompact,
+			blocks:   short,
+			want:     []string{"Alex Morgan", "EXPERIENCE", "Senior Software Engineer, Northwind Analytics Mar 2020 – Present", "Software Engineer, Contoso Ltd Jun 2016 – Feb 2020"},
+		},
+	}
+
+	for _, tt := range tests {
+		renderer, err := NewPDFRenderer(tt.template)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := renderer.Render(&ResumeDocument{Name: "Alex Morgan", Blocks: tt.blocks})
+		if err != nil {
+			t.Fatalf("%s: Render: %v", tt.template, err)
+		}
+		doc, err := NewTextExtractor().extractPDFLayout(context.Background(), writeTestFile(t, "resume.pdf", data))
+		if err != nil {
+			t.Fatalf("%s: extractPDFLayout: %v", tt.template, err)
+		}
+
+		if len(doc.Report.Warnings) > 0 {
+			t.Errorf("%s: warnings %q", tt.template, doc.Report.Warnings)
+		}
+		lines := strings.Split(doc.Text, "\n")
+		next := 0
+		for _, want := range tt.want {
+			for next < len(lines) && lines[next] != want {
+				next++
+			}
+			if next == len(lines) {
+				t.Errorf("%s: %q missing or out of order in\n%s", tt.template, want, doc.Text)
+				break
+			}
+		}
+	}
+}
+
+func TestExtractPDFLayoutOutline(t *testing.T) {
+	// The modern template sets the name and section headings in larger
+	// type than the body; the largest size is level 1
+	renderer, err := NewPDFRenderer(PDFTemplateModern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := renderer.Render(testResumeDocument())
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	doc, err := NewTextExtractor().extractPDFLayout(context.Background(), writeTestFile(t, "resume.pdf", data))
+	if err != nil {
+		t.Fatalf("extractPDFLayout: %v", err)
+	}
+
+	want := []OutlineEntry{{Title: "Alex Morgan", Level: 1, Page: 1, Source: OutlineSourceFontSize}}
+	for _, heading := range []string{"SUMMARY", "EXPERIENCE", "EDUCATION", "SKILLS"} {
+		want = append(want, OutlineEntry{Title: heading, Level: 2, Page: 1, Source: OutlineSourceFontSize})
+	}
+	if !reflect.DeepEqual(doc.Outline, want) {
+		t.Errorf("outline\n got: %+v\nwant: %+v", doc.Outline, want)
+	}
+	// Headings are set apart by blank lines
+	if !strings.Contains(doc.Text, "\n\nEXPERIENCE\n\n") {
+		t.Errorf("heading not set apart:\n%s", doc.Text)
+	}
+}
@@ -0,0 +1,380 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// Built-in PDF templates
+const (
+	PDFTemplateClassic = "classic" // Centered header, ruled all-caps headings
+	PDFTemplateModern  = "modern"  // Colored name and headings, dates beside the titles
+	PDFTemplateCompact = "compact" // Small type and margins to fit more on a page
+)
+
+// ErrUnknownPDFTemplate is returned for template names that are not built in
+var ErrUnknownPDFTemplate = errors.New("unknown PDF template")
+
+// Page sizes in points
+var pdfPageSizes = map[string][2]float64{
+	"letter": {612, 792},
+	"a4":     {595.28, 841.89},
+}
+
+var (
+	pdfTextColor  = pdfColor{0.1, 0.1, 0.1}
+	pdfMutedColor = pdfColor{0.35, 0.35, 0.35}
+)
+
+// pdfTemplate holds the layout settings of a template
+type pdfTemplate struct {
+	margin        float64 // Page margin in points
+	bodySize      float64
+	nameSize      float64
+	headingSize   float64
+	leading       float64 // Line height as a multiple of the font size
+	entryGap      float64 // Space before an entry
+	sectionGap    float64 // Space before a heading
+	accent        pdfColor
+	centerHeader  bool // Center the name and contact details
+	headerRule    bool // Rule under the contact details
+	upperHeadings bool
+	headingRule   bool // Rule under each heading
+	datesBeside   bool // Dates right-aligned on the entry's line instead of below it
+}
+
+var pdfTemplates = map[string]pdfTemplate{
+	PDFTemplateClassic: {
+		margin: 54, bodySize: 10.5, nameSize: 20, headingSize: 11.5, leading: 1.3,
+		entryGap: 6, sectionGap: 12, accent: pdfTextColor,
+		centerHeader: true, upperHeadings: true, headingRule: true,
+	},
+	PDFTemplateModern: {
+		margin: 50, bodySize: 10, nameSize: 24, headingSize: 13, leading: 1.35,
+		entryGap: 7, sectionGap: 14, accent: pdfColor{0.12, 0.31, 0.55},
+		headerRule: true, datesBeside: true,
+	},
+	PDFTemplateCompact: {
+		margin: 36, bodySize: 9, nameSize: 16, headingSize: 10, leading: 1.2,
+		entryGap: 3, sectionGap: 7, accent: pdfTextColor,
+		upperHeadings: true, headingRule: true, datesBeside: true,
+	},
+}
+
+// PDFTemplates lists the built-in template names
+func PDFTemplates() []string {
+	names := make([]string, 0, len(pdfTemplates))
+	for name := range pdfTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PDFRenderer lays out resumes as PDF files. The output is a single column
+// of real text in reading order with the fonts embedded, so it can be
+// selected, searched and parsed by applicant tracking systems.
+type PDFRenderer struct {
+	template   pdfTemplate
+	pageWidth  float64
+	pageHeight float64
+}
+
+// NewPDFRenderer creates a renderer for a built-in template; an empty name
+// selects the classic template. The page size is read from PDF_PAGE_SIZE
+// (letter or a4, default letter).
+func NewPDFRenderer(template string) (*PDFRenderer, error) {
+	if template == "" {
+		template = PDFTemplateClassic
+	}
+	settings, ok := pdfTemplates[template]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %s)", ErrUnknownPDFTemplate, template, strings.Join(PDFTemplates(), ", "))
+	}
+
+	size, ok := pdfPageSizes[strings.ToLower(os.Getenv("PDF_PAGE_SIZE"))]
+	if !ok {
+		size = pdfPageSizes["letter"]
+	}
+	return &PDFRenderer{template: settings, pageWidth: size[0], pageHeight: size[1]}, nil
+}
+
+// Render lays out a resume document and returns the PDF file
+func (r *PDFRenderer) Render(doc *ResumeDocument) ([]byte, error) {
+	pdf := &pdfDocument{width: r.pageWidth, height: r.pageHeight, title: doc.Name, author: doc.Name}
+	if doc.Name != "" {
+		pdf.title = doc.Name + " - Resume"
+	}
+
+	c := &pdfComposer{pdf: pdf, t: r.template}
+	var err error
+	if c.regular, err = pdf.addFont(goregular.TTF, false); err != nil {
+		return nil, err
+	}
+	if c.bold, err = pdf.addFont(gobold.TTF, false); err != nil {
+		return nil, err
+	}
+	if c.italic, err = pdf.addFont(goitalic.TTF, true); err != nil {
+		return nil, err
+	}
+	c.bullet = "•"
+	if gid, _ := c.regular.glyph('•'); gid == 0 {
+		c.bullet = "-"
+	}
+
+	c.newPage()
+	for _, block := range doc.Blocks {
+		c.block(block)
+	}
+	return pdf.bytes()
+}
+
+// pdfRun is a piece of text set in one font
+type pdfRun struct {
+	font *pdfFont
+	text string
+}
+
+// pdfComposer places blocks on pages, starting new pages as they fill up
+type pdfComposer struct {
+	pdf                   *pdfDocument
+	t                     pdfTemplate
+	regular, bold, italic *pdfFont
+	bullet                string
+	page                  *bytes.Buffer
+	y                     float64 // Top of the next line
+	headerDone            bool
+}
+
+func (c *pdfComposer) newPage() {
+	c.page = c.pdf.addPage()
+	c.y = c.pdf.height - c.t.margin
+}
+
+// ensure starts a new page unless height points are left on this one
+func (c *pdfComposer) ensure(height float64) {
+	if c.y-height < c.t.margin && c.y < c.pdf.height-c.t.margin {
+		c.newPage()
+	}
+}
+
+// space adds vertical space, unless at the top of a page
+func (c *pdfComposer) space(points float64) {
+	if c.y < c.pdf.height-c.t.margin {
+		c.y -= points
+	}
+}
+
+func (c *pdfComposer) contentWidth() float64 {
+	return c.pdf.width - 2*c.t.margin
+}
+
+// block lays out one block
+func (c *pdfComposer) block(b ResumeBlock) {
+	t := c.t
+	align := "left"
+	if t.centerHeader {
+		align = "center"
+	}
+
+	switch b.Kind {
+	case ResumeBlockName:
+		c.paragraph([]pdfRun{{c.bold, b.Text}}, t.nameSize, t.accent, 0, align)
+		c.space(2)
+	case ResumeBlockLabel:
+		c.paragraph([]pdfRun{{c.regular, b.Text}}, t.bodySize+1, pdfMutedColor, 0, align)
+	case ResumeBlockContact:
+		c.paragraph([]pdfRun{{c.regular, b.Text}}, t.bodySize-0.5, pdfMutedColor, 0, align)
+	case ResumeBlockHeading:
+		c.finishHeader()
+		text := b.Text
+		if t.upperHeadings {
+			text = strings.ToUpper(text)
+		}
+		c.space(t.sectionGap)
+		// Keep the heading with the first lines of its section
+		c.ensure(t.headingSize*t.leading + 3*t.bodySize*t.leading)
+		c.paragraph([]pdfRun{{c.bold, text}}, t.headingSize, t.accent, 0, "left")
+		if t.headingRule {
+			c.y -= 2
+			pdfLine(c.page, t.margin, c.y, c.pdf.width-t.margin, c.y, 0.6, t.accent)
+		}
+		c.y -= 4
+	case ResumeBlockEntry:
+		c.finishHeader()
+		c.space(t.entryGap)
+		c.ensure(2 * t.bodySize * t.leading)
+		c.entry(b)
+	case ResumeBlockBullet:
+		c.finishHeader()
+		c.bulletPoint(b.Text)
+	default:
+		c.finishHeader()
+		runs := []pdfRun{{c.regular, b.Text}}
+		if b.Label != "" {
+			runs = []pdfRun{{c.bold, b.Label + ": "}, {c.regular, b.Text}}
+		}
+		c.paragraph(runs, t.bodySize, pdfTextColor, 0, "left")
+	}
+}
+
+// finishHeader draws the rule under the header once the body starts
+func (c *pdfComposer) finishHeader() {
+	if c.headerDone {
+		return
+	}
+	c.headerDone = true
+	if c.t.headerRule {
+		c.y -= 6
+		pdfLine(c.page, c.t.margin, c.y, c.pdf.width-c.t.margin, c.y, 1.2, c.t.accent)
+		c.y -= 2
+	}
+}
+
+// entry lays out the title of a position with its dates
+func (c *pdfComposer) entry(b ResumeBlock) {
+	t := c.t
+	if b.Detail == "" {
+		c.paragraph([]pdfRun{{c.bold, b.Text}}, t.bodySize, pdfTextColor, 0, "left")
+		return
+	}
+	if !t.datesBeside || b.Text == "" {
+		c.paragraph([]pdfRun{{c.bold, b.Text}}, t.bodySize, pdfTextColor, 0, "left")
+		c.paragraph([]pdfRun{{c.italic, b.Detail}}, t.bodySize, pdfMutedColor, 0, "left")
+		return
+	}
+
+	detailWidth := c.italic.textWidth(b.Detail, t.bodySize)
+	lines := c.wrap([]pdfRun{{c.bold, b.Text}}, t.bodySize, c.contentWidth()-detailWidth-12)
+	for i, line := range lines {
+		c.y -= t.bodySize * t.leading
+		c.drawLine(line, t.bodySize, pdfTextColor, t.margin)
+		if i == 0 {
+			pdfText(c.page, c.italic, t.bodySize, c.pdf.width-t.margin-detailWidth, c.baseline(t.bodySize), pdfMutedColor, b.Detail)
+		}
+	}
+}
+
+// bulletPoint lays out a bullet with a hanging indent
+func (c *pdfComposer) bulletPoint(text string) {
+	t := c.t
+	indent := t.bodySize * 1.4
+	for i, line := range c.wrap([]pdfRun{{c.regular, text}}, t.bodySize, c.contentWidth()-indent) {
+		c.ensure(t.bodySize * t.leading)
+		c.y -= t.bodySize * t.leading
+		if i == 0 {
+			pdfText(c.page, c.regular, t.bodySize, t.margin+indent*0.35, c.baseline(t.bodySize), pdfTextColor, c.bullet)
+		}
+		c.drawLine(line, t.bodySize, pdfTextColor, t.margin+indent)
+	}
+}
+
+// paragraph wraps runs of text to the content width and draws them
+func (c *pdfComposer) paragraph(runs []pdfRun, size float64, color pdfColor, indent float64, align string) {
+	for _, line := range c.wrap(runs, size, c.contentWidth()-indent) {
+		c.ensure(size * c.t.leading)
+		c.y -= size * c.t.leading
+		x := c.t.margin + indent
+		if align == "center" {
+			x = c.t.margin + (c.contentWidth()-lineWidth(line, size))/2
+		}
+		c.drawLine(line, size, color, x)
+	}
+}
+
+// baseline returns the baseline of the line just moved to
+func (c *pdfComposer) baseline(size float64) float64 {
+	return c.y + (c.t.leading-1)*size/2 + size*0.2
+}
+
+// drawLine draws the runs of a line one after another
+func (c *pdfComposer) drawLine(line []pdfRun, size float64, color pdfColor, x float64) {
+	y := c.baseline(size)
+	for _, run := range line {
+		pdfText(c.page, run.font, size, x, y, color, run.text)
+		x += run.font.textWidth(run.text, size)
+	}
+}
+
+// lineWidth returns the width of a line of runs
+func lineWidth(line []pdfRun, size float64) float64 {
+	width := 0.0
+	for _, run := range line {
+		width += run.font.textWidth(run.text, size)
+	}
+	return width
+}
+
+// wrap breaks runs of text into lines no wider than width. Words are kept
+// whole unless a single word is wider than a line, as long links can be.
+func (c *pdfComposer) wrap(runs []pdfRun, size, width float64) [][]pdfRun {
+	var lines [][]pdfRun
+	var line []pdfRun
+	lineW := 0.0
+
+	appendWord := func(f *pdfFont, word string, space bool) {
+		if space && lineW > 0 {
+			word = " " + word
+		}
+		if n := len(line); n > 0 && line[n-1].font == f {
+			line[n-1].text += word
+		} else {
+			line = append(line, pdfRun{f, word})
+		}
+		lineW += f.textWidth(word, size)
+	}
+	breakLine := func() {
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+		line, lineW = nil, 0
+	}
+
+	for _, run := range runs {
+		// A run ending in a space, such as a "Label: " lead-in, is followed by a space
+		trailingSpace := strings.HasSuffix(run.text, " ")
+		for i, word := range strings.Fields(run.text) {
+			spaced := i > 0 || strings.HasPrefix(run.text, " ")
+			wordW := run.font.textWidth(word, size)
+			spaceW := 0.0
+			if spaced && lineW > 0 {
+				spaceW = run.font.textWidth(" ", size)
+			}
+			if lineW > 0 && lineW+spaceW+wordW > width {
+				breakLine()
+			}
+			for lineW == 0 && wordW > width && utf8.RuneCountInString(word) > 1 {
+				// Split an overlong word at the last character that fits
+				cut := 0
+				for idx := range word {
+					if idx > 0 && run.font.textWidth(word[:idx], size) > width {
+						break
+					}
+					cut = idx
+				}
+				if cut == 0 {
+					_, cut = utf8.DecodeRuneInString(word)
+				}
+				appendWord(run.font, word[:cut], false)
+				breakLine()
+				word = word[cut:]
+				wordW = run.font.textWidth(word, size)
+			}
+			appendWord(run.font, word, spaced)
+		}
+		if trailingSpace && lineW > 0 {
+			appendWord(run.font, " ", false)
+		}
+	}
+	breakLine()
+	return lines
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// testResumeDocument returns a short resume with every kind of block
+func testResumeDocument() *ResumeDocument {
+	return &ResumeDocument{
+		Name: "Alex Morgan",
+		Blocks: []ResumeBlock{
+			{Kind: ResumeBlockName, Text: "Alex Morgan"},
+			{Kind: ResumeBlockLabel, Text: "Senior Software Engineer"},
+			{Kind: ResumeBlockContact, Text: "alex.morgan@example.com | +1 555 010 2030 | Berlin, Germany"},
+			{Kind: ResumeBlockHeading, Text: "SUMMARY"},
+			{Kind: ResumeBlockParagraph, Text: "Backend engineer with 8 years of experience building distributed systems in Go and Python."},
+			{Kind: ResumeBlockHeading, Text: "EXPERIENCE"},
+			{Kind: ResumeBlockEntry, Text: "Senior Software Engineer, Northwind Analytics", Detail: "Mar 2020 – Present"},
+			{Kind: ResumeBlockBullet, Text: "Led the migration of the billing platform to event sourcing"},
+			{Kind: ResumeBlockBullet, Text: "Mentored five engineers and introduced design reviews"},
+			{Kind: ResumeBlockEntry, Text: "Software Engineer, Contoso Ltd", Detail: "Jun 2016 – Feb 2020"},
+			{Kind: ResumeBlockBullet, Text: "Built the REST APIs behind the customer portal"},
+			{Kind: ResumeBlockHeading, Text: "EDUCATION"},
+			{Kind: ResumeBlockEntry, Text: "B.Sc. Computer Science, Technical University of Munich", Detail: "2012 – 2016"},
+			{Kind: ResumeBlockHeading, Text: "SKILLS"},
+			{Kind: ResumeBlockParagraph, Label: "Languages", Text: "Go, Python, SQL"},
+			{Kind: ResumeBlockParagraph, Label: "Tools", Text: "Kubernetes, PostgreSQL, Kafka"},
+		},
+	}
+}
+
+func TestRenderPDFExtractsInReadingOrder(t *testing.T) {
+	renderer, err := NewPDFRenderer("classic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := renderer.Render(testResumeDocument())
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	doc, err := NewTextExtractor().extractPDFLayout(context.Background(), writeTestFile(t, "resume.pdf", data))
+	if err != nil {
+		t.Fatalf("extractPDFLayout: %v", err)
+	}
+
+	// The built-in fonts have no width tables for the PDF reader, so this
+	// relies on the layout extractor's estimated glyph widths
+	if !strings.HasPrefix(doc.Text, "Alex Morgan\n") {
+		t.Errorf("text does not start with the name:\n%s", doc.Text)
+	}
+	if !containsString(strings.Split(doc.Text, "\n"), "Languages: Go, Python, SQL") {
+		t.Errorf("skills line was split:\n%s", doc.Text)
+	}
+}
@@ -0,0 +1,385 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// pdfFontUnits is the size of an em in PDF glyph space
+const pdfFontUnits = 1000
+
+// pdfColor is an RGB color with components between 0 and 1
+type pdfColor [3]float64
+
+// pdfFont is a TrueType font embedded in a PDF as a Type0 font with
+// Identity-H encoding: text is written as glyph IDs and a ToUnicode map
+// turns them back into characters, so the text stays selectable and
+// searchable
+type pdfFont struct {
+	resource string // Name in the page resources, e.g. F1
+	data     []byte
+	font     *sfnt.Font
+	buf      sfnt.Buffer
+	name     string // PostScript name
+	italic   bool
+
+	ascent, descent, capHeight float64    // In 1/1000 em
+	bbox                       [4]float64 // In 1/1000 em
+
+	glyphs  map[rune]sfnt.GlyphIndex
+	widths  map[sfnt.GlyphIndex]float64 // Advance in 1/1000 em
+	unicode map[sfnt.GlyphIndex]rune    // Character of each glyph written, for the ToUnicode map
+}
+
+// newPDFFont parses a TrueType font for embedding
+func newPDFFont(resource string, data []byte, italic bool) (*pdfFont, error) {
+	parsed, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %v", err)
+	}
+
+	f := &pdfFont{
+		resource: resource,
+		data:     data,
+		font:     parsed,
+		italic:   italic,
+		glyphs:   map[rune]sfnt.GlyphIndex{},
+		widths:   map[sfnt.GlyphIndex]float64{},
+		unicode:  map[sfnt.GlyphIndex]rune{},
+	}
+
+	ppem := fixed.I(pdfFontUnits)
+	metrics, err := parsed.Metrics(&f.buf, ppem, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font metrics: %v", err)
+	}
+	f.ascent = fixedToFloat(metrics.Ascent)
+	f.descent = fixedToFloat(metrics.Descent)
+	f.capHeight = fixedToFloat(metrics.CapHeight)
+	if f.capHeight == 0 {
+		f.capHeight = f.ascent * 0.7
+	}
+
+	bounds, err := parsed.Bounds(&f.buf, ppem, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font bounds: %v", err)
+	}
+	// sfnt bounds grow downwards, PDF glyph space grows upwards
+	f.bbox = [4]float64{fixedToFloat(bounds.Min.X), -fixedToFloat(bounds.Max.Y), fixedToFloat(bounds.Max.X), -fixedToFloat(bounds.Min.Y)}
+
+	name, err := parsed.Name(&f.buf, sfnt.NameIDPostScript)
+	if err != nil || name == "" {
+		name = resource
+	}
+	f.name = strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' || strings.ContainsRune("()<>[]{}/%#", r) {
+			return -1
+		}
+		return r
+	}, name)
+
+	return f, nil
+}
+
+// fixedToFloat converts a 26.6 fixed point number
+func fixedToFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+// glyph returns the glyph for a character, or the glyph of "?" when the
+// font has none
+func (f *pdfFont) glyph(r rune) (sfnt.GlyphIndex, rune) {
+	if gid, ok := f.glyphs[r]; ok {
+		if gid == 0 {
+			return f.glyph('?')
+		}
+		return gid, r
+	}
+
+	gid, err := f.font.GlyphIndex(&f.buf, r)
+	if err != nil {
+		gid = 0
+	}
+	f.glyphs[r] = gid
+	if gid == 0 && r != '?' {
+		return f.glyph('?')
+	}
+	return gid, r
+}
+
+// width returns the advance of a glyph in 1/1000 em
+func (f *pdfFont) width(gid sfnt.GlyphIndex) float64 {
+	if w, ok := f.widths[gid]; ok {
+		return w
+	}
+	advance, err := f.font.GlyphAdvance(&f.buf, gid, fixed.I(pdfFontUnits), font.HintingNone)
+	w := 0.0
+	if err == nil {
+		w = fixedToFloat(advance)
+	}
+	f.widths[gid] = w
+	return w
+}
+
+// textWidth returns the width of text set at a font size, in points
+func (f *pdfFont) textWidth(text string, size float64) float64 {
+	total := 0.0
+	for _, r := range text {
+		gid, _ := f.glyph(r)
+		total += f.width(gid)
+	}
+	return total * size / pdfFontUnits
+}
+
+// encode returns text as a hex string of glyph IDs, recording the glyphs
+// used for the width array and the ToUnicode map
+func (f *pdfFont) encode(text string) string {
+	var sb strings.Builder
+	sb.WriteByte('<')
+	for _, r := range text {
+		gid, shown := f.glyph(r)
+		f.width(gid)
+		if _, ok := f.unicode[gid]; !ok {
+			f.unicode[gid] = shown
+		}
+		fmt.Fprintf(&sb, "%04X", uint16(gid))
+	}
+	sb.WriteByte('>')
+	return sb.String()
+}
+
+// usedGlyphs returns the glyphs written with the font, in order
+func (f *pdfFont) usedGlyphs() []sfnt.GlyphIndex {
+	gids := make([]sfnt.GlyphIndex, 0, len(f.unicode))
+	for gid := range f.unicode {
+		gids = append(gids, gid)
+	}
+	sort.Slice(gids, func(i, j int) bool { return gids[i] < gids[j] })
+	return gids
+}
+
+// toUnicodeCMap maps the glyphs written back to their characters
+func (f *pdfFont) toUnicodeCMap() []byte {
+	var sb strings.Builder
+	sb.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	sb.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	sb.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	sb.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	gids := f.usedGlyphs()
+	// A bfchar block holds at most 100 entries
+	for start := 0; start < len(gids); start += 100 {
+		end := start + 100
+		if end > len(gids) {
+			end = len(gids)
+		}
+		fmt.Fprintf(&sb, "%d beginbfchar\n", end-start)
+		for _, gid := range gids[start:end] {
+			fmt.Fprintf(&sb, "<%04X> <", uint16(gid))
+			for _, unit := range utf16.Encode([]rune{f.unicode[gid]}) {
+				fmt.Fprintf(&sb, "%04X", unit)
+			}
+			sb.WriteString(">\n")
+		}
+		sb.WriteString("endbfchar\n")
+	}
+
+	sb.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return []byte(sb.String())
+}
+
+// widthArray returns the /W array of the glyphs written
+func (f *pdfFont) widthArray() string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for _, gid := range f.usedGlyphs() {
+		fmt.Fprintf(&sb, "%d [%s] ", gid, pdfNumber(f.widths[gid]))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// pdfDocument collects the pages of a PDF and writes the file
+type pdfDocument struct {
+	width, height float64 // Page size in points
+	title         string
+	author        string
+	fonts         []*pdfFont
+	pages         []*bytes.Buffer // Content streams
+}
+
+// addFont registers a font for use on the pages
+func (d *pdfDocument) addFont(data []byte, italic bool) (*pdfFont, error) {
+	f, err := newPDFFont(fmt.Sprintf("F%d", len(d.fonts)+1), data, italic)
+	if err != nil {
+		return nil, err
+	}
+	d.fonts = append(d.fonts, f)
+	return f, nil
+}
+
+// addPage starts a new page and returns its content stream
+func (d *pdfDocument) addPage() *bytes.Buffer {
+	page := &bytes.Buffer{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// pdfText draws a line of text with its baseline at x, y
+func pdfText(page *bytes.Buffer, f *pdfFont, size, x, y float64, color pdfColor, text string) {
+	fmt.Fprintf(page, "BT /%s %s Tf %s rg %s %s Td %s Tj ET\n",
+		f.resource, pdfNumber(size), pdfColorOperands(color), pdfNumber(x), pdfNumber(y), f.encode(text))
+}
+
+// pdfLine draws a straight line
+func pdfLine(page *bytes.Buffer, x1, y1, x2, y2, width float64, color pdfColor) {
+	fmt.Fprintf(page, "%s RG %s w %s %s m %s %s l S\n",
+		pdfColorOperands(color), pdfNumber(width), pdfNumber(x1), pdfNumber(y1), pdfNumber(x2), pdfNumber(y2))
+}
+
+// pdfColorOperands formats a color for the rg and RG operators
+func pdfColorOperands(c pdfColor) string {
+	return pdfNumber(c[0]) + " " + pdfNumber(c[1]) + " " + pdfNumber(c[2])
+}
+
+// pdfNumber formats a number with at most three decimals
+func pdfNumber(v float64) string {
+	s := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", v), "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// pdfTextString encodes a string for the document information dictionary
+func pdfTextString(s string) string {
+	var sb strings.Builder
+	sb.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&sb, "%04X", unit)
+	}
+	sb.WriteByte('>')
+	return sb.String()
+}
+
+// pdfObjectWriter writes numbered objects and remembers their offsets for
+// the cross-reference table
+type pdfObjectWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+// alloc reserves an object number
+func (w *pdfObjectWriter) alloc() int {
+	w.offsets = append(w.offsets, 0)
+	return len(w.offsets)
+}
+
+// object writes a dictionary or other direct object
+func (w *pdfObjectWriter) object(n int, body string) {
+	w.offsets[n-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", n, body)
+}
+
+// stream writes a Flate-compressed stream; dict holds any entries besides
+// /Length and /Filter
+func (w *pdfObjectWriter) stream(n int, dict string, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	w.offsets[n-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< %s /Length %d /Filter /FlateDecode >>\nstream\n", n, dict, compressed.Len())
+	w.buf.Write(compressed.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+// bytes writes the complete PDF file
+func (d *pdfDocument) bytes() ([]byte, error) {
+	w := &pdfObjectWriter{}
+	w.buf.WriteString("%PDF-1.7\n%\xE2\xE3\xCF\xD3\n")
+
+	catalog, pages, info := w.alloc(), w.alloc(), w.alloc()
+
+	// Fonts without any text written are left out
+	type fontObjects struct{ type0, cidFont, descriptor, file, toUnicode int }
+	fontRefs := map[*pdfFont]fontObjects{}
+	var resources strings.Builder
+	for _, f := range d.fonts {
+		if len(f.unicode) == 0 {
+			continue
+		}
+		objects := fontObjects{w.alloc(), w.alloc(), w.alloc(), w.alloc(), w.alloc()}
+		fontRefs[f] = objects
+		fmt.Fprintf(&resources, "/%s %d 0 R ", f.resource, objects.type0)
+	}
+
+	pageRefs := make([]string, 0, len(d.pages))
+	for _, content := range d.pages {
+		page, stream := w.alloc(), w.alloc()
+		pageRefs = append(pageRefs, fmt.Sprintf("%d 0 R", page))
+		w.object(page, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			pages, pdfNumber(d.width), pdfNumber(d.height), resources.String(), stream))
+		if err := w.stream(stream, "", content.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range d.fonts {
+		objects, ok := fontRefs[f]
+		if !ok {
+			continue
+		}
+		w.object(objects.type0, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+			f.name, objects.cidFont, objects.toUnicode))
+		w.object(objects.cidFont, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /DW %d /W %s /CIDToGIDMap /Identity >>",
+			f.name, objects.descriptor, pdfFontUnits, f.widthArray()))
+
+		flags, italicAngle := 32, 0 // Nonsymbolic
+		if f.italic {
+			flags, italicAngle = flags|64, -12
+		}
+		w.object(objects.descriptor, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags %d /FontBBox [%s %s %s %s] /ItalicAngle %d /Ascent %s /Descent %s /CapHeight %s /StemV 80 /FontFile2 %d 0 R >>",
+			f.name, flags, pdfNumber(f.bbox[0]), pdfNumber(f.bbox[1]), pdfNumber(f.bbox[2]), pdfNumber(f.bbox[3]),
+			italicAngle, pdfNumber(f.ascent), pdfNumber(-f.descent), pdfNumber(f.capHeight), objects.file))
+		if err := w.stream(objects.file, fmt.Sprintf("/Length1 %d", len(f.data)), f.data); err != nil {
+			return nil, err
+		}
+		if err := w.stream(objects.toUnicode, "", f.toUnicodeCMap()); err != nil {
+			return nil, err
+		}
+	}
+
+	w.object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	w.object(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageRefs, " "), len(pageRefs)))
+	infoDict := "<< /Producer " + pdfTextString("Resume Optimizer")
+	if d.title != "" {
+		infoDict += " /Title " + pdfTextString(d.title)
+	}
+	if d.author != "" {
+		infoDict += " /Author " + pdfTextString(d.author)
+	}
+	w.object(info, infoDict+" >>")
+
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, catalog, info, xref)
+	return w.buf.Bytes(), nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Sources of prompt injection detections
+const (
+	InjectionSourceJobDescription = "job_description"
+	InjectionSourceModelOutput    = "model_output"
+)
+
+// Actions taken for a prompt injection detection
+const (
+	InjectionActionStripped = "stripped"
+	InjectionActionFlagged  = "flagged"
+)
+
+// InjectionDetection records instruction-like content found in untrusted
+// prompt input, or a model reply that no longer matches the expected schema
+type InjectionDetection struct {
+	Source  string `json:"source"`
+	Rule    string `json:"rule"`
+	Excerpt string `json:"excerpt"`
+	Action  string `json:"action"`
+}
+
+// injectionRule is a single heuristic for instruction-like content
+type injectionRule struct {
+	name    string
+	pattern *regexp.Regexp
+	strip   bool // Remove the offending line rather than just flagging it
+}
+
+var injectionRules = []injectionRule{
+	{"ignore_instructions", regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b.{0,40}\b(previous|prior|above|earlier|all|any|system)\b.{0,20}\b(instructions?|prompts?|rules|directions|context)\b`), true},
+	{"new_instructions", regexp.MustCompile(`(?i)\b(new|updated|real|actual)\s+(instructions?|system\s+prompt)\s*[:\-]`), true},
+	{"role_override", regexp.MustCompile(`(?i)\b(you are now|from now on,? you|pretend to be|roleplay as|act as an? (ai|assistant|chatbot|language model))\b`), true},
+	{"prompt_leak", regexp.MustCompile(`(?i)\b(reveal|print|show|repeat|output)\b.{0,30}\b(system prompt|your instructions|the prompt above)\b`), true},
+	{"chat_markup", regexp.MustCompile(`(?i)(<\|im_start\|>|<\|im_end\|>|<\|endoftext\|>|\[/?INST\]|<</?SYS>>|^\s*(system|assistant)\s*:)`), true},
+	{"output_override", regexp.MustCompile(`(?i)\b(respond|reply|answer|output)\s+(only\s+)?with\b|\bdo not (optimi[sz]e|follow)\b`), false},
+	{"hidden_candidate_instruction", regexp.MustCompile(`(?i)\b(if you are an? (ai|llm|language model|chatbot)|note to (ai|llm|the model))\b`), false},
+}
+
+// sentencePattern splits a line into sentences, keeping trailing punctuation and spaces
+var sentencePattern = regexp.MustCompile(`[^.!?]+(?:[.!?]+\s*|$)|[.!?]+\s*`)
+
+// untrustedEscaper neutralises angle brackets so untrusted text cannot open
+// or close the delimiters it is wrapped in
+var untrustedEscaper = strings.NewReplacer("<", "&lt;", ">", "&gt;")
+
+// PromptGuard hardens prompts built from untrusted text such as scraped or
+// pasted job descriptions
+type PromptGuard struct{}
+
+// NewPromptGuard creates a new PromptGuard instance
+func NewPromptGuard() *PromptGuard {
+	return &PromptGuard{}
+}
+
+// Sanitize scans untrusted text sentence by sentence. Sentences matching a
+// stripping rule are removed; sentences matching any other rule are kept but
+// reported. Scraped job descriptions are often a single long line, so
+// stripping whole lines would throw away most of the posting.
+func (pg *PromptGuard) Sanitize(source, text string) (string, []InjectionDetection) {
+	var detections []InjectionDetection
+	lines := strings.Split(text, "\n")
+
+	for i, line := range lines {
+		var kept strings.Builder
+		for _, sentence := range sentencePattern.FindAllString(line, -1) {
+			stripped := false
+			for _, rule := range injectionRules {
+				match := rule.pattern.FindString(sentence)
+				if match == "" {
+					continue
+				}
+				action := InjectionActionFlagged
+				if rule.strip {
+					action = InjectionActionStripped
+					stripped = true
+				}
+				detections = append(detections, InjectionDetection{
+					Source:  source,
+					Rule:    rule.name,
+					Excerpt: excerptAround(sentence, match, 160),
+					Action:  action,
+				})
+			}
+			if !stripped {
+				kept.WriteString(sentence)
+			}
+		}
+		lines[i] = kept.String()
+	}
+
+	return strings.Join(lines, "\n"), detections
+}
+
+// Scan reports instruction-like content without modifying the text
+func (pg *PromptGuard) Scan(source, text string) []InjectionDetection {
+	var detections []InjectionDetection
+	for _, rule := range injectionRules {
+		if match := rule.pattern.FindString(text); match != "" {
+			detections = append(detections, InjectionDetection{
+				Source:  source,
+				Rule:    rule.name,
+				Excerpt: excerptAround(text, match, 160),
+				Action:  InjectionActionFlagged,
+			})
+		}
+	}
+	return detections
+}
+
+// WrapUntrusted places untrusted text inside a named, escaped block that the
+// prompt tells the model to treat purely as data
+func (pg *PromptGuard) WrapUntrusted(name, text string) string {
+	return "<" + name + ">\n" + untrustedEscaper.Replace(text) + "\n</" + name + ">"
+}
+
+// CheckOptimizationOutput verifies that a parsed model reply still has the
+// shape the optimization prompt asked for
+func (pg *PromptGuard) CheckOptimizationOutput(resp *OptimizationResponse, schemaMatched bool) []InjectionDetection {
+	var detections []InjectionDetection
+	violation := func(rule, excerpt string) {
+		detections = append(detections, InjectionDetection{
+			Source:  InjectionSourceModelOutput,
+			Rule:    rule,
+			Excerpt: excerpt,
+			Action:  InjectionActionFlagged,
+		})
+	}
+
+	if !schemaMatched {
+		violation("schema_mismatch", "model reply was not the requested JSON object")
+	}
+	if strings.TrimSpace(resp.OptimizedContent) == "" {
+		violation("empty_optimized_content", "optimized_content is missing or empty")
+	}
+	if len(resp.Changes) == 0 {
+		violation("missing_changes", "changes list is missing or empty")
+	}
+
+	return append(detections, pg.Scan(InjectionSourceModelOutput, resp.OptimizedContent)...)
+}
+
+// excerptAround returns up to limit bytes of text centred on match
+func excerptAround(text, match string, limit int) string {
+	idx := strings.Index(text, match)
+	if idx == -1 || len(text) <= limit {
+		return strings.TrimSpace(truncateRunes(text, limit))
+	}
+	start := max(0, idx-(limit-len(match))/2)
+	for start < len(text) && !isRuneStart(text[start]) {
+		start++
+	}
+	return strings.TrimSpace(truncateRunes(text[start:], limit))
+}
+
+// truncateRunes shortens s to at most limit bytes without splitting a UTF-8 sequence
+func truncateRunes(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit
+	for cut > 0 && !isRuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
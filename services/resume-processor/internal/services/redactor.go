@@ -0,0 +1,191 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PII categories used in redaction placeholders
+const (
+	PIIName    = "NAME"
+	PIIEmail   = "EMAIL"
+	PIIPhone   = "PHONE"
+	PIIAddress = "ADDRESS"
+	PIIURL     = "URL"
+)
+
+var (
+	emailPIIPattern   = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	urlPIIPattern     = regexp.MustCompile(`(?i)\b(?:https?://)?(?:www\.)?(?:linkedin\.com|github\.com|gitlab\.com|twitter\.com|x\.com)/[^\s,;)]+|\bhttps?://[^\s,;)]+`)
+	phonePIIPattern   = regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?\(?\d{2,4}\)?[\s.-]?\d{3,4}[\s.-]?\d{3,4}\b`)
+	addressPIIPattern = regexp.MustCompile(`\b\d{1,5}\s+(?:[A-Z][A-Za-z]*\.?\s+){1,4}(?:Street|St|Avenue|Ave|Road|Rd|Boulevard|Blvd|Lane|Ln|Drive|Dr|Court|Ct|Way|Place|Pl|Terrace|Crescent)\b\.?(?:,?\s*(?:Apt|Suite|Unit)\.?\s*\w+)?(?:,\s*[A-Z][A-Za-z]+(?:\s[A-Z][A-Za-z]+)*)?(?:,\s*[A-Z]{2})?(?:\s+\d{5}(?:-\d{4})?|\s+[A-Z]\d[A-Z]\s?\d[A-Z]\d)?`)
+	namePIIPattern    = regexp.MustCompile(`^\p{Lu}[\p{L}'-]+(?:\s+\p{Lu}[\p{L}'.-]*){1,3}$`)
+	placeholderRegex  = regexp.MustCompile(`\[\[(?:NAME|EMAIL|PHONE|ADDRESS|URL)_\d+\]\]`)
+)
+
+// RedactionMap maps placeholders back to the values they replaced. It
+// deliberately hides its contents from fmt and encoding/json so that it
+// cannot end up in logs or API responses by accident.
+type RedactionMap struct {
+	values map[string]string // placeholder -> original
+	index  map[string]string // original -> placeholder
+	counts map[string]int    // category -> number of distinct values
+}
+
+func newRedactionMap() *RedactionMap {
+	return &RedactionMap{
+		values: make(map[string]string),
+		index:  make(map[string]string),
+		counts: make(map[string]int),
+	}
+}
+
+// placeholderFor returns the stable placeholder for a value, allocating one on first use
+func (m *RedactionMap) placeholderFor(category, value string) string {
+	key := category + "\x00" + value
+	if placeholder, ok := m.index[key]; ok {
+		return placeholder
+	}
+	m.counts[category]++
+	placeholder := fmt.Sprintf("[[%s_%d]]", category, m.counts[category])
+	m.index[key] = placeholder
+	m.values[placeholder] = value
+	return placeholder
+}
+
+// Len returns the number of redacted values
+func (m *RedactionMap) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.values)
+}
+
+// Counts returns the number of redacted values per category
+func (m *RedactionMap) Counts() map[string]int {
+	counts := make(map[string]int)
+	if m == nil {
+		return counts
+	}
+	for category, count := range m.counts {
+		counts[category] = count
+	}
+	return counts
+}
+
+// String implements fmt.Stringer without revealing any redacted value
+func (m *RedactionMap) String() string {
+	return fmt.Sprintf("RedactionMap(%d values)", m.Len())
+}
+
+// GoString implements fmt.GoStringer without revealing any redacted value
+func (m *RedactionMap) GoString() string {
+	return m.String()
+}
+
+// MarshalJSON only exposes per-category counts
+func (m *RedactionMap) MarshalJSON() ([]byte, error) {
+	counts := m.Counts()
+	var parts []string
+	for _, category := range []string{PIIName, PIIEmail, PIIPhone, PIIAddress, PIIURL} {
+		if counts[category] > 0 {
+			parts = append(parts, fmt.Sprintf("%q:%d", strings.ToLower(category), counts[category]))
+		}
+	}
+	return []byte("{" + strings.Join(parts, ",") + "}"), nil
+}
+
+// PIIRedactor replaces personally identifiable information in resume text
+// with stable placeholders before it is sent to a third-party model
+type PIIRedactor struct{}
+
+// NewPIIRedactor creates a new PIIRedactor instance
+func NewPIIRedactor() *PIIRedactor {
+	return &PIIRedactor{}
+}
+
+// Redact replaces detected PII with placeholders such as [[EMAIL_1]]. The
+// same value always maps to the same placeholder within one call.
+func (r *PIIRedactor) Redact(text string) (string, *RedactionMap) {
+	redactions := newRedactionMap()
+
+	// The name is detected before anything else is replaced, then every
+	// occurrence of it is swapped out after the structured patterns
+	name := r.detectName(text)
+
+	// Emails go first so the URL and phone patterns never see their parts
+	text = r.replacePattern(text, emailPIIPattern, PIIEmail, redactions)
+	text = r.replacePattern(text, urlPIIPattern, PIIURL, redactions)
+	text = r.replacePattern(text, addressPIIPattern, PIIAddress, redactions)
+	text = phonePIIPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if !r.looksLikePhone(match) {
+			return match
+		}
+		return redactions.placeholderFor(PIIPhone, match)
+	})
+
+	if name != "" {
+		text = strings.ReplaceAll(text, name, redactions.placeholderFor(PIIName, name))
+	}
+
+	return text, redactions
+}
+
+// Restore puts the original values back in place of their placeholders.
+// Placeholders the model invented or mangled are left untouched.
+func (r *PIIRedactor) Restore(text string, redactions *RedactionMap) string {
+	if redactions.Len() == 0 {
+		return text
+	}
+	return placeholderRegex.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if original, ok := redactions.values[placeholder]; ok {
+			return original
+		}
+		return placeholder
+	})
+}
+
+// replacePattern replaces every match of pattern with its placeholder
+func (r *PIIRedactor) replacePattern(text string, pattern *regexp.Regexp, category string, redactions *RedactionMap) string {
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if placeholderRegex.MatchString(match) {
+			return match
+		}
+		return redactions.placeholderFor(category, match)
+	})
+}
+
+// looksLikePhone filters out year ranges such as "2019 - 2021" and other
+// short digit runs the phone pattern picks up
+func (r *PIIRedactor) looksLikePhone(match string) bool {
+	digits := 0
+	for _, ch := range match {
+		if ch >= '0' && ch <= '9' {
+			digits++
+		}
+	}
+	return digits >= 9 && digits <= 15
+}
+
+// detectName returns the candidate's name if the first non-empty line of the
+// resume looks like one
+func (r *PIIRedactor) detectName(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// Names are often followed by contact details on the same line
+		for _, sep := range []string{"|", "•", " - ", ","} {
+			if idx := strings.Index(line, sep); idx > 0 {
+				line = strings.TrimSpace(line[:idx])
+			}
+		}
+		if len(line) <= 50 && namePIIPattern.MatchString(line) && !localizedSectionHeaders[strings.ToLower(line)] {
+			return line
+		}
+		return ""
+	}
+	return ""
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Kinds of resume blocks
+const (
+	ResumeBlockName      = "name"      // The candidate's name
+	ResumeBlockLabel     = "label"     // Headline under the name, e.g. "Senior Engineer"
+	ResumeBlockContact   = "contact"   // Contact details
+	ResumeBlockHeading   = "heading"   // Section heading
+	ResumeBlockEntry     = "entry"     // Title of a position, degree or project, with its dates as Detail
+	ResumeBlockParagraph = "paragraph" // Body text, with an optional bold Label such as "Languages"
+	ResumeBlockBullet    = "bullet"    // Bullet point
+)
+
+// ResumeBlock is one element of a resume laid out for rendering
+type ResumeBlock struct {
+	Kind   string `json:"kind"`
+	Text   string `json:"text"`
+	Detail string `json:"detail,omitempty"` // Dates or location shown beside an entry
+	Label  string `json:"label,omitempty"`  // Lead-in of a paragraph
+}
+
+// ResumeDocument is a resume as a sequence of blocks, the common input of
+// the PDF and other document renderers
+type ResumeDocument struct {
+	Name   string        `json:"name"` // Used as the document title
+	Blocks []ResumeBlock `json:"blocks"`
+}
+
+// resumeContactHint matches lines of the header that hold contact details
+var resumeContactHint = regexp.MustCompile(`@|https?://|www\.|linkedin|github|\+?\d[\d\s().-]{6,}\d|\|`)
+
+// ResumeDocumentFromJSONResume lays out a structured resume
+func ResumeDocumentFromJSONResume(r *JSONResume) *ResumeDocument {
+	doc := &ResumeDocument{Name: r.Basics.Name}
+	add := func(kind, text, detail, label string) {
+		if strings.TrimSpace(text) == "" && label == "" {
+			return
+		}
+		doc.Blocks = append(doc.Blocks, ResumeBlock{Kind: kind, Text: strings.TrimSpace(text), Detail: detail, Label: label})
+	}
+	bullets := func(items []string) {
+		for _, item := range items {
+			add(ResumeBlockBullet, item, "", "")
+		}
+	}
+
+	b := r.Basics
+	add(ResumeBlockName, b.Name, "", "")
+	add(ResumeBlockLabel, b.Label, "", "")
+	contact := []string{}
+	if b.Location != nil {
+		contact = append(contact, joinNonEmpty([]string{b.Location.City, b.Location.Region}, ", "))
+	}
+	contact = append(contact, b.Email, b.Phone, b.URL)
+	for _, profile := range b.Profiles {
+		contact = append(contact, profile.URL)
+	}
+	add(ResumeBlockContact, joinNonEmpty(contact, " | "), "", "")
+
+	if b.Summary != "" {
+		add(ResumeBlockHeading, "Summary", "", "")
+		add(ResumeBlockParagraph, b.Summary, "", "")
+	}
+	if len(r.Work) > 0 {
+		add(ResumeBlockHeading, "Experience", "", "")
+		for _, work := range r.Work {
+			add(ResumeBlockEntry, joinNonEmpty([]string{work.Position, work.Name}, ", "),
+				joinNonEmpty([]string{work.Location, jsonResumeDateRange(work.StartDate, work.EndDate)}, " | "), "")
+			add(ResumeBlockParagraph, work.Summary, "", "")
+			bullets(work.Highlights)
+		}
+	}
+	if len(r.Education) > 0 {
+		add(ResumeBlockHeading, "Education", "", "")
+		for _, education := range r.Education {
+			degree := joinNonEmpty([]string{education.StudyType, education.Area}, " ")
+			add(ResumeBlockEntry, joinNonEmpty([]string{degree, education.Institution}, ", "), jsonResumeDateRange(education.StartDate, education.EndDate), "")
+			if education.Score != "" {
+				add(ResumeBlockParagraph, education.Score, "", "Score")
+			}
+			bullets(education.Courses)
+		}
+	}
+	if len(r.Skills) > 0 {
+		add(ResumeBlockHeading, "Skills", "", "")
+		for _, skill := range r.Skills {
+			if len(skill.Keywords) == 0 {
+				add(ResumeBlockParagraph, skill.Name, "", "")
+				continue
+			}
+			add(ResumeBlockParagraph, strings.Join(skill.Keywords, ", "), "", skill.Name)
+		}
+	}
+	if len(r.Projects) > 0 {
+		add(ResumeBlockHeading, "Projects", "", "")
+		for _, project := range r.Projects {
+			add(ResumeBlockEntry, project.Name, jsonResumeDateRange(project.StartDate, project.EndDate), "")
+			add(ResumeBlockParagraph, project.Description, "", "")
+			bullets(project.Highlights)
+			add(ResumeBlockParagraph, project.URL, "", "")
+		}
+	}
+	if len(r.Volunteer) > 0 {
+		add(ResumeBlockHeading, "Volunteer Experience", "", "")
+		for _, volunteer := range r.Volunteer {
+			add(ResumeBlockEntry, joinNonEmpty([]string{volunteer.Position, volunteer.Organization}, ", "), jsonResumeDateRange(volunteer.StartDate, volunteer.EndDate), "")
+			add(ResumeBlockParagraph, volunteer.Summary, "", "")
+			bullets(volunteer.Highlights)
+		}
+	}
+	if len(r.Certificates) > 0 {
+		add(ResumeBlockHeading, "Certifications", "", "")
+		for _, certificate := range r.Certificates {
+			add(ResumeBlockBullet, joinNonEmpty([]string{certificate.Name, certificate.Issuer, formatJSONResumeDate(certificate.Date)}, " | "), "", "")
+		}
+	}
+	if len(r.Awards) > 0 {
+		add(ResumeBlockHeading, "Awards", "", "")
+		for _, award := range r.Awards {
+			add(ResumeBlockEntry, joinNonEmpty([]string{award.Title, award.Awarder}, ", "), formatJSONResumeDate(award.Date), "")
+			add(ResumeBlockParagraph, award.Summary, "", "")
+		}
+	}
+	if len(r.Languages) > 0 {
+		add(ResumeBlockHeading, "Languages", "", "")
+		languages := make([]string, 0, len(r.Languages))
+		for _, language := range r.Languages {
+			if language.Fluency != "" {
+				languages = append(languages, language.Language+" ("+language.Fluency+")")
+			} else {
+				languages = append(languages, language.Language)
+			}
+		}
+		add(ResumeBlockParagraph, strings.Join(languages, ", "), "", "")
+	}
+
+	return doc
+}
+
+// ResumeDocumentFromText lays out resume text such as an optimization's
+// output. The lines before the first section heading are read as the name,
+// headline and contact details; dated lines become entries and bullet
+// points are kept. No content is dropped.
+func ResumeDocumentFromText(text string) *ResumeDocument {
+	doc := &ResumeDocument{}
+	lines := strings.Split(text, "\n")
+
+	entriesByLine := map[int]TimelineEntry{}
+	for _, entry := range NewTimelineAnalyzer().Analyze(text).Entries {
+		if _, taken := entriesByLine[entry.Line]; !taken {
+			entriesByLine[entry.Line] = entry
+		}
+	}
+
+	inHeader := true
+	section := ""
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if heading, kind, ok := resumeHeading(line, doc.Name != ""); ok {
+			doc.Blocks = append(doc.Blocks, ResumeBlock{Kind: ResumeBlockHeading, Text: heading})
+			inHeader, section = false, kind
+			continue
+		}
+
+		if inHeader {
+			switch {
+			case doc.Name == "":
+				doc.Name = line
+				doc.Blocks = append(doc.Blocks, ResumeBlock{Kind: ResumeBlockName, Text: line})
+			case resumeContactHint.MatchString(line):
+				doc.Blocks = append(doc.Blocks, ResumeBlock{Kind: ResumeBlockContact, Text: line})
+			case len(doc.Blocks) == 1:
+				doc.Blocks = append(doc.Blocks, ResumeBlock{Kind: ResumeBlockLabel, Text: line})
+			default:
+				doc.Blocks = append(doc.Blocks, ResumeBlock{Kind: ResumeBlockParagraph, Text: line})
+			}
+			continue
+		}
+
+		if jsonResumeBullet.MatchString(line) {
+			doc.Blocks = append(doc.Blocks, ResumeBlock{Kind: ResumeBlockBullet, Text: strings.TrimSpace(jsonResumeBullet.ReplaceAllString(line, ""))})
+			continue
+		}
+
+		if entry, dated := entriesByLine[i+1]; dated && strings.Contains(line, entry.Raw) {
+			title := strings.Trim(strings.Replace(line, entry.Raw, "", 1), " \t|,–—-()")
+			last := len(doc.Blocks) - 1
+			if title == "" && last >= 0 && doc.Blocks[last].Kind == ResumeBlockParagraph && doc.Blocks[last].Label == "" {
+				// Dates on their own line belong to the title above them
+				doc.Blocks[last].Kind, doc.Blocks[last].Detail = ResumeBlockEntry, entry.Raw
+				continue
+			}
+			doc.Blocks = append(doc.Blocks, ResumeBlock{Kind: ResumeBlockEntry, Text: title, Detail: entry.Raw})
+			continue
+		}
+
+		if section == "skills" {
+			if idx := strings.Index(line, ":"); idx > 0 && idx <= 40 {
+				doc.Blocks = append(doc.Blocks, ResumeBlock{Kind: ResumeBlockParagraph, Label: strings.TrimSpace(line[:idx]), Text: strings.TrimSpace(line[idx+1:])})
+				continue
+			}
+		}
+		doc.Blocks = append(doc.Blocks, ResumeBlock{Kind: ResumeBlockParagraph, Text: line})
+	}
+
+	return doc
+}
+
+// resumeHeading reports whether a line is a section heading, returning the
+// heading and the JSON Resume section it fills, if any. Short all-caps lines
+// count as headings once the name has been read.
+func resumeHeading(line string, afterName bool) (string, string, bool) {
+	heading := strings.TrimSpace(strings.TrimSuffix(line, ":"))
+	lower := strings.ToLower(heading)
+	if section, ok := jsonResumeSectionHeaders[lower]; ok {
+		return heading, section, true
+	}
+	if localizedSectionHeaders[lower] {
+		return heading, "", true
+	}
+	if kind, ok := timelineSection(heading); ok && len(strings.Fields(heading)) <= 4 {
+		return heading, map[string]string{TimelineSectionExperience: "work", TimelineSectionEducation: "education"}[kind], true
+	}
+
+	if !afterName || len(heading) > 40 || len(strings.Fields(heading)) > 4 || resumeContactHint.MatchString(heading) {
+		return "", "", false
+	}
+	hasLetter := false
+	for _, r := range heading {
+		switch {
+		case unicode.IsDigit(r) || unicode.IsLower(r):
+			return "", "", false
+		case unicode.IsLetter(r):
+			hasLetter = true
+		}
+	}
+	return heading, "", hasLetter
+}
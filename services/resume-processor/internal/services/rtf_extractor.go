@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// rtfSkippedDestinations hold formatting tables and metadata rather than
+// document text
+var rtfSkippedDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true,
+	"pict": true, "object": true, "fldinst": true, "listtable": true,
+	"listoverridetable": true, "rsidtbl": true, "xmlnstbl": true,
+	"generator": true, "themedata": true, "colorschememapping": true,
+	"datastore": true, "latentstyles": true, "pgdsctbl": true,
+	"header": true, "headerl": true, "headerr": true, "headerf": true,
+	"footer": true, "footerl": true, "footerr": true, "footerf": true,
+	"bkmkstart": true, "bkmkend": true, "revtbl": true, "filetbl": true,
+}
+
+// rtfSymbols maps control words that stand for a single character
+var rtfSymbols = map[string]string{
+	"tab": "\t", "emdash": "—", "endash": "–", "bullet": "•",
+	"lquote": "‘", "rquote": "’", "ldblquote": "“", "rdblquote": "”",
+	"emspace": " ", "enspace": " ", "qmspace": " ",
+}
+
+// rtfCodePages maps \ansicpg values to their decoders; anything else is
+// read as Windows-1252, the RTF default
+var rtfCodePages = map[int]*charmap.Charmap{
+	1250: charmap.Windows1250,
+	1251: charmap.Windows1251,
+	1252: charmap.Windows1252,
+	1253: charmap.Windows1253,
+	1254: charmap.Windows1254,
+	1257: charmap.Windows1257,
+}
+
+// rtfGroup is the state scoped to a {...} group
+type rtfGroup struct {
+	skip     bool
+	listText bool // \listtext or \pntext: the rendered list marker of a paragraph
+	ucSkip   int  // Fallback characters that follow each \u escape
+}
+
+// rtfParser converts RTF to plain text
+type rtfParser struct {
+	src      string
+	pos      int
+	groups   []rtfGroup
+	codePage *charmap.Charmap
+
+	lines     []string
+	line      strings.Builder
+	marker    strings.Builder
+	cells     []string
+	inTable   bool
+	skipChars int // Fallback characters still to drop after a \u escape
+}
+
+// extractFromRTF extracts text from an RTF document, keeping paragraphs,
+// list markers and table rows
+func (te *TextExtractor) extractFromRTF(ctx context.Context, filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read RTF file: %v", err)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(content)), `{\rtf`) {
+		return "", fmt.Errorf("failed to parse RTF: missing {\\rtf header")
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	p := &rtfParser{
+		src:      string(content),
+		groups:   []rtfGroup{{ucSkip: 1}},
+		codePage: charmap.Windows1252,
+	}
+	p.parse()
+
+	text := te.normalizeStructure(strings.Join(p.lines, "\n"))
+	return te.finalCleanup(text), nil
+}
+
+// group returns the innermost open group
+func (p *rtfParser) group() *rtfGroup {
+	return &p.groups[len(p.groups)-1]
+}
+
+// parse walks the document once, writing text of non-skipped groups
+func (p *rtfParser) parse() {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case '{':
+			p.groups = append(p.groups, *p.group())
+			p.pos++
+		case '}':
+			p.endGroup()
+			p.pos++
+		case '\\':
+			p.controlWord()
+		case '\r', '\n':
+			p.pos++
+		default:
+			p.pos++
+			p.writeByte(c)
+		}
+	}
+	p.endParagraph()
+}
+
+// endGroup closes a group, turning a finished list marker into a bullet
+func (p *rtfParser) endGroup() {
+	closing := *p.group()
+	if len(p.groups) > 1 {
+		p.groups = p.groups[:len(p.groups)-1]
+	}
+
+	if closing.listText && !p.group().listText {
+		// Bullets are symbol-font glyphs that decode to stray characters;
+		// numbered markers ("1.", "a)") are kept as written
+		marker := strings.TrimSpace(p.marker.String())
+		p.marker.Reset()
+		if strings.IndexFunc(marker, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			marker = "•"
+		}
+		p.line.WriteString(marker + " ")
+	}
+}
+
+// controlWord handles the control word or symbol at the current backslash
+func (p *rtfParser) controlWord() {
+	p.pos++ // Backslash
+	if p.pos >= len(p.src) {
+		return
+	}
+
+	c := p.src[p.pos]
+	if !isASCIILetter(c) {
+		p.pos++
+		switch c {
+		case '\\', '{', '}':
+			p.writeByte(c)
+		case '\'':
+			if p.pos+2 <= len(p.src) {
+				if b, err := strconv.ParseUint(p.src[p.pos:p.pos+2], 16, 8); err == nil {
+					p.writeByte(byte(b))
+				}
+				p.pos += 2
+			}
+		case '~':
+			p.writeString(" ")
+		case '_':
+			p.writeString("-")
+		case '*':
+			// Unknown destinations are marked with \* and must be ignored
+			p.group().skip = true
+		case '\n', '\r':
+			p.paragraph()
+		}
+		return
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) && isASCIILetter(p.src[p.pos]) {
+		p.pos++
+	}
+	word := p.src[start:p.pos]
+
+	paramStart := p.pos
+	if p.pos < len(p.src) && p.src[p.pos] == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	param, hasParam := 0, p.pos > paramStart
+	if hasParam {
+		param, _ = strconv.Atoi(p.src[paramStart:p.pos])
+	}
+	// A single space delimits the control word and is not part of the text
+	if p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+
+	switch {
+	case rtfSkippedDestinations[word]:
+		p.group().skip = true
+	case word == "listtext" || word == "pntext":
+		p.group().listText = true
+	case word == "bin" && hasParam:
+		p.pos += max(param, 0)
+	case word == "ansicpg":
+		if cp, ok := rtfCodePages[param]; ok {
+			p.codePage = cp
+		}
+	case word == "uc":
+		p.group().ucSkip = param
+	case word == "u":
+		if param < 0 {
+			param += 65536
+		}
+		p.writeString(string(rune(param)))
+		p.skipChars = p.group().ucSkip
+		return
+	case word == "par" || word == "line" || word == "sect" || word == "page":
+		p.paragraph()
+	case word == "pard":
+		p.inTable = false
+	case word == "intbl":
+		p.inTable = true
+	case word == "cell" || word == "nestcell":
+		p.cells = append(p.cells, strings.TrimSpace(p.line.String()))
+		p.line.Reset()
+	case word == "row" || word == "nestrow":
+		p.lines = append(p.lines, joinTableRow(p.cells))
+		p.cells = nil
+		p.line.Reset()
+	default:
+		if symbol, ok := rtfSymbols[word]; ok {
+			p.writeString(symbol)
+		}
+	}
+}
+
+// paragraph ends the current paragraph; inside a table cell it only separates text
+func (p *rtfParser) paragraph() {
+	if p.group().skip {
+		return
+	}
+	if p.inTable {
+		p.line.WriteString(" ")
+		return
+	}
+	p.endParagraph()
+}
+
+// endParagraph flushes the current line
+func (p *rtfParser) endParagraph() {
+	p.lines = append(p.lines, strings.TrimSpace(p.line.String()))
+	p.line.Reset()
+}
+
+// writeByte writes a character in the document code page
+func (p *rtfParser) writeByte(b byte) {
+	if b < 0x80 {
+		p.writeString(string(rune(b)))
+		return
+	}
+	p.writeString(string(p.codePage.DecodeByte(b)))
+}
+
+// writeString writes text to the current line, honouring skipped groups and
+// the fallback characters of a preceding \u escape
+func (p *rtfParser) writeString(s string) {
+	if p.skipChars > 0 {
+		p.skipChars--
+		return
+	}
+
+	group := p.group()
+	switch {
+	case group.skip:
+	case group.listText:
+		p.marker.WriteString(s)
+	default:
+		p.line.WriteString(s)
+	}
+}
+
+// isASCIILetter reports whether b is an ASCII letter, the only characters
+// allowed in an RTF control word
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractFromRTFFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    string
+	}{
+		{
+			// \'hh bytes are decoded in the document code page; \uN escapes
+			// may be negative and drop the \ucN fallback characters after
+			// them; the font table and info group are not text
+			fixture: "escapes.rtf",
+			want: "José Müller\n" +
+				"Café – München\n" +
+				"“Quoted” and 안녕\n" +
+				"東京 Tokyo\n" +
+				"Braces { and } and a backslash \\",
+		},
+		{
+			// \ansicpg selects the code page; \* destinations are skipped
+			fixture: "codepage.rtf",
+			want: "Привет\n" +
+				"Line after generator",
+		},
+		{
+			// Symbol-font list markers become bullets, numbered markers are
+			// kept, and table cells are joined into one line per row
+			fixture: "lists.rtf",
+			want: "Highlights\n" +
+				"• Cut deploy time by 40%\n" +
+				"2. Owned the on-call rotation\n" +
+				"Acme Corp | 2019—2024\n" +
+				"After the table",
+		},
+	}
+
+	te := NewTextExtractor()
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := te.extractFromRTF(context.Background(), filepath.Join("testdata", "rtf", tt.fixture))
+			if err != nil {
+				t.Fatalf("extractFromRTF: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("extracted text\n got: %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of the fetch policy
+const (
+	defaultFetchMaxBody      = 5 << 20 // Bytes
+	defaultFetchMaxRedirects = 5
+	defaultFetchTimeout      = 10 * time.Second
+)
+
+// ErrFetchBlocked is returned when a URL or a response breaks the fetch
+// policy: a disallowed scheme or host, an address on an internal network, a
+// body that is too large or an unexpected content type
+var ErrFetchBlocked = errors.New("fetch blocked")
+
+// blockedPrefixes are the address ranges user-supplied URLs may not reach,
+// besides the loopback, private, link-local, multicast and unspecified
+// addresses the netip predicates cover
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // Documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // Benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // Documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // Documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // Reserved and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, which embeds IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // Local-use NAT64
+	netip.MustParsePrefix("100::/64"),        // Discard
+	netip.MustParsePrefix("2001:db8::/32"),   // Documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, which embeds IPv4 addresses
+}
+
+// defaultBlockedHosts are rejected whatever FETCH_BLOCKED_HOSTS says
+var defaultBlockedHosts = []string{"localhost", "metadata.google.internal"}
+
+// FetchPolicy limits what a client made by NewSafeHTTPClient may fetch
+type FetchPolicy struct {
+	Schemes      []string // Allowed URL schemes
+	AllowedHosts []string // When set, only these hosts and their subdomains are fetched
+	BlockedHosts []string // Never fetched, nor their subdomains
+	ContentTypes []string // Media types successful responses may have
+	MaxBodyBytes int64
+	MaxRedirects int
+	Timeout      time.Duration
+	// AllowPrivateNetworks turns off the address checks, for tests against
+	// local servers
+	AllowPrivateNetworks bool
+}
+
+// DefaultFetchPolicy returns the policy job pages and APIs are fetched with.
+// FETCH_ALLOWED_HOSTS and FETCH_BLOCKED_HOSTS take comma-separated host
+// names; FETCH_MAX_BODY_BYTES caps response bodies.
+func DefaultFetchPolicy() FetchPolicy {
+	policy := FetchPolicy{
+		Schemes:      []string{"http", "https"},
+		AllowedHosts: splitHostList(os.Getenv("FETCH_ALLOWED_HOSTS")),
+		BlockedHosts: append(append([]string{}, defaultBlockedHosts...), splitHostList(os.Getenv("FETCH_BLOCKED_HOSTS"))...),
+		ContentTypes: []string{"text/html", "application/xhtml+xml", "text/plain", "application/json", "application/ld+json"},
+		MaxBodyBytes: defaultFetchMaxBody,
+		MaxRedirects: defaultFetchMaxRedirects,
+		Timeout:      defaultFetchTimeout,
+	}
+	if n, err := strconv.ParseInt(os.Getenv("FETCH_MAX_BODY_BYTES"), 10, 64); err == nil && n > 0 {
+		policy.MaxBodyBytes = n
+	}
+	return policy
+}
+
+// splitHostList parses a comma-separated list of host names
+func splitHostList(list string) []string {
+	var hosts []string
+	for _, host := range strings.Split(list, ",") {
+		if host = normalizeHost(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// normalizeHost lowercases a host name and drops its trailing dot
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// NewSafeHTTPClient returns a client for user-supplied URLs. Every request,
+// including each redirect, is checked against the policy; host names are
+// resolved and checked on every redirect and again when dialing, and the
+// connection goes to the checked address, so DNS rebinding cannot slip an
+// internal address in between. Proxies are not used, as they would dial for
+// us. Violations are reported as errors wrapping ErrFetchBlocked.
+func NewSafeHTTPClient(policy FetchPolicy) *http.Client {
+	return newFetchGuard(policy).client()
+}
+
+// CheckFetchURL checks a URL against the policy before it is fetched,
+// resolving its host. The client checks every request again, as redirects
+// and later lookups may lead elsewhere.
+func CheckFetchURL(ctx context.Context, policy FetchPolicy, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fetchBlocked("invalid URL")
+	}
+	guard := newFetchGuard(policy)
+	if err := guard.checkURL(u); err != nil {
+		return err
+	}
+	_, err = guard.resolve(ctx, normalizeHost(u.Hostname()))
+	return err
+}
+
+// fetchGuard enforces a fetch policy
+type fetchGuard struct {
+	policy   FetchPolicy
+	resolver *net.Resolver
+	dialer   *net.Dialer
+	internal func(addr netip.Addr) bool // Reports addresses that may not be reached
+}
+
+// newFetchGuard returns a guard for a policy
+func newFetchGuard(policy FetchPolicy) *fetchGuard {
+	internal := isInternalAddr
+	if policy.AllowPrivateNetworks {
+		internal = func(netip.Addr) bool { return false }
+	}
+	return &fetchGuard{
+		policy:   policy,
+		resolver: net.DefaultResolver,
+		dialer:   &net.Dialer{Timeout: policy.Timeout, KeepAlive: 30 * time.Second},
+		internal: internal,
+	}
+}
+
+// client returns an HTTP client that fetches under the guard
+func (g *fetchGuard) client() *http.Client {
+	return &http.Client{
+		Timeout: g.policy.Timeout,
+		Transport: &guardedTransport{
+			guard: g,
+			next: &http.Transport{
+				Proxy:                 nil,
+				DialContext:           g.dialContext,
+				ForceAttemptHTTP2:     true,
+				MaxIdleConns:          10,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   g.policy.Timeout,
+				ResponseHeaderTimeout: g.policy.Timeout,
+			},
+		},
+		CheckRedirect: g.checkRedirect,
+	}
+}
+
+// fetchBlocked returns an error wrapping ErrFetchBlocked
+func fetchBlocked(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrFetchBlocked, fmt.Sprintf(format, args...))
+}
+
+// checkURL checks the scheme and host of a URL, without resolving it
+func (g *fetchGuard) checkURL(u *url.URL) error {
+	scheme := strings.ToLower(u.Scheme)
+	if !containsString(g.policy.Schemes, scheme) {
+		return fetchBlocked("scheme %q is not allowed", u.Scheme)
+	}
+	if u.User != nil {
+		return fetchBlocked("URLs with credentials are not allowed")
+	}
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return fetchBlocked("URL has no host")
+	}
+	if matchHostList(g.policy.BlockedHosts, host) {
+		return fetchBlocked("host %s is blocked", host)
+	}
+	if len(g.policy.AllowedHosts) > 0 && !matchHostList(g.policy.AllowedHosts, host) {
+		return fetchBlocked("host %s is not on the allow list", host)
+	}
+	return nil
+}
+
+// matchHostList reports whether a host or one of its parent domains is listed
+func matchHostList(list []string, host string) bool {
+	for _, entry := range list {
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve looks a host up and fails unless all its addresses are public
+func (g *fetchGuard) resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else {
+		ips, err := g.resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		addrs = ips
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	for _, addr := range addrs {
+		if g.internal(addr) {
+			return nil, fetchBlocked("host %s resolves to the internal address %s", host, addr)
+		}
+	}
+	return addrs, nil
+}
+
+// isInternalAddr reports whether an address is not on the public internet
+func isInternalAddr(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// dialContext resolves and checks the host, then dials the checked addresses
+func (g *fetchGuard) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := g.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := g.dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// checkRedirect checks every redirect target, resolving its host
+func (g *fetchGuard) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > g.policy.MaxRedirects {
+		return fetchBlocked("more than %d redirects", g.policy.MaxRedirects)
+	}
+	if err := g.checkURL(req.URL); err != nil {
+		return err
+	}
+	_, err := g.resolve(req.Context(), normalizeHost(req.URL.Hostname()))
+	return err
+}
+
+// guardedTransport checks requests before they are sent and limits the
+// responses: successful ones must have an allowed content type, and bodies
+// fail once they pass the size limit
+type guardedTransport struct {
+	guard *fetchGuard
+	next  http.RoundTripper
+}
+
+func (t *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.guard.checkURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := t.guard.policy
+	if policy.MaxBodyBytes > 0 && resp.ContentLength > policy.MaxBodyBytes {
+		resp.Body.Close()
+		return nil, fetchBlocked("response body is larger than %d bytes", policy.MaxBodyBytes)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 && len(policy.ContentTypes) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if !containsString(policy.ContentTypes, strings.ToLower(mediaType)) {
+			resp.Body.Close()
+			if mediaType == "" {
+				return nil, fetchBlocked("response has no content type")
+			}
+			return nil, fetchBlocked("content type %s is not allowed", mediaType)
+		}
+	}
+	if policy.MaxBodyBytes > 0 {
+		resp.Body = &limitedBody{ReadCloser: resp.Body, limit: policy.MaxBodyBytes}
+	}
+	return resp, nil
+}
+
+// limitedBody fails reads once more than the limit has been read
+type limitedBody struct {
+	io.ReadCloser
+	limit int64
+	read  int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.read > b.limit {
+		return 0, fetchBlocked("response body is larger than %d bytes", b.limit)
+	}
+	// Read one byte past the limit to tell a body of exactly the limit apart
+	if remaining := b.limit - b.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return n - int(b.read-b.limit), fetchBlocked("response body is larger than %d bytes", b.limit)
+	}
+	return n, err
+}
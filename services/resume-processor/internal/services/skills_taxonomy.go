@@ -0,0 +1,522 @@
+package services
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed data/skills.json
+var builtinSkillsData []byte
+
+var (
+	// ErrUnknownSkill is returned for skill IDs that are not in the taxonomy
+	ErrUnknownSkill = errors.New("unknown skill")
+	// ErrInvalidSkill is returned when a skill definition cannot be added
+	ErrInvalidSkill = errors.New("invalid skill")
+)
+
+var (
+	skillIDPattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9.+#-]{0,63}$`)
+	skillTokenBreaks  = regexp.MustCompile(`[\s,;()\[\]{}|"!?<>:•·]+`)
+	skillTokenPadding = "'`*"
+)
+
+// Skill is a canonical skill with the other names it goes by
+type Skill struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Category  string   `json:"category"`
+	Parent    string   `json:"parent,omitempty"`    // Broader skill this one implies, e.g. react → javascript
+	Ambiguous bool     `json:"ambiguous,omitempty"` // Single words only match in free text with the capitalization given here ("Go", "Excel")
+	Aliases   []string `json:"aliases"`
+}
+
+// SkillMatch is a skill found in a text
+type SkillMatch struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Terms    []string `json:"terms"` // Forms the skill was written in
+	Count    int      `json:"count"`
+	Via      []string `json:"via,omitempty"` // Set by CompareSkills when only narrower skills matched
+}
+
+// SkillComparison is how well the skills of a resume cover those of a job description
+type SkillComparison struct {
+	Matched  []SkillMatch `json:"matched"`
+	Missing  []SkillMatch `json:"missing"`
+	Coverage float64      `json:"coverage"` // Share of job skills matched, 0-1
+}
+
+// skillTaxonomyFile is the layout of the embedded taxonomy
+type skillTaxonomyFile struct {
+	Categories map[string]string `json:"categories"`
+	Skills     []Skill           `json:"skills"`
+}
+
+// SkillTaxonomy maps skill names and aliases to canonical skills. It is
+// safe for concurrent use; admin changes apply to every reader.
+type SkillTaxonomy struct {
+	mu         sync.RWMutex
+	categories map[string]string
+	skills     map[string]*Skill
+	terms      map[string]string // Normalized name or alias → skill ID
+	maxWords   int               // Longest term, in words
+}
+
+var (
+	defaultSkillTaxonomy     *SkillTaxonomy
+	defaultSkillTaxonomyOnce sync.Once
+)
+
+// DefaultSkillTaxonomy returns the shared taxonomy loaded from the embedded
+// data file
+func DefaultSkillTaxonomy() *SkillTaxonomy {
+	defaultSkillTaxonomyOnce.Do(func() {
+		taxonomy, err := NewSkillTaxonomy(builtinSkillsData)
+		if err != nil {
+			panic("invalid embedded skills taxonomy: " + err.Error())
+		}
+		defaultSkillTaxonomy = taxonomy
+	})
+	return defaultSkillTaxonomy
+}
+
+// NewSkillTaxonomy creates a taxonomy from JSON data in the layout of
+// data/skills.json
+func NewSkillTaxonomy(data []byte) (*SkillTaxonomy, error) {
+	var file skillTaxonomyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse skills taxonomy: %v", err)
+	}
+
+	t := &SkillTaxonomy{
+		categories: file.Categories,
+		skills:     make(map[string]*Skill, len(file.Skills)),
+		terms:      make(map[string]string),
+	}
+	// Parents may be listed after their children, so they are checked
+	// once every skill is in place
+	for _, skill := range file.Skills {
+		if err := t.validate(skill, false); err != nil {
+			return nil, err
+		}
+		t.put(skill)
+	}
+	for _, skill := range file.Skills {
+		if err := t.validateParent(skill); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Categories returns the skill categories and their descriptions
+func (t *SkillTaxonomy) Categories() map[string]string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	categories := make(map[string]string, len(t.categories))
+	for id, description := range t.categories {
+		categories[id] = description
+	}
+	return categories
+}
+
+// Skills returns the skills of a category, or every skill when category is
+// empty, ordered by ID
+func (t *SkillTaxonomy) Skills(category string) []Skill {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	skills := []Skill{}
+	for _, skill := range t.skills {
+		if category == "" || skill.Category == category {
+			skills = append(skills, copySkill(skill))
+		}
+	}
+	sort.Slice(skills, func(i, j int) bool { return skills[i].ID < skills[j].ID })
+	return skills
+}
+
+// Skill returns the skill with the given ID
+func (t *SkillTaxonomy) Skill(id string) (Skill, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	skill, ok := t.skills[id]
+	if !ok {
+		return Skill{}, false
+	}
+	return copySkill(skill), true
+}
+
+// Normalize returns the canonical skill for a name or alias, ignoring case,
+// e.g. "k8s" → Kubernetes
+func (t *SkillTaxonomy) Normalize(term string) (Skill, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	id, ok := t.lookup(skillKey(term))
+	if !ok {
+		return Skill{}, false
+	}
+	return copySkill(t.skills[id]), true
+}
+
+// Ancestors returns the IDs of the broader skills a skill implies, nearest first
+func (t *SkillTaxonomy) Ancestors(id string) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.ancestors(id)
+}
+
+// Children returns the IDs of the skills directly below a skill
+func (t *SkillTaxonomy) Children(id string) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	children := []string{}
+	for _, skill := range t.skills {
+		if skill.Parent == id {
+			children = append(children, skill.ID)
+		}
+	}
+	sort.Strings(children)
+	return children
+}
+
+// Extract finds the skills mentioned in free text, most mentioned first
+func (t *SkillTaxonomy) Extract(text string) []SkillMatch {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tokens := skillTokens(text, t.terms)
+	matches := map[string]*SkillMatch{}
+	var order []string
+
+	for i := 0; i < len(tokens); {
+		matched := 0
+		for n := min(t.maxWords, len(tokens)-i); n >= 1; n-- {
+			written := strings.Join(tokens[i:i+n], " ")
+			id, ok := t.lookup(skillKey(written))
+			if !ok {
+				continue
+			}
+			skill := t.skills[id]
+			if n == 1 && skill.Ambiguous && !skill.writtenAs(written) {
+				continue
+			}
+
+			match, seen := matches[id]
+			if !seen {
+				match = &SkillMatch{ID: id, Name: skill.Name, Category: skill.Category}
+				matches[id] = match
+				order = append(order, id)
+			}
+			match.Count++
+			if !containsString(match.Terms, written) {
+				match.Terms = append(match.Terms, written)
+			}
+			matched = n
+			break
+		}
+		if matched == 0 {
+			matched = 1
+		}
+		i += matched
+	}
+
+	result := make([]SkillMatch, 0, len(order))
+	for _, id := range order {
+		result = append(result, *matches[id])
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Count > result[j].Count })
+	return result
+}
+
+// CompareSkills reports which skills of a job description a resume covers.
+// A narrower skill covers the broader ones it implies, so a resume listing
+// React covers a job asking for JavaScript.
+func (t *SkillTaxonomy) CompareSkills(resumeText, jobText string) SkillComparison {
+	resumeSkills := t.Extract(resumeText)
+	jobSkills := t.Extract(jobText)
+
+	direct := map[string]bool{}
+	implied := map[string][]string{} // Skill ID → narrower resume skills implying it
+	t.mu.RLock()
+	for _, skill := range resumeSkills {
+		direct[skill.ID] = true
+		for _, ancestor := range t.ancestors(skill.ID) {
+			implied[ancestor] = append(implied[ancestor], skill.ID)
+		}
+	}
+	t.mu.RUnlock()
+
+	comparison := SkillComparison{Matched: []SkillMatch{}, Missing: []SkillMatch{}}
+	for _, skill := range jobSkills {
+		switch {
+		case direct[skill.ID]:
+			comparison.Matched = append(comparison.Matched, skill)
+		case len(implied[skill.ID]) > 0:
+			skill.Via = implied[skill.ID]
+			comparison.Matched = append(comparison.Matched, skill)
+		default:
+			comparison.Missing = append(comparison.Missing, skill)
+		}
+	}
+	if len(jobSkills) > 0 {
+		comparison.Coverage = float64(len(comparison.Matched)) / float64(len(jobSkills))
+	}
+	return comparison
+}
+
+// PromptContext summarizes the comparison for the optimization prompt, or
+// returns "" when the job description names no known skills
+func (c *SkillComparison) PromptContext() string {
+	if c == nil || len(c.Matched)+len(c.Missing) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Coverage: %.0f%% of %d job skills\n", c.Coverage*100, len(c.Matched)+len(c.Missing))
+	if len(c.Matched) > 0 {
+		b.WriteString("Matched:\n")
+		for _, skill := range c.Matched {
+			if len(skill.Via) > 0 {
+				fmt.Fprintf(&b, "- %s (implied by %s)\n", skill.Name, strings.Join(skill.Via, ", "))
+			} else {
+				fmt.Fprintf(&b, "- %s\n", skill.Name)
+			}
+		}
+	}
+	if len(c.Missing) > 0 {
+		b.WriteString("Missing:\n")
+		for _, skill := range c.Missing {
+			fmt.Fprintf(&b, "- %s\n", skill.Name)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Validate checks that a skill can be added to the taxonomy or replace the
+// skill with the same ID
+func (t *SkillTaxonomy) Validate(skill Skill) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if err := t.validate(skill, true); err != nil {
+		return err
+	}
+	return t.validateParent(skill)
+}
+
+// Upsert adds a skill or replaces the skill with the same ID
+func (t *SkillTaxonomy) Upsert(skill Skill) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.validate(skill, true); err != nil {
+		return err
+	}
+	if err := t.validateParent(skill); err != nil {
+		return err
+	}
+	t.remove(skill.ID)
+	t.put(skill)
+	return nil
+}
+
+// Remove deletes a skill. Skills that others name as their parent cannot
+// be removed until the children are moved or removed.
+func (t *SkillTaxonomy) Remove(id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.validateRemove(id); err != nil {
+		return err
+	}
+	t.remove(id)
+	return nil
+}
+
+// ValidateRemove checks that a skill can be removed
+func (t *SkillTaxonomy) ValidateRemove(id string) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.validateRemove(id)
+}
+
+// validateRemove checks that a skill exists and has no children
+func (t *SkillTaxonomy) validateRemove(id string) error {
+	if _, ok := t.skills[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownSkill, id)
+	}
+	for _, skill := range t.skills {
+		if skill.Parent == id {
+			return fmt.Errorf("%w: %s is the parent of %s", ErrInvalidSkill, id, skill.ID)
+		}
+	}
+	return nil
+}
+
+// validate checks the fields of a skill and that its terms are not taken
+// by another skill. The parent is checked separately.
+func (t *SkillTaxonomy) validate(skill Skill, replace bool) error {
+	if !skillIDPattern.MatchString(skill.ID) {
+		return fmt.Errorf("%w: id %q must be lowercase letters, digits and .+#-", ErrInvalidSkill, skill.ID)
+	}
+	if _, exists := t.skills[skill.ID]; exists && !replace {
+		return fmt.Errorf("%w: duplicate id %s", ErrInvalidSkill, skill.ID)
+	}
+	if strings.TrimSpace(skill.Name) == "" {
+		return fmt.Errorf("%w: %s has no name", ErrInvalidSkill, skill.ID)
+	}
+	if _, ok := t.categories[skill.Category]; !ok {
+		return fmt.Errorf("%w: %s has unknown category %q", ErrInvalidSkill, skill.ID, skill.Category)
+	}
+
+	for _, term := range append([]string{skill.Name}, skill.Aliases...) {
+		for _, key := range skillTermKeys(term) {
+			if key == "" {
+				return fmt.Errorf("%w: %s has an empty alias", ErrInvalidSkill, skill.ID)
+			}
+			if owner, taken := t.terms[key]; taken && owner != skill.ID {
+				return fmt.Errorf("%w: %q of %s is already a name of %s", ErrInvalidSkill, term, skill.ID, owner)
+			}
+		}
+	}
+	return nil
+}
+
+// validateParent checks that a skill's parent exists and is not the skill
+// itself or one of its descendants
+func (t *SkillTaxonomy) validateParent(skill Skill) error {
+	if skill.Parent == "" {
+		return nil
+	}
+	if _, ok := t.skills[skill.Parent]; !ok {
+		return fmt.Errorf("%w: %s has unknown parent %s", ErrInvalidSkill, skill.ID, skill.Parent)
+	}
+	if skill.Parent == skill.ID || containsString(t.ancestors(skill.Parent), skill.ID) {
+		return fmt.Errorf("%w: making %s the parent of %s creates a cycle", ErrInvalidSkill, skill.Parent, skill.ID)
+	}
+	return nil
+}
+
+// put adds a validated skill and indexes its terms
+func (t *SkillTaxonomy) put(skill Skill) {
+	stored := copySkill(&skill)
+	t.skills[skill.ID] = &stored
+	for _, term := range append([]string{skill.Name}, skill.Aliases...) {
+		for _, key := range skillTermKeys(term) {
+			t.terms[key] = skill.ID
+		}
+		if words := len(strings.Fields(skillKey(term))); words > t.maxWords {
+			t.maxWords = words
+		}
+	}
+}
+
+// remove deletes a skill and its terms
+func (t *SkillTaxonomy) remove(id string) {
+	delete(t.skills, id)
+	for key, owner := range t.terms {
+		if owner == id {
+			delete(t.terms, key)
+		}
+	}
+}
+
+// lookup finds the skill for a normalized term, reading hyphens as spaces
+// when the term is not known as written ("test-driven development")
+func (t *SkillTaxonomy) lookup(key string) (string, bool) {
+	if id, ok := t.terms[key]; ok {
+		return id, true
+	}
+	id, ok := t.terms[strings.ReplaceAll(key, "-", " ")]
+	return id, ok
+}
+
+// ancestors returns the parent chain of a skill; the caller holds the lock
+func (t *SkillTaxonomy) ancestors(id string) []string {
+	var ancestors []string
+	seen := map[string]bool{id: true}
+	for skill, ok := t.skills[id]; ok && skill.Parent != "" && !seen[skill.Parent]; skill, ok = t.skills[skill.Parent] {
+		seen[skill.Parent] = true
+		ancestors = append(ancestors, skill.Parent)
+	}
+	return ancestors
+}
+
+// writtenAs reports whether a single word matches one of the skill's terms
+// with the same capitalization
+func (s *Skill) writtenAs(word string) bool {
+	if s.Name == word {
+		return true
+	}
+	return containsString(s.Aliases, word)
+}
+
+// skillKey normalizes a skill term for lookup
+func skillKey(term string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.TrimRight(strings.TrimSpace(term), ".,;:"))), " ")
+}
+
+// skillTermKeys returns the lookup keys of a term: as written, and with
+// hyphens read as spaces
+func skillTermKeys(term string) []string {
+	key := skillKey(term)
+	if spaced := strings.ReplaceAll(key, "-", " "); spaced != key {
+		return []string{key, spaced}
+	}
+	return []string{key}
+}
+
+// skillTokens splits text into words, keeping the characters skill names
+// use ("C++", "C#", "Node.js", "CI/CD"). Slashed words that are not a
+// known term are split further ("Python/Django").
+func skillTokens(text string, terms map[string]string) []string {
+	var tokens []string
+	for _, raw := range skillTokenBreaks.Split(text, -1) {
+		token := strings.TrimRight(strings.Trim(raw, skillTokenPadding), ".")
+		if token == "" {
+			continue
+		}
+		if strings.Contains(token, "/") {
+			if _, known := terms[strings.ToLower(token)]; !known {
+				for _, part := range strings.Split(token, "/") {
+					if part = strings.Trim(part, skillTokenPadding); part != "" {
+						tokens = append(tokens, part)
+					}
+				}
+				continue
+			}
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// copySkill returns a copy of a skill that does not share its alias slice
+func copySkill(skill *Skill) Skill {
+	copied := *skill
+	copied.Aliases = append([]string{}, skill.Aliases...)
+	return copied
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
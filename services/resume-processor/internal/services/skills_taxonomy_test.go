@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSkillTaxonomyNormalize(t *testing.T) {
+	tests := []struct {
+		term string
+		want string // Skill ID, or "" when the term is unknown
+	}{
+		{term: "k8s", want: "kubernetes"},
+		{term: "K8S", want: "kubernetes"},
+		{term: "JS", want: "javascript"},
+		{term: "js", want: "javascript"},
+		{term: "Golang", want: "go"},
+		{term: " react.js ", want: "react"},
+		{term: "test driven development", want: "tdd"},
+		{term: "basket weaving"},
+	}
+
+	taxonomy := DefaultSkillTaxonomy()
+	for _, tt := range tests {
+		skill, ok := taxonomy.Normalize(tt.term)
+		if got := skill.ID; got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", tt.term, got, ok, tt.want)
+		}
+	}
+}
+
+func TestSkillTaxonomyExtractAmbiguous(t *testing.T) {
+	// Ambiguous single words only count with the capitalization of the
+	// taxonomy; unambiguous aliases match in any case
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "Built billing services in Go", want: []string{"go"}},
+		{text: "Ready to go the extra mile", want: []string{}},
+		{text: "GO, Rust", want: []string{"rust"}},
+		{text: "golang and K8s", want: []string{"go", "kubernetes"}},
+		{text: "Python/Django and ci/cd", want: []string{"python", "django", "ci-cd"}},
+	}
+
+	taxonomy := DefaultSkillTaxonomy()
+	for _, tt := range tests {
+		got := []string{}
+		for _, match := range taxonomy.Extract(tt.text) {
+			got = append(got, match.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Extract(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSkillTaxonomyCompareSkills(t *testing.T) {
+	comparison := DefaultSkillTaxonomy().CompareSkills(
+		"Skills: Next.js, k8s",
+		"We use JavaScript and Kubernetes; Terraform is a plus")
+
+	var matched, missing []string
+	for _, skill := range comparison.Matched {
+		matched = append(matched, skill.ID+strings.Join(append([]string{""}, skill.Via...), "<"))
+	}
+	for _, skill := range comparison.Missing {
+		missing = append(missing, skill.ID)
+	}
+	// JavaScript is covered by Next.js, two levels below it
+	if want := []string{"javascript<nextjs", "kubernetes"}; !reflect.DeepEqual(matched, want) {
+		t.Errorf("matched = %q, want %q", matched, want)
+	}
+	if want := []string{"terraform"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %q, want %q", missing, want)
+	}
+	if comparison.Coverage < 0.66 || comparison.Coverage > 0.67 {
+		t.Errorf("coverage = %v, want 2/3", comparison.Coverage)
+	}
+
+	context := comparison.PromptContext()
+	for _, want := range []string{"Coverage: 67% of 3 job skills", "- JavaScript (implied by nextjs)", "Missing:\n- Terraform"} {
+		if !strings.Contains(context, want) {
+			t.Errorf("prompt context lacks %q:\n%s", want, context)
+		}
+	}
+	if context := (&SkillComparison{}).PromptContext(); context != "" {
+		t.Errorf("empty comparison prompt context = %q", context)
+	}
+}
+
+func TestSkillTaxonomyValidateParents(t *testing.T) {
+	taxonomy, err := NewSkillTaxonomy([]byte(`{
+		"categories": {"language": "Programming languages"},
+		"skills": [
+			{"id": "c", "name": "Gamma", "category": "language", "parent": "b", "aliases": []},
+			{"id": "b", "name": "Beta", "category": "language", "parent": "a", "aliases": []},
+			{"id": "a", "name": "Alpha", "category": "language", "aliases": []}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("NewSkillTaxonomy: %v", err)
+	}
+
+	tests := []struct {
+		id     string
+		parent string
+		err    string // Part of the error message, or "" when valid
+	}{
+		{id: "a", parent: "a", err: "creates a cycle"},
+		{id: "a", parent: "b", err: "creates a cycle"},
+		{id: "a", parent: "c", err: "creates a cycle"},
+		{id: "b", parent: "c", err: "creates a cycle"},
+		{id: "a", parent: "missing", err: "unknown parent"},
+		{id: "c", parent: "a"},
+		{id: "d", parent: "c"},
+	}
+	for _, tt := range tests {
+		err := taxonomy.Validate(Skill{ID: tt.id, Name: strings.ToUpper(tt.id) + " skill", Category: "language", Parent: tt.parent})
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s under %s: %v", tt.id, tt.parent, err)
+		case tt.err != "" && (!errors.Is(err, ErrInvalidSkill) || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s under %s: err = %v, want ErrInvalidSkill with %q", tt.id, tt.parent, err, tt.err)
+		}
+	}
+
+	// A cycle in the data file is rejected too
+	_, err = NewSkillTaxonomy([]byte(`{
+		"categories": {"language": "Programming languages"},
+		"skills": [
+			{"id": "a", "name": "Alpha", "category": "language", "parent": "b", "aliases": []},
+			{"id": "b", "name": "Beta", "category": "language", "parent": "a", "aliases": []}
+		]
+	}`))
+	if !errors.Is(err, ErrInvalidSkill) {
+		t.Errorf("cyclic taxonomy: err = %v, want ErrInvalidSkill", err)
+	}
+}
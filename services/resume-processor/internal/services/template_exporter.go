@@ -0,0 +1,377 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Template export formats
+const (
+	ExportFormatLaTeX    = "latex"
+	ExportFormatMarkdown = "markdown"
+)
+
+// DefaultExportTemplate is used when no template is named
+const DefaultExportTemplate = "classic"
+
+var (
+	// ErrUnknownExportFormat is returned for formats without templates
+	ErrUnknownExportFormat = errors.New("unknown export format")
+	// ErrUnknownExportTemplate is returned for template names that are neither
+	// built in nor installed in EXPORT_TEMPLATE_DIR
+	ErrUnknownExportTemplate = errors.New("unknown export template")
+)
+
+// builtinExportTemplates holds the default templates, laid out as
+// templates/<format>/<name>/ with one *.tmpl file and any assets beside it
+//
+//go:embed templates
+var builtinExportTemplates embed.FS
+
+// exportFormat describes how a format is escaped and delivered
+type exportFormat struct {
+	escape      func(string) string
+	contentType string
+	ext         string
+	// LaTeX braces clash with the default {{ }} delimiters
+	leftDelim, rightDelim string
+	separator             string // Joins contact details
+}
+
+var exportFormats = map[string]exportFormat{
+	ExportFormatLaTeX: {
+		escape: EscapeLaTeX, contentType: "application/x-tex", ext: ".tex",
+		leftDelim: "<<", rightDelim: ">>", separator: ` \textbar{} `,
+	},
+	ExportFormatMarkdown: {
+		escape: EscapeMarkdown, contentType: "text/markdown; charset=utf-8", ext: ".md",
+		leftDelim: "{{", rightDelim: "}}", separator: " · ",
+	},
+}
+
+// exportTemplateName limits template names to a single path element
+var exportTemplateName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ExportData is what export templates are executed with. Every string is
+// already escaped for the target format, so templates insert values as-is.
+type ExportData struct {
+	Name      string
+	Label     string
+	Contact   []string
+	Separator string // Separator for contact details, e.g. {{join .Contact .Separator}}
+	Sections  []ExportSection
+}
+
+// ExportSection is a titled section of the resume. Text before the first
+// heading is collected in a section without a title.
+type ExportSection struct {
+	Title   string
+	Entries []ExportEntry
+}
+
+// ExportEntry is a position, degree or project with its text. Paragraphs and
+// bullets that do not belong to an entry form an entry without a title.
+type ExportEntry struct {
+	Title      string
+	Detail     string // Dates or location
+	Paragraphs []ExportParagraph
+	Bullets    []string
+}
+
+// ExportParagraph is body text with an optional lead-in such as "Languages"
+type ExportParagraph struct {
+	Label string
+	Text  string
+}
+
+// ExportFile is a rendered export ready for download
+type ExportFile struct {
+	Ext         string // Extension of the download: ".tex", ".md" or ".zip"
+	ContentType string
+	Data        []byte
+}
+
+// TemplateExporter renders resumes to LaTeX or Markdown with text/template.
+// Templates in the directory named by EXPORT_TEMPLATE_DIR (same
+// <format>/<name>/ layout) replace the built-in templates of the same name
+// and add new ones.
+type TemplateExporter struct {
+	spec      exportFormat
+	source    string
+	templates fs.FS // The directory of the chosen template, for its assets
+	main      string
+	assets    []string
+	sandbox   *templateSandbox // Set for user-defined templates
+}
+
+// NewTemplateExporter loads a template for a format; an empty name selects
+// the classic template
+func NewTemplateExporter(format, name string) (*TemplateExporter, error) {
+	spec, ok := exportFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExportFormat, format)
+	}
+	if name == "" {
+		name = DefaultExportTemplate
+	}
+	if !exportTemplateName.MatchString(name) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExportTemplate, name)
+	}
+
+	dir, err := exportTemplateDir(format, name)
+	if err != nil {
+		return nil, err
+	}
+	e := &TemplateExporter{spec: spec, templates: dir}
+	err = fs.WalkDir(dir, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasSuffix(p, ".tmpl") && !strings.Contains(p, "/") {
+			if e.main != "" {
+				return fmt.Errorf("export template %s/%s has more than one .tmpl file", format, name)
+			}
+			e.main = p
+			return nil
+		}
+		e.assets = append(e.assets, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if e.main == "" {
+		return nil, fmt.Errorf("export template %s/%s has no .tmpl file", format, name)
+	}
+
+	source, err := fs.ReadFile(dir, e.main)
+	if err != nil {
+		return nil, err
+	}
+	e.source = string(source)
+	return e, nil
+}
+
+// exportTemplateDir finds a template, preferring the deployment's copy
+func exportTemplateDir(format, name string) (fs.FS, error) {
+	if root := os.Getenv("EXPORT_TEMPLATE_DIR"); root != "" {
+		dir := path.Join(root, format, name)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return os.DirFS(dir), nil
+		}
+	}
+	dir, err := fs.Sub(builtinExportTemplates, path.Join("templates", format, name))
+	if err == nil {
+		if _, err = fs.Stat(dir, "."); err == nil {
+			return dir, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q (available: %s)", ErrUnknownExportTemplate, name, strings.Join(ExportTemplates(format), ", "))
+}
+
+// ExportTemplates lists the template names available for a format
+func ExportTemplates(format string) []string {
+	names := map[string]bool{}
+	if entries, err := fs.ReadDir(builtinExportTemplates, path.Join("templates", format)); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				names[entry.Name()] = true
+			}
+		}
+	}
+	if root := os.Getenv("EXPORT_TEMPLATE_DIR"); root != "" {
+		if entries, err := os.ReadDir(path.Join(root, format)); err == nil {
+			for _, entry := range entries {
+				if entry.IsDir() && exportTemplateName.MatchString(entry.Name()) {
+					names[entry.Name()] = true
+				}
+			}
+		}
+	}
+
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Render executes the template for a resume document. Templates without
+// assets produce the bare .tex or .md file; otherwise the file is returned in
+// a ZIP archive together with its assets (document classes, images, ...).
+func (e *TemplateExporter) Render(doc *ResumeDocument) (*ExportFile, error) {
+	var out bytes.Buffer
+	data := NewExportData(doc, e.spec.escape, e.spec.separator)
+	if e.sandbox != nil {
+		if err := e.sandbox.execute(e.main, e.source, e.spec, doc, data, &out); err != nil {
+			return nil, err
+		}
+	} else {
+		tmpl, err := parseExportTemplate(e.main, e.source, e.spec, exportTemplateFuncs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse export template: %w", err)
+		}
+		if err := tmpl.Execute(&out, data); err != nil {
+			return nil, fmt.Errorf("failed to render export template: %w", err)
+		}
+	}
+
+	filename := strings.TrimSuffix(e.main, ".tmpl")
+	if len(e.assets) == 0 {
+		return &ExportFile{Ext: path.Ext(filename), ContentType: e.spec.contentType, Data: out.Bytes()}, nil
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	write := func(name string, data []byte) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	if err := write(filename, out.Bytes()); err != nil {
+		return nil, err
+	}
+	for _, asset := range e.assets {
+		data, err := fs.ReadFile(e.templates, asset)
+		if err != nil {
+			return nil, err
+		}
+		if err := write(asset, data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return &ExportFile{Ext: ".zip", ContentType: "application/zip", Data: archive.Bytes()}, nil
+}
+
+// exportTemplateFuncs are the functions available to export templates
+var exportTemplateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// parseExportTemplate parses a template with the delimiters of its format
+func parseExportTemplate(name, source string, spec exportFormat, funcs template.FuncMap) (*template.Template, error) {
+	return template.New(name).
+		Delims(spec.leftDelim, spec.rightDelim).
+		Option("missingkey=error").
+		Funcs(funcs).
+		Parse(source)
+}
+
+// NewExportData groups the blocks of a resume document into sections and
+// entries, escaping every string with escape
+func NewExportData(doc *ResumeDocument, escape func(string) string, separator string) ExportData {
+	data := ExportData{Name: escape(doc.Name), Separator: separator}
+	var section *ExportSection
+	var entry *ExportEntry
+	current := func() *ExportEntry {
+		if section == nil {
+			data.Sections = append(data.Sections, ExportSection{})
+			section = &data.Sections[len(data.Sections)-1]
+		}
+		if entry == nil {
+			section.Entries = append(section.Entries, ExportEntry{})
+			entry = &section.Entries[len(section.Entries)-1]
+		}
+		return entry
+	}
+
+	for _, block := range doc.Blocks {
+		switch block.Kind {
+		case ResumeBlockName:
+			if data.Name == "" {
+				data.Name = escape(block.Text)
+			}
+		case ResumeBlockLabel:
+			data.Label = escape(block.Text)
+		case ResumeBlockContact:
+			for _, part := range strings.Split(block.Text, "|") {
+				if part = strings.TrimSpace(part); part != "" {
+					data.Contact = append(data.Contact, escape(part))
+				}
+			}
+		case ResumeBlockHeading:
+			data.Sections = append(data.Sections, ExportSection{Title: escape(block.Text)})
+			section, entry = &data.Sections[len(data.Sections)-1], nil
+		case ResumeBlockEntry:
+			entry = nil
+			e := current()
+			e.Title, e.Detail = escape(block.Text), escape(block.Detail)
+		case ResumeBlockBullet:
+			e := current()
+			e.Bullets = append(e.Bullets, escape(block.Text))
+		default:
+			e := current()
+			if len(e.Bullets) > 0 {
+				// Keep text that follows a list below it
+				entry = nil
+				e = current()
+			}
+			e.Paragraphs = append(e.Paragraphs, ExportParagraph{Label: escape(block.Label), Text: escape(block.Text)})
+		}
+	}
+	return data
+}
+
+// latexEscaper replaces the characters LaTeX treats specially. Characters
+// with no escape of their own are written as text-mode commands; the empty
+// group keeps a following letter from running into the command name.
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`$`, `\$`,
+	`&`, `\&`,
+	`#`, `\#`,
+	`%`, `\%`,
+	`_`, `\_`,
+	`^`, `\textasciicircum{}`,
+	`~`, `\textasciitilde{}`,
+	`<`, `\textless{}`,
+	`>`, `\textgreater{}`,
+	`|`, `\textbar{}`,
+	`"`, `\textquotedbl{}`,
+	"\r", " ",
+	"\n", " ",
+)
+
+// EscapeLaTeX makes text safe to insert in LaTeX source
+func EscapeLaTeX(s string) string {
+	return latexEscaper.Replace(s)
+}
+
+var (
+	markdownEscaper = strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+		`<`, `\<`, `>`, `\>`, `|`, `\|`, `~`, `\~`, "\r", " ", "\n", " ",
+	)
+	// markdownBlockStart matches text that would start a heading, list,
+	// quote or rule at the beginning of a line
+	markdownBlockStart = regexp.MustCompile(`^(#|[-+=]|\d+[.)])`)
+)
+
+// EscapeMarkdown makes text safe to insert in Markdown, inline or at the
+// start of a line
+func EscapeMarkdown(s string) string {
+	s = markdownEscaper.Replace(s)
+	if loc := markdownBlockStart.FindStringIndex(s); loc != nil {
+		s = s[:loc[1]-1] + `\` + s[loc[1]-1:]
+	}
+	return s
+}
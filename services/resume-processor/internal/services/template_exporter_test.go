@@ -0,0 +1,238 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestEscapeLaTeX(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`\`, `\textbackslash{}`},
+		{`{`, `\{`},
+		{`}`, `\}`},
+		{`$`, `\$`},
+		{`&`, `\&`},
+		{`#`, `\#`},
+		{`%`, `\%`},
+		{`_`, `\_`},
+		{`^`, `\textasciicircum{}`},
+		{`~`, `\textasciitilde{}`},
+		{`<`, `\textless{}`},
+		{`>`, `\textgreater{}`},
+		{`|`, `\textbar{}`},
+		{`"`, `\textquotedbl{}`},
+		// Escapes are not escaped again, and line breaks would end a paragraph
+		{`\{}`, `\textbackslash{}\{\}`},
+		{"C# & R&D\n50% off", `C\# \& R\&D 50\% off`},
+		{"naïve café", "naïve café"},
+	}
+	for _, tt := range tests {
+		if got := EscapeLaTeX(tt.in); got != tt.want {
+			t.Errorf("EscapeLaTeX(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`\`, `\\`},
+		{`{`, `{`},
+		{`}`, `}`},
+		{`$`, `$`},
+		{`&`, `&`},
+		{`%`, `%`},
+		{`_`, `\_`},
+		{`^`, `^`},
+		{`~`, `\~`},
+		{`<`, `\<`},
+		{`>`, `\>`},
+		{`|`, `\|`},
+		{`"`, `"`},
+		{"a*b `c` [d]", "a\\*b \\`c\\` \\[d\\]"},
+		// Only text that would start a block is escaped at the line start
+		{`#`, `\#`},
+		{`C# and F#`, `C# and F#`},
+		{`- not a bullet`, `\- not a bullet`},
+		{`+1 555 010 2030`, `\+1 555 010 2030`},
+		{`2019. A good year`, `2019\. A good year`},
+		{"one\ntwo", "one two"},
+	}
+	for _, tt := range tests {
+		if got := EscapeMarkdown(tt.in); got != tt.want {
+			t.Errorf("EscapeMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// testExportDocument returns testResumeDocument with a bullet full of
+// characters both formats escape
+func testExportDocument() *ResumeDocument {
+	doc := testResumeDocument()
+	doc.Blocks = append(doc.Blocks, ResumeBlock{Kind: ResumeBlockBullet, Text: `Cut C# build time 30% & shipped *R&D* tools_v2`})
+	return doc
+}
+
+func TestTemplateExporterBuiltins(t *testing.T) {
+	t.Setenv("EXPORT_TEMPLATE_DIR", "")
+
+	tests := []struct {
+		format, name string
+		ext          string
+		want         []string // Lines of the rendered file
+	}{
+		{
+			format: ExportFormatLaTeX, name: "classic", ext: ".tex",
+			want: []string{
+				`  {\LARGE\bfseries Alex Morgan}\par`,
+				`  alex.morgan@example.com \textbar{} +1 555 010 2030 \textbar{} Berlin, Germany\par`,
+				`\section*{EXPERIENCE}`,
+				`\textbf{Senior Software Engineer, Northwind Analytics} \hfill \textit{Mar 2020 – Present}\par`,
+				`  \item{} Led the migration of the billing platform to event sourcing`,
+				`\textbf{Languages:} Go, Python, SQL\par`,
+				`  \item{} Cut C\# build time 30\% \& shipped *R\&D* tools\_v2`,
+			},
+		},
+		{
+			format: ExportFormatMarkdown, name: "", ext: ".md",
+			want: []string{
+				`# Alex Morgan`,
+				`**Senior Software Engineer**`,
+				`alex.morgan@example.com · \+1 555 010 2030 · Berlin, Germany`,
+				`## EXPERIENCE`,
+				`### Senior Software Engineer, Northwind Analytics`,
+				`*Mar 2020 – Present*`,
+				`- Led the migration of the billing platform to event sourcing`,
+				`**Languages:** Go, Python, SQL`,
+				`- Cut C# build time 30% & shipped \*R&D\* tools\_v2`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		exporter, err := NewTemplateExporter(tt.format, tt.name)
+		if err != nil {
+			t.Fatalf("%s/%s: %v", tt.format, tt.name, err)
+		}
+		file, err := exporter.Render(testExportDocument())
+		if err != nil {
+			t.Fatalf("%s/%s: Render: %v", tt.format, tt.name, err)
+		}
+		if file.Ext != tt.ext || file.ContentType != exportFormats[tt.format].contentType {
+			t.Errorf("%s/%s: ext %q, content type %q", tt.format, tt.name, file.Ext, file.ContentType)
+		}
+		lines := strings.Split(string(file.Data), "\n")
+		for _, want := range tt.want {
+			if !containsString(lines, want) {
+				t.Errorf("%s/%s: missing line %q in\n%s", tt.format, tt.name, want, file.Data)
+			}
+		}
+	}
+}
+
+// readExportZip returns the files of a rendered ZIP export by name
+func readExportZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("export is not a ZIP archive: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestTemplateExporterAssets(t *testing.T) {
+	// A template installed in EXPORT_TEMPLATE_DIR, with an image in a
+	// subdirectory
+	root := t.TempDir()
+	dir := filepath.Join(root, ExportFormatMarkdown, "portfolio")
+	if err := os.MkdirAll(filepath.Join(dir, "images"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"cv.md.tmpl":      "![logo](images/logo.png)\n# {{ .Name }}\n{{ range .Sections }}{{ if .Title }}## {{ .Title }}\n{{ end }}{{ end }}",
+		"images/logo.png": "\x89PNG fake image",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("EXPORT_TEMPLATE_DIR", root)
+
+	if names := ExportTemplates(ExportFormatMarkdown); strings.Join(names, ",") != "classic,portfolio" {
+		t.Errorf("templates = %q", names)
+	}
+
+	tests := []struct {
+		format, name string
+		want         map[string]string // File names, with text each file must contain
+	}{
+		{
+			// The built-in modern template ships its document class
+			format: ExportFormatLaTeX, name: "modern",
+			want: map[string]string{
+				"resume.tex": `\entry{Software Engineer, Contoso Ltd}{Jun 2016 – Feb 2020}`,
+				"resume.cls": `\ProvidesClass{resume}`,
+			},
+		},
+		{
+			format: ExportFormatMarkdown, name: "portfolio",
+			want: map[string]string{
+				"cv.md":           "## EDUCATION",
+				"images/logo.png": "\x89PNG fake image",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		exporter, err := NewTemplateExporter(tt.format, tt.name)
+		if err != nil {
+			t.Fatalf("%s/%s: %v", tt.format, tt.name, err)
+		}
+		file, err := exporter.Render(testExportDocument())
+		if err != nil {
+			t.Fatalf("%s/%s: Render: %v", tt.format, tt.name, err)
+		}
+		if file.Ext != ".zip" || file.ContentType != "application/zip" {
+			t.Errorf("%s/%s: ext %q, content type %q", tt.format, tt.name, file.Ext, file.ContentType)
+		}
+
+		files := readExportZip(t, file.Data)
+		var names, wantNames []string
+		for name := range files {
+			names = append(names, name)
+		}
+		for name, text := range tt.want {
+			wantNames = append(wantNames, name)
+			if !strings.Contains(files[name], text) {
+				t.Errorf("%s/%s: %s lacks %q:\n%s", tt.format, tt.name, name, text, files[name])
+			}
+		}
+		sort.Strings(names)
+		sort.Strings(wantNames)
+		if strings.Join(names, ",") != strings.Join(wantNames, ",") {
+			t.Errorf("%s/%s: archive holds %q, want %q", tt.format, tt.name, names, wantNames)
+		}
+	}
+}
@@ -1,82 +1,228 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/ledongthuc/pdf"
+	"github.com/rs/zerolog"
 )
 
+// PDF extraction modes, selected with PDF_EXTRACTION_MODE
+const (
+	PDFModeLayout = "layout" // Order text by glyph position, falling back to stream order
+	PDFModeStream = "stream" // Content stream order only
+)
+
+// Extractor extracts the text of one file format
+type Extractor func(ctx context.Context, filePath string) (*ExtractedDocument, error)
+
 // TextExtractor handles text extraction from various file formats
-type TextExtractor struct{}
+type TextExtractor struct {
+	extractors     map[string]Extractor // By MIME type
+	pdfMode        string
+	ocr            OCREngine
+	ocrConcurrency int
+	ocrPageTimeout time.Duration
+	trace          *ExtractionTrace // Set when EXTRACTION_DEBUG is on
+}
 
 // NewTextExtractor creates a new TextExtractor instance
 func NewTextExtractor() *TextExtractor {
-	return &TextExtractor{}
+	pdfMode := os.Getenv("PDF_EXTRACTION_MODE")
+	if pdfMode != PDFModeStream {
+		pdfMode = PDFModeLayout
+	}
+	te := &TextExtractor{
+		extractors:     make(map[string]Extractor),
+		pdfMode:        pdfMode,
+		ocr:            &TesseractEngine{Language: os.Getenv("OCR_LANGUAGE")},
+		ocrConcurrency: ocrConcurrency(),
+		ocrPageTimeout: ocrPageTimeout(),
+	}
+	if extractionDebugEnabled() {
+		te.trace = &ExtractionTrace{}
+	}
+	te.registerDefaultExtractors()
+	return te
+}
+
+// Trace returns the trace of the extractions run so far, or nil unless
+// EXTRACTION_DEBUG is set
+func (te *TextExtractor) Trace() *ExtractionTrace {
+	return te.trace
+}
+
+// WithOCREngine replaces the OCR engine used for image-based PDFs
+func (te *TextExtractor) WithOCREngine(engine OCREngine) *TextExtractor {
+	te.ocr = engine
+	return te
+}
+
+// ExtractText extracts plain text from a file based on its content
+func (te *TextExtractor) ExtractText(ctx context.Context, filePath string) (string, error) {
+	doc, err := te.ExtractDocument(ctx, filePath)
+	if err != nil {
+		return "", err
+	}
+	return doc.Text, nil
 }
 
-// ExtractText extracts plain text from a file based on its extension
-func (te *TextExtractor) ExtractText(filePath string) (string, error) {
-	fmt.Printf("=== NEW TEXT EXTRACTOR CALLED ===\n")
-	fmt.Printf("File: %s\n", filePath)
-	ext := strings.ToLower(filepath.Ext(filePath))
-	fmt.Printf("Extension detected: %s\n", ext)
-	
-	switch ext {
-	case ".pdf":
-		fmt.Printf("Calling UNIPDF extraction...\n")
-		return te.extractFromPDF(filePath)
-	case ".txt":
-		return te.extractFromText(filePath)
-	default:
-		// For unknown formats, try to read as text and clean up
+// ExtractDocument detects the type of a file from its content and extracts
+// its text with the extractor registered for that type, along with the
+// document outline where the format provides one. The file extension is
+// only used as a hint between text formats.
+func (te *TextExtractor) ExtractDocument(ctx context.Context, filePath string) (*ExtractedDocument, error) {
+	logger := zerolog.Ctx(ctx)
+
+	declared := DeclaredFileType(filePath)
+	detected, err := DetectFileType(filePath, declared)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug().Str("declared_type", declared).Str("detected_type", detected).Msg("Detected file type")
+	te.trace.add("file %s: declared %s, detected %s", filepath.Base(filePath), declared, detected)
+
+	extract, ok := te.extractors[detected]
+	if !ok {
+		if detected == MIMEMSWord {
+			return nil, fmt.Errorf("%w: legacy .doc files are not supported, please save the resume as .docx or PDF", ErrUnsupportedFileType)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFileType, detected)
+	}
+
+	doc, err := extract(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	doc.FileType = detected
+	doc.Report.finish(doc.Text)
+	te.trace.add("report: %+v", doc.Report)
+	logger.Info().
+		Str("file_type", detected).
+		Str("method", doc.Report.Method).
+		Int("pages", doc.Report.Pages).
+		Int("warnings", len(doc.Report.Warnings)).
+		Object("text", LogContent(doc.Text)).
+		Msg("Text extracted")
+	return doc, nil
+}
+
+// RegisterExtractor sets the extractor used for files of a MIME type,
+// replacing any existing one
+func (te *TextExtractor) RegisterExtractor(mimeType string, extractor Extractor) {
+	te.extractors[mimeType] = extractor
+}
+
+// SupportsFileType reports whether an extractor is registered for a MIME type
+func (te *TextExtractor) SupportsFileType(mimeType string) bool {
+	_, ok := te.extractors[mimeType]
+	return ok
+}
+
+// registerDefaultExtractors registers the built-in resume formats
+func (te *TextExtractor) registerDefaultExtractors() {
+	te.RegisterExtractor(MIMEPDF, te.extractPDFDocument)
+	te.RegisterExtractor(MIMEDOCX, textExtractor(te.extractFromDOCX))
+	te.RegisterExtractor(MIMEODT, textExtractor(te.extractFromODT))
+	te.RegisterExtractor(MIMERTF, textExtractor(te.extractFromRTF))
+	te.RegisterExtractor(MIMEHTML, textExtractor(te.extractFromHTML))
+	te.RegisterExtractor(MIMEMarkdown, textExtractor(te.extractFromMarkdown))
+	te.RegisterExtractor(MIMEJSON, te.extractFromJSONResume)
+	te.RegisterExtractor(MIMEPlainText, textExtractor(func(ctx context.Context, filePath string) (string, error) {
 		content, err := te.extractFromText(filePath)
 		if err != nil {
-			return "", fmt.Errorf("unsupported file format: %s", ext)
+			return "", err
 		}
 		return te.cleanTextContent(content), nil
+	}))
+}
+
+// textExtractor adapts an extractor that only produces text
+func textExtractor(extract func(ctx context.Context, filePath string) (string, error)) Extractor {
+	return func(ctx context.Context, filePath string) (*ExtractedDocument, error) {
+		text, err := extract(ctx, filePath)
+		if err != nil {
+			return nil, err
+		}
+		return &ExtractedDocument{Text: text}, nil
+	}
+}
+
+// extractPDFDocument extracts a PDF in layout mode when it yields readable
+// text, and in content stream order otherwise
+func (te *TextExtractor) extractPDFDocument(ctx context.Context, filePath string) (*ExtractedDocument, error) {
+	if te.pdfMode == PDFModeLayout {
+		doc, err := te.extractPDFLayout(ctx, filePath)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err == nil && len(strings.Fields(doc.Text)) >= 5 && !te.looksLikePDFArtifacts(doc.Text) {
+			te.trace.add("layout extraction: %d characters, %d outline entries", len(doc.Text), len(doc.Outline))
+			te.trace.add("extracted text:\n%s", doc.Text)
+			return doc, nil
+		}
+		zerolog.Ctx(ctx).Debug().AnErr("layout_error", err).Msg("Layout extraction unusable, falling back to stream order")
+		te.trace.add("layout extraction unusable (err: %v), falling back to stream order", err)
+	}
+
+	doc, err := te.extractFromPDF(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if te.pdfMode == PDFModeLayout {
+		doc.Report.warn("layout analysis found no readable text; text is in content stream order")
 	}
+	return doc, nil
 }
 
-// extractFromPDF extracts text from PDF files using improved filtering
-func (te *TextExtractor) extractFromPDF(filePath string) (string, error) {
-	fmt.Printf("=== STARTING IMPROVED PDF EXTRACTION ===\n")
-	fmt.Printf("Processing file: %s\n", filePath)
+// extractFromPDF extracts text from PDF files using improved filtering,
+// falling back to aggressive extraction and then OCR. The report records
+// which method produced the text.
+func (te *TextExtractor) extractFromPDF(ctx context.Context, filePath string) (*ExtractedDocument, error) {
+	logger := zerolog.Ctx(ctx)
 	
 	// Open PDF file
 	file, reader, err := pdf.Open(filePath)
 	if err != nil {
-		fmt.Printf("ERROR: Failed to open PDF: %v\n", err)
-		return "", fmt.Errorf("failed to open PDF: %v", err)
+		return nil, fmt.Errorf("failed to open PDF: %v", err)
 	}
 	defer file.Close()
 	
-	fmt.Printf("PDF opened successfully, pages: %d\n", reader.NumPage())
+	te.trace.add("stream extraction: %d pages", reader.NumPage())
 	
 	var textBuilder strings.Builder
 	validTextFound := false
+	doc := &ExtractedDocument{Report: ExtractionReport{Method: ExtractionMethodNative, Pages: reader.NumPage()}}
+	segments, filteredSegments := 0, 0
 	
 	// Extract text from all pages
 	for pageNum := 1; pageNum <= reader.NumPage(); pageNum++ {
-		fmt.Printf("Processing page %d/%d\n", pageNum, reader.NumPage())
-		
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		page := reader.Page(pageNum)
 		if page.V.IsNull() {
-			fmt.Printf("Page %d: Null page, skipping\n", pageNum)
+			te.trace.add("page %d: null page, skipping", pageNum)
 			continue
 		}
 		
 		// Get page content
 		content := page.Content()
 		if content.Text == nil {
-			fmt.Printf("Page %d: No text objects found\n", pageNum)
+			te.trace.add("page %d: no text objects found", pageNum)
+			doc.Report.warn("image-only page %d", pageNum)
 			continue
 		}
+		if layoutHasColumns(content.Text) {
+			doc.Report.warn("possible multi-column layout on page %d; text may be out of reading order", pageNum)
+		}
 		
 		pageTextExtracted := 0
 		totalSegments := 0
@@ -88,61 +234,79 @@ func (te *TextExtractor) extractFromPDF(filePath string) (string, error) {
 			}
 			
 			totalSegments++
-			fmt.Printf("  Segment %d: '%s' (len=%d)\n", totalSegments, text.S, len(text.S))
+			te.trace.add("  segment %d: %q", totalSegments, text.S)
 			
 			// Apply intelligent filtering
 			if te.isValidTextSegment(text.S) {
 				validSegments++
 				cleanedSegment := te.cleanTextSegment(text.S)
 				if cleanedSegment != "" {
-					fmt.Printf("  -> Valid segment %d: '%s'\n", validSegments, cleanedSegment)
+					te.trace.add("  -> valid segment %d: %q", validSegments, cleanedSegment)
 					textBuilder.WriteString(cleanedSegment + " ")
 					pageTextExtracted += len(cleanedSegment)
 					validTextFound = true
 				} else {
-					fmt.Printf("  -> Segment valid but cleaned to empty\n")
+					te.trace.add("  -> segment valid but cleaned to empty")
 				}
 			} else {
-				fmt.Printf("  -> Segment filtered out by validation\n")
+				te.trace.add("  -> segment filtered out by validation")
 			}
 		}
+		segments += totalSegments
+		filteredSegments += totalSegments - validSegments
+		if totalSegments == 0 {
+			doc.Report.warn("image-only page %d", pageNum)
+		}
 		
 		if pageTextExtracted > 0 {
-			fmt.Printf("Page %d extracted: %d characters from %d/%d segments\n", pageNum, pageTextExtracted, validSegments, totalSegments)
+			te.trace.add("page %d: %d characters from %d/%d segments", pageNum, pageTextExtracted, validSegments, totalSegments)
 			textBuilder.WriteString("\n") // Add line break between pages
 		} else {
-			fmt.Printf("Page %d: No valid text content found (processed %d segments, %d valid)\n", pageNum, totalSegments, validSegments)
+			te.trace.add("page %d: no valid text content found (processed %d segments, %d valid)", pageNum, totalSegments, validSegments)
 		}
 	}
 	
 	rawText := textBuilder.String()
 	cleanedText := te.cleanTextContent(rawText)
+	if segments > 0 {
+		doc.Report.FilteredRatio = float64(filteredSegments) / float64(segments)
+	}
 	
 	// If no valid text found, try more aggressive extraction methods
 	if !validTextFound || len(cleanedText) < 10 {
-		fmt.Printf("No text found via PDF parsing, trying aggressive extraction...\n")
+		logger.Debug().Msg("No text found via PDF parsing, trying aggressive extraction")
 		
 		// Try aggressive text extraction first
-		aggressiveText, err := te.extractTextAggressive(filePath)
+		aggressiveText, err := te.extractTextAggressive(ctx, filePath)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err == nil && len(aggressiveText) >= 10 {
-			fmt.Printf("Aggressive extraction successful: %d characters\n", len(aggressiveText))
+			te.trace.add("aggressive extraction successful: %d characters", len(aggressiveText))
 			cleanedText = te.cleanTextContent(aggressiveText)
+			doc.Report.Method = ExtractionMethodAggressive
 			validTextFound = true
 		} else {
-			fmt.Printf("Aggressive extraction failed or insufficient text, attempting OCR...\n")
+			logger.Debug().AnErr("aggressive_error", err).Msg("Aggressive extraction failed or insufficient text, attempting OCR")
 			// Try OCR as last resort
-			ocrText, err := te.extractTextWithOCR(filePath)
+			ocr, err := te.runOCR(ctx, filePath)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
 			if err != nil {
-				fmt.Printf("OCR extraction also failed: %v\n", err)
-				return "", fmt.Errorf("PDF text extraction failed - no readable text found via PDF parsing (%d characters), aggressive extraction, or OCR. This PDF might be image-based, corrupted, or have very complex formatting", len(cleanedText))
+				logger.Warn().Err(err).Msg("OCR extraction failed")
+				return nil, fmt.Errorf("PDF text extraction failed - no readable text found via PDF parsing (%d characters), aggressive extraction, or OCR. This PDF might be image-based, corrupted, or have very complex formatting", len(cleanedText))
 			}
 			
-			if len(ocrText) < 10 {
-				return "", fmt.Errorf("PDF text extraction failed - OCR found only %d characters. This PDF might be corrupted or contain no readable text", len(ocrText))
+			if len(ocr.Text) < 10 {
+				return nil, fmt.Errorf("PDF text extraction failed - OCR found only %d characters. This PDF might be corrupted or contain no readable text", len(ocr.Text))
 			}
 			
-			fmt.Printf("OCR extraction successful: %d characters\n", len(ocrText))
-			cleanedText = te.cleanTextContent(ocrText)
+			te.trace.add("OCR extraction successful: %d characters from %d pages", len(ocr.Text), len(ocr.Pages))
+			cleanedText = te.cleanTextContent(ocr.Text)
+			doc.OCRPages = ocr.Pages
+			doc.Report.Method = ExtractionMethodOCR
+			doc.Report.addOCRPages(ocr.Pages)
 			validTextFound = true
 		}
 	}
@@ -150,39 +314,20 @@ func (te *TextExtractor) extractFromPDF(filePath string) (string, error) {
 	// Additional validation - check for meaningful content
 	words := strings.Fields(cleanedText)
 	if len(words) < 5 {
-		return "", fmt.Errorf("PDF text extraction failed - insufficient text content (only %d words). This PDF might be image-based", len(words))
+		return nil, fmt.Errorf("PDF text extraction failed - insufficient text content (only %d words). This PDF might be image-based", len(words))
 	}
 	
 	// Check if text looks like actual resume content vs PDF artifacts
 	if te.looksLikePDFArtifacts(cleanedText) {
-		return "", fmt.Errorf("PDF text extraction failed - extracted content appears to be PDF structure rather than readable text")
-	}
-	
-	// Debug logging
-	fmt.Printf("=== PDF EXTRACTION RESULTS ===\n")
-	fmt.Printf("Total pages processed: %d\n", reader.NumPage())
-	fmt.Printf("Raw text length: %d characters\n", len(rawText))
-	fmt.Printf("Cleaned text length: %d characters\n", len(cleanedText))
-	fmt.Printf("Word count: %d words\n", len(words))
-	fmt.Printf("Valid text found: %t\n", validTextFound)
-	
-	// Write extracted text to debug file
-	debugFile := "/tmp/extracted_text_debug.txt"
-	if err := os.WriteFile(debugFile, []byte(cleanedText), 0644); err != nil {
-		fmt.Printf("Warning: Could not write debug file: %v\n", err)
-	} else {
-		fmt.Printf("Debug: Extracted text written to %s\n", debugFile)
+		return nil, fmt.Errorf("PDF text extraction failed - extracted content appears to be PDF structure rather than readable text")
 	}
 	
-	// Show text sample for debugging
-	if len(cleanedText) > 500 {
-		fmt.Printf("Text sample (first 500 chars):\n%s...\n", cleanedText[:500])
-	} else {
-		fmt.Printf("Complete extracted text:\n%s\n", cleanedText)
-	}
-	fmt.Printf("=== END EXTRACTION ===\n")
+	te.trace.add("stream extraction results: %d pages, raw %d characters, cleaned %d characters, %d words, valid text found: %t",
+		reader.NumPage(), len(rawText), len(cleanedText), len(words), validTextFound)
+	te.trace.add("extracted text:\n%s", cleanedText)
 	
-	return cleanedText, nil
+	doc.Text = cleanedText
+	return doc, nil
 }
 
 // extractFromText reads plain text files
@@ -192,14 +337,11 @@ func (te *TextExtractor) extractFromText(filePath string) (string, error) {
 		return "", fmt.Errorf("failed to read text file: %v", err)
 	}
 	
-	return string(content), nil
+	return decodeText(content), nil
 }
 
 // cleanTextContent cleans up extracted text content for better readability
 func (te *TextExtractor) cleanTextContent(text string) string {
-	fmt.Printf("=== STARTING TEXT CLEANING ===\n")
-	fmt.Printf("Original text length: %d characters\n", len(text))
-	
 	// Step 1: Remove weird symbols and artifacts
 	text = te.removeArtifacts(text)
 	
@@ -212,9 +354,6 @@ func (te *TextExtractor) cleanTextContent(text string) string {
 	// Step 4: Final cleanup
 	text = te.finalCleanup(text)
 	
-	fmt.Printf("Cleaned text length: %d characters\n", len(text))
-	fmt.Printf("=== TEXT CLEANING COMPLETE ===\n")
-	
 	return text
 }
 
@@ -247,9 +386,6 @@ func (te *TextExtractor) removeArtifacts(text string) string {
 
 // fixSpacing fixes spacing issues between characters
 func (te *TextExtractor) fixSpacing(text string) string {
-	fmt.Printf("Fixing spacing issues...\n")
-	originalLen := len(text)
-	
 	// Step 1: Fix extremely spaced out text (every character separated)
 	// This handles cases like "J i w o o L e e" -> "JiwooLee"
 	extremeSpacingPattern := regexp.MustCompile(`\b([A-Za-z])(?:\s+([A-Za-z]))+\b`)
@@ -310,7 +446,6 @@ func (te *TextExtractor) fixSpacing(text string) string {
 		return strings.ReplaceAll(match, " ", "")
 	})
 	
-	fmt.Printf("Spacing fix: %d -> %d characters\n", originalLen, len(text))
 	return text
 }
 
@@ -341,20 +476,21 @@ func (te *TextExtractor) normalizeStructure(text string) string {
 	return strings.Join(cleanLines, "\n")
 }
 
+// localizedSectionHeaders holds the section headers of every supported locale
+var localizedSectionHeaders = localizedHeaders()
+
 // isSectionHeader checks if a line is likely a section header
 func (te *TextExtractor) isSectionHeader(line string) bool {
-	sectionHeaders := []string{
-		"Summary", "Technical Skills", "Experience", "Education", 
-		"Certifications", "Projects", "Skills", "Work Experience",
-		"Professional Experience", "Leadership", "Activity",
-	}
-	
-	for _, header := range sectionHeaders {
+	for _, header := range canonicalSectionHeaders {
 		if strings.Contains(strings.ToLower(line), strings.ToLower(header)) {
 			return true
 		}
 	}
-	return false
+
+	// Localized headers are short common words in their language
+	// ("Formation", "Profil"), so only a whole-line match counts
+	trimmed := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), ":"))
+	return localizedSectionHeaders[strings.ToLower(trimmed)]
 }
 
 // finalCleanup performs final text cleanup
@@ -388,26 +524,19 @@ func (te *TextExtractor) finalCleanup(text string) string {
 
 // ValidateTextLength checks if extracted text is reasonable for AI processing
 func (te *TextExtractor) ValidateTextLength(text string) error {
-	fmt.Printf("=== TEXT VALIDATION DEBUG ===\n")
-	fmt.Printf("Text length: %d characters\n", len(text))
-	
 	if len(text) == 0 {
-		fmt.Printf("ERROR: No text content found\n")
 		return fmt.Errorf("no text content found in the file")
 	}
 	
 	if len(text) < 50 {
-		fmt.Printf("ERROR: Text too short\n")
 		return fmt.Errorf("file content is too short to be a valid resume")
 	}
 	
 	// Temporarily increase limit to 300,000 characters for debugging
 	if len(text) > 300000 {
-		fmt.Printf("ERROR: Text too large (%d characters)\n", len(text))
 		return fmt.Errorf("file content is too large (over 300,000 characters)")
 	}
 	
-	fmt.Printf("Text validation passed\n")
 	return nil
 }
 
@@ -518,9 +647,7 @@ func (te *TextExtractor) looksLikePDFArtifacts(text string) bool {
 }
 
 // extractTextAggressive extracts all available text without filtering (for difficult PDFs)
-func (te *TextExtractor) extractTextAggressive(filePath string) (string, error) {
-	fmt.Printf("=== STARTING AGGRESSIVE EXTRACTION ===\n")
-	
+func (te *TextExtractor) extractTextAggressive(ctx context.Context, filePath string) (string, error) {
 	// Open PDF file
 	file, reader, err := pdf.Open(filePath)
 	if err != nil {
@@ -528,13 +655,16 @@ func (te *TextExtractor) extractTextAggressive(filePath string) (string, error)
 	}
 	defer file.Close()
 	
-	fmt.Printf("Aggressive extraction: PDF has %d pages\n", reader.NumPage())
+	te.trace.add("aggressive extraction: %d pages", reader.NumPage())
 	
 	var textBuilder strings.Builder
 	allSegments := 0
 	
 	// Extract ALL text segments without filtering
 	for pageNum := 1; pageNum <= reader.NumPage(); pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
 		page := reader.Page(pageNum)
 		if page.V.IsNull() {
 			continue
@@ -557,29 +687,19 @@ func (te *TextExtractor) extractTextAggressive(filePath string) (string, error)
 			}
 		}
 		
-		fmt.Printf("Page %d: extracted %d segments\n", pageNum, pageSegments)
+		te.trace.add("page %d: extracted %d segments", pageNum, pageSegments)
 		if pageSegments > 0 {
 			textBuilder.WriteString("\n")
 		}
 	}
 	
 	rawText := textBuilder.String()
-	fmt.Printf("Aggressive extraction complete: %d segments, %d characters\n", allSegments, len(rawText))
+	te.trace.add("aggressive extraction complete: %d segments, %d characters", allSegments, len(rawText))
 	
 	// Apply basic cleaning but less aggressive filtering
 	cleanedText := te.cleanTextContentAggressive(rawText)
 	
-	fmt.Printf("After aggressive cleaning: %d characters\n", len(cleanedText))
-	
-	if len(cleanedText) > 100 {
-		previewLen := 500
-		if len(cleanedText) < previewLen {
-			previewLen = len(cleanedText)
-		}
-		fmt.Printf("Aggressive sample (first %d chars):\n%s...\n", previewLen, cleanedText[:previewLen])
-	} else {
-		fmt.Printf("Complete aggressive text:\n%s\n", cleanedText)
-	}
+	te.trace.add("after aggressive cleaning: %d characters", len(cleanedText))
 	
 	return cleanedText, nil
 }
@@ -611,79 +731,3 @@ func (te *TextExtractor) cleanTextContentAggressive(text string) string {
 	
 	return strings.TrimSpace(result)
 }
-
-// extractTextWithOCR extracts text from PDF using OCR (for image-based PDFs)
-func (te *TextExtractor) extractTextWithOCR(filePath string) (string, error) {
-	fmt.Printf("=== STARTING OCR EXTRACTION ===\n")
-	
-	// Create temporary directory for OCR processing
-	tempDir := "/tmp/ocr_" + filepath.Base(filePath)
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create temp dir: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
-	
-	// Step 1: Convert PDF to images using pdfimages
-	fmt.Printf("Converting PDF to images...\n")
-	imagePrefix := filepath.Join(tempDir, "page")
-	cmd := exec.Command("pdfimages", "-png", filePath, imagePrefix)
-	if err := cmd.Run(); err != nil {
-		// Try alternative: convert PDF using ImageMagick
-		fmt.Printf("pdfimages failed, trying ImageMagick convert...\n")
-		imageFile := filepath.Join(tempDir, "page.png")
-		cmd = exec.Command("convert", "-density", "300", filePath, imageFile)
-		if err := cmd.Run(); err != nil {
-			return "", fmt.Errorf("failed to convert PDF to images: %v", err)
-		}
-	}
-	
-	// Step 2: Find generated image files
-	files, err := filepath.Glob(filepath.Join(tempDir, "*.png"))
-	if err != nil {
-		return "", fmt.Errorf("failed to find image files: %v", err)
-	}
-	if len(files) == 0 {
-		// Try jpg files as fallback
-		files, _ = filepath.Glob(filepath.Join(tempDir, "*.jpg"))
-		if len(files) == 0 {
-			return "", fmt.Errorf("no image files generated from PDF")
-		}
-	}
-	
-	fmt.Printf("Found %d image files to process\n", len(files))
-	
-	// Step 3: OCR each image file
-	var allText strings.Builder
-	for i, imageFile := range files {
-		fmt.Printf("Processing image %d/%d: %s\n", i+1, len(files), imageFile)
-		
-		// Use tesseract to extract text
-		cmd = exec.Command("tesseract", imageFile, "stdout", "-c", "preserve_interword_spaces=1")
-		output, err := cmd.Output()
-		if err != nil {
-			fmt.Printf("Warning: OCR failed for %s: %v\n", imageFile, err)
-			continue
-		}
-		
-		text := strings.TrimSpace(string(output))
-		if text != "" {
-			allText.WriteString(text)
-			allText.WriteString("\n\n")
-			fmt.Printf("Extracted %d characters from image %d\n", len(text), i+1)
-		} else {
-			fmt.Printf("No text found in image %d\n", i+1)
-		}
-	}
-	
-	ocrResult := allText.String()
-	fmt.Printf("=== OCR EXTRACTION COMPLETE ===\n")
-	fmt.Printf("Total OCR text extracted: %d characters\n", len(ocrResult))
-	
-	if len(ocrResult) > 500 {
-		fmt.Printf("OCR sample (first 500 chars):\n%s...\n", ocrResult[:500])
-	} else {
-		fmt.Printf("Complete OCR text:\n%s\n", ocrResult)
-	}
-	
-	return ocrResult, nil
-}
@@ -0,0 +1,616 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timeline sections
+const (
+	TimelineSectionExperience = "experience"
+	TimelineSectionEducation  = "education"
+)
+
+// Timeline issue kinds
+const (
+	TimelineIssueGap          = "gap"                 // No experience entry covers a stretch of months
+	TimelineIssueOverlap      = "overlap"             // Two experience entries run at the same time
+	TimelineIssueInconsistent = "inconsistent_format" // Dates in one section are written in different styles
+	TimelineIssueInvalidRange = "invalid_range"       // An entry ends before it starts
+)
+
+// Date precisions, from finest to coarsest
+const (
+	DatePrecisionMonth  = "month"
+	DatePrecisionSeason = "season"
+	DatePrecisionYear   = "year"
+)
+
+// Date formats, as written in the resume
+const (
+	DateFormatMonthName   = "month_name"   // January 2020
+	DateFormatMonthAbbrev = "month_abbrev" // Jan 2020
+	DateFormatNumeric     = "numeric"      // 01/2020
+	DateFormatISO         = "iso"          // 2020-01
+	DateFormatYear        = "year"         // 2020
+	DateFormatSeason      = "season"       // Summer 2018
+	DateFormatShortYear   = "short_year"   // 2019-21
+)
+
+// timelineOverlapTolerance is the overlap in months that is not flagged;
+// a new job often starts in the month the previous one ends
+const timelineOverlapTolerance = 1
+
+// timelineMonth is a month name and whether it is abbreviated
+type timelineMonth struct {
+	month  int
+	abbrev bool
+}
+
+// timelineMonths are the month names of the supported locales
+var timelineMonths = map[string]timelineMonth{
+	"january": {1, false}, "february": {2, false}, "march": {3, false}, "april": {4, false},
+	"may": {5, false}, "june": {6, false}, "july": {7, false}, "august": {8, false},
+	"september": {9, false}, "october": {10, false}, "november": {11, false}, "december": {12, false},
+	"jan": {1, true}, "feb": {2, true}, "mar": {3, true}, "apr": {4, true}, "jun": {6, true},
+	"jul": {7, true}, "aug": {8, true}, "sep": {9, true}, "sept": {9, true}, "oct": {10, true},
+	"nov": {11, true}, "dec": {12, true},
+	// German
+	"januar": {1, false}, "februar": {2, false}, "märz": {3, false}, "mai": {5, false},
+	"juni": {6, false}, "juli": {7, false}, "oktober": {10, false}, "dezember": {12, false},
+	"okt": {10, true}, "dez": {12, true},
+	// French
+	"janvier": {1, false}, "février": {2, false}, "mars": {3, false}, "avril": {4, false},
+	"juin": {6, false}, "juillet": {7, false}, "août": {8, false}, "septembre": {9, false},
+	"octobre": {10, false}, "novembre": {11, false}, "décembre": {12, false},
+	// Spanish
+	"enero": {1, false}, "febrero": {2, false}, "marzo": {3, false}, "abril": {4, false},
+	"mayo": {5, false}, "junio": {6, false}, "julio": {7, false}, "agosto": {8, false},
+	"septiembre": {9, false}, "octubre": {10, false}, "noviembre": {11, false}, "diciembre": {12, false},
+}
+
+// timelineSeasons are the first and last month of each season. Winter is
+// read as the winter term that opens the year.
+var timelineSeasons = map[string][2]int{
+	"spring": {3, 5}, "summer": {6, 8}, "fall": {9, 11}, "autumn": {9, 11}, "winter": {1, 3},
+}
+
+// timelinePresentWords mark an entry that is still ongoing
+var timelinePresentWords = []string{
+	"present", "current", "now", "today", "ongoing",
+	"heute", "aktuell", "jetzt", "actuel", "aujourd'hui", "presente", "actualidad", "actual", "atual", "oggi",
+}
+
+// timelineExperienceHeaders are the canonical headers of experience sections
+var timelineExperienceHeaders = []string{"Experience", "Work Experience", "Professional Experience", "Leadership"}
+
+var (
+	timelineDatePoint    = timelineDatePointPattern()
+	timelinePresent      = `\b(?:` + strings.Join(timelinePresentWords, "|") + `)\b`
+	timelineRangePattern = regexp.MustCompile(`(?i)(` + timelineDatePoint + `)\s*(?:-|–|—|−|\bto\b|\buntil\b|\btill\b|\bthrough\b|\bbis\b)\s*(` + timelineDatePoint + `|` + timelinePresent + `)|\b((?:19|20)\d{2})\s*[-–—/]\s*(\d{2})\b`)
+	timelinePointPattern = regexp.MustCompile(`(?i)` + timelineDatePoint)
+	timelinePresentDate  = regexp.MustCompile(`(?i)^` + timelinePresent + `$`)
+	timelineNamedPattern = regexp.MustCompile(`(?i)^(\pL+)\.?\s+(\d{4}|'\d{2})$`)
+	timelineNumericDate  = regexp.MustCompile(`^(\d{1,2})[/.](\d{4})$`)
+	timelineISODate      = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	timelineBulletLine   = regexp.MustCompile(`^[-•*◦▪‣]\s`)
+	timelineProseDate    = regexp.MustCompile(`(?i)\b(?:in|since|by|during|until|from|of)$`)
+	timelineTitleTrim    = " \t,;:|–—-()[]·•"
+)
+
+// timelineDatePointPattern builds the pattern of a single date
+func timelineDatePointPattern() string {
+	names := make([]string, 0, len(timelineMonths)+len(timelineSeasons))
+	for name := range timelineMonths {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	for name := range timelineSeasons {
+		names = append(names, name)
+	}
+	// Longer names first so "september" is not read as "sep"
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	return `(?:\b(?:` + strings.Join(names, "|") + `)\.?\s+(?:\d{4}|'\d{2})\b` +
+		`|\b(?:0?[1-9]|1[0-2])[/.](?:19|20)\d{2}\b` +
+		`|\b(?:19|20)\d{2}-(?:0[1-9]|1[0-2])\b` +
+		`|\b(?:19|20)\d{2}\b)`
+}
+
+// TimelineEntry is one dated experience or education entry
+type TimelineEntry struct {
+	Section   string `json:"section"`   // experience or education
+	Title     string `json:"title"`     // Text of the line the dates appear on, or of the line before
+	Raw       string `json:"raw"`       // The dates as written
+	Start     string `json:"start"`     // YYYY-MM; the first month the entry may have started for coarse dates
+	End       string `json:"end"`       // YYYY-MM; the current month for ongoing entries
+	Current   bool   `json:"current"`   // Ongoing ("Present")
+	Precision string `json:"precision"` // Coarsest precision of the two dates
+	Format    string `json:"format"`    // Format of the start date
+	Months    int    `json:"months"`    // Length of the entry, counting both the first and last month
+	Line      int    `json:"line"`      // 1-based line of the resume text
+
+	start, end dateSpan
+	formats    []string
+}
+
+// TimelineIssue is a gap, overlap or inconsistency in a timeline
+type TimelineIssue struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Start   string `json:"start,omitempty"` // YYYY-MM
+	End     string `json:"end,omitempty"`   // YYYY-MM
+	Months  int    `json:"months,omitempty"`
+	Entries []int  `json:"entries"` // Indexes into Timeline.Entries
+}
+
+// Timeline is the normalized chronology of a resume, entries ordered by start date
+type Timeline struct {
+	Entries []TimelineEntry `json:"entries"`
+	Issues  []TimelineIssue `json:"issues"`
+}
+
+// dateSpan is the range of months a written date may stand for, as
+// year*12 + month-1; "2020" spans January to December
+type dateSpan struct {
+	lo, hi int
+}
+
+// TimelineAnalyzer parses the dates of experience and education entries
+type TimelineAnalyzer struct {
+	gapMonths int              // Shortest gap between experience entries that is flagged
+	now       func() time.Time // Month that "Present" stands for
+}
+
+// NewTimelineAnalyzer creates a new TimelineAnalyzer instance. Gaps of
+// TIMELINE_GAP_MONTHS (default 6) or more are flagged.
+func NewTimelineAnalyzer() *TimelineAnalyzer {
+	gapMonths := 6
+	if value, err := strconv.Atoi(os.Getenv("TIMELINE_GAP_MONTHS")); err == nil && value > 0 {
+		gapMonths = value
+	}
+	return &TimelineAnalyzer{gapMonths: gapMonths, now: time.Now}
+}
+
+// Analyze builds the timeline of the dated entries in resume text
+func (ta *TimelineAnalyzer) Analyze(text string) *Timeline {
+	now := ta.now()
+	current := now.Year()*12 + int(now.Month()) - 1
+
+	timeline := &Timeline{Entries: []TimelineEntry{}, Issues: []TimelineIssue{}}
+	lines := strings.Split(text, "\n")
+
+	// Without recognizable headers every line is read as experience
+	section := ""
+	if !timelineHasSections(lines) {
+		section = TimelineSectionExperience
+	}
+
+	previous := ""
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if kind, ok := timelineSection(line); ok {
+			section, previous = kind, ""
+			continue
+		}
+		if section != TimelineSectionExperience && section != TimelineSectionEducation {
+			continue
+		}
+
+		entries := ta.parseLine(line, current)
+		for _, entry := range entries {
+			entry.Section = section
+			entry.Line = i + 1
+			if entry.Title == "" {
+				entry.Title = previous
+			}
+			timeline.Entries = append(timeline.Entries, entry)
+		}
+		if len(entries) == 0 {
+			previous = line
+		}
+	}
+
+	sort.SliceStable(timeline.Entries, func(i, j int) bool {
+		return timeline.Entries[i].start.lo < timeline.Entries[j].start.lo
+	})
+
+	timeline.Issues = append(timeline.Issues, timelineInvalidRanges(timeline.Entries)...)
+	timeline.Issues = append(timeline.Issues, timelineFormatIssues(timeline.Entries)...)
+	timeline.Issues = append(timeline.Issues, ta.gaps(timeline.Entries, current)...)
+	timeline.Issues = append(timeline.Issues, timelineOverlaps(timeline.Entries)...)
+	return timeline
+}
+
+// parseLine returns the entries dated on one line. A single date only
+// counts at the start or end of a line that is not a bullet point, and not
+// after a preposition, so years mentioned in achievements ("cut costs by
+// 30% in 2019") are not read as entries.
+func (ta *TimelineAnalyzer) parseLine(line string, current int) []TimelineEntry {
+	var entries []TimelineEntry
+
+	for _, loc := range timelineRangePattern.FindAllStringSubmatchIndex(line, -1) {
+		raw := line[loc[0]:loc[1]]
+		entry := TimelineEntry{Raw: raw}
+
+		if loc[2] >= 0 {
+			start, precision, format, ok := parseTimelineDate(line[loc[2]:loc[3]])
+			if !ok {
+				continue
+			}
+			entry.start, entry.Precision, entry.formats = start, precision, []string{format}
+
+			endText := line[loc[4]:loc[5]]
+			if timelinePresentDate.MatchString(endText) {
+				entry.end, entry.Current = dateSpan{current, current}, true
+			} else {
+				end, endPrecision, endFormat, ok := parseTimelineDate(endText)
+				if !ok {
+					continue
+				}
+				entry.end = end
+				entry.Precision = coarserPrecision(entry.Precision, endPrecision)
+				entry.formats = append(entry.formats, endFormat)
+			}
+		} else {
+			startYear, _ := strconv.Atoi(line[loc[6]:loc[7]])
+			short, _ := strconv.Atoi(line[loc[8]:loc[9]])
+			endYear := startYear/100*100 + short
+			switch {
+			case endYear > startYear:
+				entry.start = dateSpan{startYear * 12, startYear*12 + 11}
+				entry.end = dateSpan{endYear * 12, endYear*12 + 11}
+				entry.Precision, entry.formats = DatePrecisionYear, []string{DateFormatShortYear}
+			case short >= 1 && short <= 12:
+				// "2019-03" on its own is a month, not a range ending in 2003
+				month := startYear*12 + short - 1
+				entry.start, entry.end = dateSpan{month, month}, dateSpan{month, month}
+				entry.Precision, entry.formats = DatePrecisionMonth, []string{DateFormatISO}
+			default:
+				continue
+			}
+		}
+
+		entry.Title = strings.Trim(line[:loc[0]]+" "+line[loc[1]:], timelineTitleTrim)
+		entries = append(entries, entry.normalize())
+	}
+	if len(entries) > 0 || timelineBulletLine.MatchString(line) {
+		return entries
+	}
+
+	for _, loc := range timelinePointPattern.FindAllStringIndex(line, -1) {
+		before := strings.Trim(line[:loc[0]], timelineTitleTrim)
+		after := strings.Trim(line[loc[1]:], timelineTitleTrim)
+		if (before != "" && after != "") || timelineProseDate.MatchString(before) {
+			continue
+		}
+		span, precision, format, ok := parseTimelineDate(line[loc[0]:loc[1]])
+		if !ok {
+			continue
+		}
+		entry := TimelineEntry{
+			Raw:       line[loc[0]:loc[1]],
+			Title:     strings.TrimSpace(before + after),
+			Precision: precision,
+			start:     span,
+			end:       span,
+			formats:   []string{format},
+		}
+		entries = append(entries, entry.normalize())
+	}
+	return entries
+}
+
+// normalize fills in the exported fields from the parsed spans
+func (e TimelineEntry) normalize() TimelineEntry {
+	e.Start = formatTimelineMonth(e.start.lo)
+	e.End = formatTimelineMonth(e.end.hi)
+	e.Format = e.formats[0]
+	e.Months = e.end.hi - e.start.lo + 1
+	if e.Months < 0 {
+		e.Months = 0
+	}
+	return e
+}
+
+// parseTimelineDate reads a single written date
+func parseTimelineDate(text string) (dateSpan, string, string, bool) {
+	text = strings.TrimSpace(text)
+
+	if match := timelineNamedPattern.FindStringSubmatch(text); match != nil {
+		year := parseTimelineYear(match[2])
+		name := strings.ToLower(match[1])
+		if month, ok := timelineMonths[name]; ok {
+			format := DateFormatMonthName
+			if month.abbrev {
+				format = DateFormatMonthAbbrev
+			}
+			m := year*12 + month.month - 1
+			return dateSpan{m, m}, DatePrecisionMonth, format, true
+		}
+		if season, ok := timelineSeasons[name]; ok {
+			return dateSpan{year*12 + season[0] - 1, year*12 + season[1] - 1}, DatePrecisionSeason, DateFormatSeason, true
+		}
+		return dateSpan{}, "", "", false
+	}
+
+	if match := timelineNumericDate.FindStringSubmatch(text); match != nil {
+		month, _ := strconv.Atoi(match[1])
+		year, _ := strconv.Atoi(match[2])
+		m := year*12 + month - 1
+		return dateSpan{m, m}, DatePrecisionMonth, DateFormatNumeric, true
+	}
+
+	if match := timelineISODate.FindStringSubmatch(text); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		m := year*12 + month - 1
+		return dateSpan{m, m}, DatePrecisionMonth, DateFormatISO, true
+	}
+
+	if year, err := strconv.Atoi(text); err == nil && len(text) == 4 {
+		return dateSpan{year * 12, year*12 + 11}, DatePrecisionYear, DateFormatYear, true
+	}
+	return dateSpan{}, "", "", false
+}
+
+// parseTimelineYear reads a four-digit year or an abbreviated one ('19)
+func parseTimelineYear(text string) int {
+	if strings.HasPrefix(text, "'") {
+		short, _ := strconv.Atoi(text[1:])
+		// Two-digit years more than a few years ahead are last century's
+		if short > time.Now().Year()%100+5 {
+			return 1900 + short
+		}
+		return 2000 + short
+	}
+	year, _ := strconv.Atoi(text)
+	return year
+}
+
+// coarserPrecision returns the coarser of two date precisions
+func coarserPrecision(a, b string) string {
+	rank := map[string]int{DatePrecisionMonth: 0, DatePrecisionSeason: 1, DatePrecisionYear: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// formatTimelineMonth formats a month index as YYYY-MM
+func formatTimelineMonth(month int) string {
+	return fmt.Sprintf("%04d-%02d", month/12, month%12+1)
+}
+
+// timelineSection reports whether a line is a section header and which
+// timeline section it opens; other sections are returned as ""
+func timelineSection(line string) (string, bool) {
+	trimmed := strings.ToLower(strings.TrimSpace(strings.TrimSuffix(line, ":")))
+	if kind, ok := timelineSectionHeaders[trimmed]; ok {
+		return kind, true
+	}
+	if localizedSectionHeaders[trimmed] {
+		return "", true
+	}
+	// Headers such as "Relevant Experience" or "EDUCATION & TRAINING"
+	if len(strings.Fields(trimmed)) <= 4 && !strings.ContainsAny(trimmed, "0123456789") {
+		switch {
+		case strings.Contains(trimmed, "experience"), strings.Contains(trimmed, "employment"):
+			return TimelineSectionExperience, true
+		case strings.Contains(trimmed, "education"):
+			return TimelineSectionEducation, true
+		}
+	}
+	return "", false
+}
+
+// timelineHasSections reports whether the text has an experience or education header
+func timelineHasSections(lines []string) bool {
+	for _, line := range lines {
+		if kind, ok := timelineSection(strings.TrimSpace(line)); ok && kind != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// timelineSectionHeaders maps the lowercased experience and education
+// headers of every locale to their timeline section
+var timelineSectionHeaders = func() map[string]string {
+	headers := map[string]string{"employment history": TimelineSectionExperience, "work history": TimelineSectionExperience}
+	for _, profile := range localeProfiles {
+		for _, canonical := range timelineExperienceHeaders {
+			headers[strings.ToLower(canonical)] = TimelineSectionExperience
+			if localized, ok := profile.SectionHeaders[canonical]; ok {
+				headers[strings.ToLower(localized)] = TimelineSectionExperience
+			}
+		}
+		headers["education"] = TimelineSectionEducation
+		if localized, ok := profile.SectionHeaders["Education"]; ok {
+			headers[strings.ToLower(localized)] = TimelineSectionEducation
+		}
+	}
+	return headers
+}()
+
+// timelineInvalidRanges flags entries that end before they start
+func timelineInvalidRanges(entries []TimelineEntry) []TimelineIssue {
+	var issues []TimelineIssue
+	for i, entry := range entries {
+		if entry.end.hi < entry.start.lo {
+			issues = append(issues, TimelineIssue{
+				Kind:    TimelineIssueInvalidRange,
+				Message: fmt.Sprintf("%q ends before it starts", entry.Raw),
+				Entries: []int{i},
+			})
+		}
+	}
+	return issues
+}
+
+// timelineFormatIssues flags sections whose dates are written in more
+// than one style. Seasons are left out; "Summer 2018" is the usual way to
+// date an internship.
+func timelineFormatIssues(entries []TimelineEntry) []TimelineIssue {
+	var issues []TimelineIssue
+	for _, section := range []string{TimelineSectionExperience, TimelineSectionEducation} {
+		counts := map[string]int{}
+		var order []string
+		for _, entry := range entries {
+			if entry.Section != section {
+				continue
+			}
+			for _, format := range entry.formats {
+				if format == DateFormatSeason {
+					continue
+				}
+				if counts[format] == 0 {
+					order = append(order, format)
+				}
+				counts[format]++
+			}
+		}
+		if len(order) < 2 {
+			continue
+		}
+
+		// Entries not using the most common format are the ones to fix;
+		// on a tie the format seen first wins
+		dominant := order[0]
+		for _, format := range order {
+			if counts[format] > counts[dominant] {
+				dominant = format
+			}
+		}
+		var offending []int
+		var examples []string
+		for i, entry := range entries {
+			if entry.Section != section {
+				continue
+			}
+			for _, format := range entry.formats {
+				if format != dominant && format != DateFormatSeason {
+					offending = append(offending, i)
+					examples = append(examples, fmt.Sprintf("%q", entry.Raw))
+					break
+				}
+			}
+		}
+		issues = append(issues, TimelineIssue{
+			Kind:    TimelineIssueInconsistent,
+			Message: fmt.Sprintf("%s dates mix formats (%s); %s differ from %s", section, strings.Join(order, ", "), strings.Join(examples, ", "), dominant),
+			Entries: offending,
+		})
+	}
+	return issues
+}
+
+// gaps flags stretches of gapMonths or more that no experience entry
+// covers, including the time since the last entry ended. Coarse dates are
+// read as widely as possible, so "2018 - 2019" and "2020 - 2021" have no gap.
+func (ta *TimelineAnalyzer) gaps(entries []TimelineEntry, current int) []TimelineIssue {
+	var issues []TimelineIssue
+	coveredUntil, last := -1, -1
+	for i, entry := range entries {
+		if entry.Section != TimelineSectionExperience || entry.end.hi < entry.start.lo {
+			continue
+		}
+		if last >= 0 {
+			if months := entry.start.lo - coveredUntil - 1; months >= ta.gapMonths {
+				issues = append(issues, TimelineIssue{
+					Kind:    TimelineIssueGap,
+					Message: fmt.Sprintf("no experience between %s and %s (%d months)", formatTimelineMonth(coveredUntil+1), formatTimelineMonth(entry.start.lo-1), months),
+					Start:   formatTimelineMonth(coveredUntil + 1),
+					End:     formatTimelineMonth(entry.start.lo - 1),
+					Months:  months,
+					Entries: []int{last, i},
+				})
+			}
+		}
+		if last < 0 || entry.end.hi > coveredUntil {
+			coveredUntil, last = entry.end.hi, i
+		}
+	}
+
+	if last >= 0 {
+		if months := current - coveredUntil; months >= ta.gapMonths {
+			issues = append(issues, TimelineIssue{
+				Kind:    TimelineIssueGap,
+				Message: fmt.Sprintf("no experience since %s (%d months)", formatTimelineMonth(coveredUntil+1), months),
+				Start:   formatTimelineMonth(coveredUntil + 1),
+				Months:  months,
+				Entries: []int{last},
+			})
+		}
+	}
+	return issues
+}
+
+// timelineOverlaps flags experience entries that certainly ran at the same
+// time for more than timelineOverlapTolerance months. Coarse dates are read
+// as narrowly as possible, so "2018 - 2019" and "2019 - 2020" do not overlap.
+func timelineOverlaps(entries []TimelineEntry) []TimelineIssue {
+	var issues []TimelineIssue
+	for i := range entries {
+		a := entries[i]
+		if a.Section != TimelineSectionExperience {
+			continue
+		}
+		for j := i + 1; j < len(entries); j++ {
+			b := entries[j]
+			if b.Section != TimelineSectionExperience {
+				continue
+			}
+			from := max(a.start.hi, b.start.hi)
+			until := a.end.lo
+			if b.end.lo < until {
+				until = b.end.lo
+			}
+			months := until - from + 1
+			if months <= timelineOverlapTolerance {
+				continue
+			}
+			issues = append(issues, TimelineIssue{
+				Kind:    TimelineIssueOverlap,
+				Message: fmt.Sprintf("%q and %q overlap from %s to %s (%d months)", a.Raw, b.Raw, formatTimelineMonth(from), formatTimelineMonth(until), months),
+				Start:   formatTimelineMonth(from),
+				End:     formatTimelineMonth(until),
+				Months:  months,
+				Entries: []int{i, j},
+			})
+		}
+	}
+	return issues
+}
+
+// PromptContext summarizes the timeline for the optimization prompt, or
+// returns "" when the resume has no dated entries
+func (t *Timeline) PromptContext() string {
+	if t == nil || len(t.Entries) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Entries:\n")
+	for _, entry := range t.Entries {
+		end := entry.End
+		if entry.Current {
+			end = "present"
+		}
+		fmt.Fprintf(&b, "- %s to %s (%s, %s precision): %s\n", entry.Start, end, entry.Section, entry.Precision, entry.Title)
+	}
+	if len(t.Issues) > 0 {
+		b.WriteString("Issues:\n")
+		for _, issue := range t.Issues {
+			fmt.Fprintf(&b, "- %s: %s\n", issue.Kind, issue.Message)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
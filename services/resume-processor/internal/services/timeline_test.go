@@ -0,0 +1,171 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testTimelineAnalyzer returns an analyzer whose "Present" is June 2024
+func testTimelineAnalyzer(t *testing.T, gapMonths string) *TimelineAnalyzer {
+	t.Helper()
+	t.Setenv("TIMELINE_GAP_MONTHS", gapMonths)
+	ta := NewTimelineAnalyzer()
+	ta.now = func() time.Time { return time.Date(2024, time.June, 15, 0, 0, 0, 0, time.UTC) }
+	return ta
+}
+
+func TestTimelineAnalyzerDates(t *testing.T) {
+	tests := []struct {
+		line string
+		want TimelineEntry
+	}{
+		{
+			line: "Acme Corp, Jan 2020 – Present",
+			want: TimelineEntry{Title: "Acme Corp", Raw: "Jan 2020 – Present", Start: "2020-01", End: "2024-06", Current: true, Precision: DatePrecisionMonth, Format: DateFormatMonthAbbrev, Months: 54},
+		},
+		{
+			line: "Initech 2019-21",
+			want: TimelineEntry{Title: "Initech", Raw: "2019-21", Start: "2019-01", End: "2021-12", Precision: DatePrecisionYear, Format: DateFormatShortYear, Months: 36},
+		},
+		{
+			line: "Globex intern, Summer 2018",
+			want: TimelineEntry{Title: "Globex intern", Raw: "Summer 2018", Start: "2018-06", End: "2018-08", Precision: DatePrecisionSeason, Format: DateFormatSeason, Months: 3},
+		},
+		{
+			// A single month, not a range ending in 2003
+			line: "Hackathon winner 2019-03",
+			want: TimelineEntry{Title: "Hackathon winner", Raw: "2019-03", Start: "2019-03", End: "2019-03", Precision: DatePrecisionMonth, Format: DateFormatISO, Months: 1},
+		},
+		{
+			line: "Umbrella Corp 03/2016 to December 2017",
+			want: TimelineEntry{Title: "Umbrella Corp", Raw: "03/2016 to December 2017", Start: "2016-03", End: "2017-12", Precision: DatePrecisionMonth, Format: DateFormatNumeric, Months: 22},
+		},
+		{
+			line: "Hooli 2015 - 2016",
+			want: TimelineEntry{Title: "Hooli", Raw: "2015 - 2016", Start: "2015-01", End: "2016-12", Precision: DatePrecisionYear, Format: DateFormatYear, Months: 24},
+		},
+	}
+
+	ta := testTimelineAnalyzer(t, "6")
+	for _, tt := range tests {
+		timeline := ta.Analyze("Experience\n" + tt.line)
+		if len(timeline.Entries) != 1 {
+			t.Errorf("%q: %d entries, want 1", tt.line, len(timeline.Entries))
+			continue
+		}
+		got := timeline.Entries[0]
+		got.start, got.end, got.formats = dateSpan{}, dateSpan{}, nil
+		tt.want.Section, tt.want.Line = TimelineSectionExperience, 2
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q:\n got %+v\nwant %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestTimelineAnalyzerIgnoresDatesInText(t *testing.T) {
+	text := "Experience\n" +
+		"Senior Engineer, Acme Corp\n" +
+		"Mar 2019 – Present\n" +
+		"• Cut cloud costs by 30% in 2021\n" +
+		"• 2020 migration to Kubernetes\n" +
+		"- Led the team since 2022\n" +
+		"Grew revenue by 2x in 2020 through new pricing\n"
+
+	timeline := testTimelineAnalyzer(t, "6").Analyze(text)
+	if len(timeline.Entries) != 1 {
+		t.Fatalf("entries = %+v, want only the dated role", timeline.Entries)
+	}
+	if entry := timeline.Entries[0]; entry.Title != "Senior Engineer, Acme Corp" || entry.Start != "2019-03" {
+		t.Errorf("entry = %+v", entry)
+	}
+}
+
+func TestTimelineAnalyzerGapThreshold(t *testing.T) {
+	// January to May 2019 is not covered: a gap of five months
+	text := "Experience\n" +
+		"Acme Corp, Jan 2018 – Dec 2018\n" +
+		"Initech, Jun 2019 – Present\n"
+
+	tests := []struct {
+		gapMonths string
+		want      []TimelineIssue
+	}{
+		{gapMonths: "6", want: []TimelineIssue{}},
+		{
+			gapMonths: "5",
+			want: []TimelineIssue{{
+				Kind:    TimelineIssueGap,
+				Message: "no experience between 2019-01 and 2019-05 (5 months)",
+				Start:   "2019-01",
+				End:     "2019-05",
+				Months:  5,
+				Entries: []int{0, 1},
+			}},
+		},
+		// Invalid values fall back to six months
+		{gapMonths: "0", want: []TimelineIssue{}},
+	}
+
+	for _, tt := range tests {
+		timeline := testTimelineAnalyzer(t, tt.gapMonths).Analyze(text)
+		if !reflect.DeepEqual(timeline.Issues, tt.want) {
+			t.Errorf("TIMELINE_GAP_MONTHS=%s: issues = %+v, want %+v", tt.gapMonths, timeline.Issues, tt.want)
+		}
+	}
+}
+
+func TestTimelineAnalyzerGapSinceLastEntry(t *testing.T) {
+	// Present is June 2024; the months since the last entry ended count
+	tests := []struct {
+		end    string
+		months int // 0 when no gap is flagged
+	}{
+		{end: "Nov 2023", months: 7},
+		{end: "Dec 2023", months: 6},
+		{end: "Jan 2024"},
+	}
+
+	ta := testTimelineAnalyzer(t, "6")
+	for _, tt := range tests {
+		timeline := ta.Analyze("Experience\nAcme Corp, Jan 2018 – " + tt.end)
+		var months int
+		for _, issue := range timeline.Issues {
+			if issue.Kind == TimelineIssueGap {
+				months = issue.Months
+			}
+		}
+		if months != tt.months {
+			t.Errorf("ended %s: gap of %d months, want %d", tt.end, months, tt.months)
+		}
+	}
+}
+
+func TestTimelineAnalyzerOverlapTolerance(t *testing.T) {
+	tests := []struct {
+		first, second string
+		months        int // 0 when no overlap is flagged
+	}{
+		// Starting in the month the previous job ends is not an overlap
+		{first: "Jan 2018 – Mar 2019", second: "Mar 2019 – Present"},
+		{first: "Jan 2018 – Apr 2019", second: "Mar 2019 – Present", months: 2},
+		{first: "Jan 2018 – Dec 2019", second: "Mar 2019 – Jun 2019", months: 4},
+		// Years are read narrowly: 2019 may be January for one and December for the other
+		{first: "2017 – 2019", second: "2019 – 2021"},
+		{first: "2017 – 2020", second: "2019 – 2021", months: 2},
+	}
+
+	ta := testTimelineAnalyzer(t, "6")
+	for _, tt := range tests {
+		timeline := ta.Analyze("Experience\nAcme Corp, " + tt.first + "\nInitech, " + tt.second)
+		var months int
+		for _, issue := range timeline.Issues {
+			if issue.Kind == TimelineIssueOverlap {
+				months = issue.Months
+			}
+		}
+		if months != tt.months {
+			t.Errorf("%s and %s: overlap of %d months, want %d", tt.first, tt.second, months, tt.months)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// translatorSystemPrompt is sent as the system message for translation requests
+const translatorSystemPrompt = "You are a professional translator who specialises in resumes and CVs. You translate faithfully, adapt formatting to local hiring conventions and never invent content."
+
+// TranslationRequest represents a request to translate a resume into another locale
+type TranslationRequest struct {
+	Content      string `json:"content"`
+	TargetLocale string `json:"target_locale"`
+	AIModel      string `json:"ai_model"`
+	UserAPIKey   string `json:"user_api_key"`
+	RedactPII    bool   `json:"redact_pii"` // Replace PII with placeholders before calling the provider
+}
+
+// TranslationResponse represents the translated resume and the conventions applied
+type TranslationResponse struct {
+	TranslatedContent string         `json:"translated_content"`
+	SourceLanguage    string         `json:"source_language"`
+	Locale            LocaleProfile  `json:"locale"`
+	Notes             []string       `json:"notes"`
+	RedactedFields    map[string]int `json:"redacted_fields,omitempty"` // Per-category count of values redacted from the prompt
+}
+
+// TranslateResume translates resume content into the target locale and
+// converts dates, phone numbers, the document name and personal details
+// sections to that locale's conventions. When RedactPII is set the provider
+// only ever sees placeholders, as in OptimizeResume; redacted phone numbers
+// are restored as written rather than reformatted.
+func (ai *AIOptimizer) TranslateResume(ctx context.Context, req TranslationRequest) (*TranslationResponse, error) {
+	profile, ok := GetLocaleProfile(req.TargetLocale)
+	if !ok {
+		return nil, fmt.Errorf("unsupported target locale: %s (supported: %s)", req.TargetLocale, strings.Join(SupportedLocales(), ", "))
+	}
+
+	var redactor *PIIRedactor
+	var redactions *RedactionMap
+	if req.RedactPII {
+		redactor = NewPIIRedactor()
+		req.Content, redactions = redactor.Redact(req.Content)
+	}
+
+	prompt := ai.buildTranslationPrompt(req.Content, profile)
+	content, err := ai.complete(ctx, req.AIModel, req.UserAPIKey, translatorSystemPrompt, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	response := ai.parseTranslationResponse(content)
+	if redactor != nil {
+		response.TranslatedContent = redactor.Restore(response.TranslatedContent, redactions)
+		for i, note := range response.Notes {
+			response.Notes[i] = redactor.Restore(note, redactions)
+		}
+		response.RedactedFields = redactions.Counts()
+	}
+	response.TranslatedContent = localizeSectionHeaders(response.TranslatedContent, profile)
+	response.Locale = profile
+
+	return response, nil
+}
+
+// buildTranslationPrompt creates the prompt for resume translation
+func (ai *AIOptimizer) buildTranslationPrompt(content string, profile LocaleProfile) string {
+	var headers strings.Builder
+	for _, canonical := range canonicalSectionHeaders {
+		if localized, ok := profile.SectionHeaders[canonical]; ok {
+			headers.WriteString(fmt.Sprintf("- %s -> %s\n", canonical, localized))
+		}
+	}
+
+	personalDetails := "Do NOT include a photo placeholder or date of birth; remove them if present."
+	switch {
+	case profile.IncludePhoto && profile.IncludeDateOfBirth:
+		personalDetails = "A photo and date of birth are customary. Keep them if present, but do not invent them."
+	case profile.IncludePhoto:
+		personalDetails = "A photo is customary but date of birth is not. Keep a photo reference if present and remove any date of birth."
+	}
+
+	placeholderRule := ""
+	if placeholderRegex.MatchString(content) {
+		placeholderRule = "\n- Placeholders such as [[NAME_1]] or [[PHONE_1]] stand in for the candidate's personal details. Copy them exactly as written, without translating or reformatting them"
+	}
+
+	return fmt.Sprintf(`Translate the following resume into %s (%s) and adapt it to local hiring conventions.
+
+RESUME:
+%s
+
+LOCALE CONVENTIONS:
+- Call the document "%s" wherever the document itself is named
+- Write all dates in the format %s
+- Write phone numbers in the format %s, keeping the original country code
+- %s
+- Use exactly these section headers, each on its own line:
+%s
+RULES:
+- Translate everything except names, company names, product names, URLs and email addresses
+- Keep the structure, order and bullet points of the original
+- Do not add, remove or embellish experience or skills%s
+
+Please provide your response in the following JSON format:
+{
+  "translated_content": "The complete translated resume in clean text format",
+  "source_language": "The language of the original resume as a BCP 47 tag, e.g. en",
+  "notes": ["Conventions that were applied", "Anything the candidate should review by hand"]
+}`, profile.Language, profile.Code, content, profile.DocumentTerm, profile.DateFormat, profile.PhoneFormat, personalDetails, headers.String(), placeholderRule)
+}
+
+// parseTranslationResponse parses the AI response into a TranslationResponse
+func (ai *AIOptimizer) parseTranslationResponse(content string) *TranslationResponse {
+	startIdx := strings.Index(content, "{")
+	endIdx := strings.LastIndex(content, "}")
+
+	if startIdx != -1 && endIdx > startIdx {
+		var response TranslationResponse
+		if err := json.Unmarshal([]byte(content[startIdx:endIdx+1]), &response); err == nil && response.TranslatedContent != "" {
+			return &response
+		}
+	}
+
+	// Fallback: treat entire response as translated content
+	return &TranslationResponse{
+		TranslatedContent: strings.TrimSpace(content),
+		Notes:             []string{"Resume translated to the target locale"},
+	}
+}
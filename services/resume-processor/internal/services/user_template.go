@@ -0,0 +1,341 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// Limits of user-defined templates
+const (
+	MaxUserTemplateSize   = 64 << 10 // Bytes of template source
+	userTemplateTimeout   = 2 * time.Second
+	userTemplateMaxSteps  = 100000  // Loop iterations per render
+	userTemplateMaxOutput = 1 << 20 // Bytes
+)
+
+// Fields a user-defined template can require of a resume. A section is
+// required by its heading, e.g. "section:Experience".
+const (
+	TemplateFieldName          = "name"
+	TemplateFieldLabel         = "label"
+	TemplateFieldContact       = "contact"
+	TemplateFieldSections      = "sections"
+	TemplateFieldSectionPrefix = "section:"
+)
+
+var (
+	// ErrTemplateTimeout is returned when a template runs past its time limit
+	ErrTemplateTimeout = errors.New("template execution timed out")
+	// ErrTemplateTooComplex is returned when a template loops too often
+	ErrTemplateTooComplex = errors.New("template exceeded its loop limit")
+	// ErrTemplateOutputTooLarge is returned when a template writes too much
+	ErrTemplateOutputTooLarge = errors.New("template output too large")
+)
+
+// sandboxFuncs are the functions user-defined templates may call. Templates
+// only see the escaped resume data, and none of these reach files, the
+// network or the process; print and printf are left out because their
+// padding can allocate without bound.
+var sandboxFuncs = map[string]bool{
+	"join": true, "len": true, "index": true,
+	"and": true, "or": true, "not": true,
+	"eq": true, "ne": true, "lt": true, "le": true, "gt": true, "ge": true,
+}
+
+// sandboxStepFunc is called at the top of every loop body of a sandboxed
+// template to enforce the loop and time limits
+const sandboxStepFunc = "sandboxStep"
+
+// TemplateValidationError lists the problems found in a user-defined template
+type TemplateValidationError struct {
+	Problems []string
+}
+
+func (e *TemplateValidationError) Error() string {
+	return "invalid template: " + strings.Join(e.Problems, "; ")
+}
+
+// MissingFieldsError is returned when a resume lacks fields a template requires
+type MissingFieldsError struct {
+	Fields []string
+}
+
+func (e *MissingFieldsError) Error() string {
+	return "resume is missing fields required by the template: " + strings.Join(e.Fields, ", ")
+}
+
+// templateSandbox holds the limits a user-defined template runs under
+type templateSandbox struct {
+	required  []string
+	timeout   time.Duration
+	maxSteps  int
+	maxOutput int
+}
+
+// NewUserTemplateExporter checks a user-defined template and returns an
+// exporter that renders it in a sandbox. Problems are reported together in a
+// *TemplateValidationError. Required fields are checked against each resume
+// before it is rendered.
+func NewUserTemplateExporter(format, body string, required []string) (*TemplateExporter, error) {
+	spec, ok := exportFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExportFormat, format)
+	}
+	main := "resume" + spec.ext + ".tmpl"
+
+	var problems []string
+	for _, field := range required {
+		if !validTemplateField(field) {
+			problems = append(problems, fmt.Sprintf("unknown required field %q", field))
+		}
+	}
+	switch {
+	case strings.TrimSpace(body) == "":
+		problems = append(problems, "template body is empty")
+	case len(body) > MaxUserTemplateSize:
+		problems = append(problems, fmt.Sprintf("template body is larger than %d bytes", MaxUserTemplateSize))
+	default:
+		tmpl, err := parseExportTemplate(main, body, spec, exportTemplateFuncs)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			problems = append(problems, sandboxProblems(tmpl)...)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &TemplateValidationError{Problems: problems}
+	}
+
+	return &TemplateExporter{
+		spec:   spec,
+		source: body,
+		main:   main,
+		sandbox: &templateSandbox{
+			required:  required,
+			timeout:   userTemplateTimeout,
+			maxSteps:  userTemplateMaxSteps,
+			maxOutput: userTemplateMaxOutput,
+		},
+	}, nil
+}
+
+// UserDefined reports whether the exporter renders a user-defined template
+func (e *TemplateExporter) UserDefined() bool {
+	return e.sandbox != nil
+}
+
+// validTemplateField reports whether a required field can be checked
+func validTemplateField(field string) bool {
+	switch field {
+	case TemplateFieldName, TemplateFieldLabel, TemplateFieldContact, TemplateFieldSections:
+		return true
+	}
+	return strings.HasPrefix(field, TemplateFieldSectionPrefix) && strings.TrimSpace(strings.TrimPrefix(field, TemplateFieldSectionPrefix)) != ""
+}
+
+// sandboxProblems finds the constructs user-defined templates may not use:
+// functions outside sandboxFuncs and nested templates, which could recurse
+func sandboxProblems(tmpl *template.Template) []string {
+	var problems []string
+	if len(tmpl.Templates()) > 1 {
+		problems = append(problems, "define and block are not allowed")
+	}
+	if tmpl.Tree == nil {
+		return problems
+	}
+
+	report := func(node parse.Node, format string, args ...interface{}) {
+		location, _ := tmpl.ErrorContext(node)
+		problems = append(problems, location+": "+fmt.Sprintf(format, args...))
+	}
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			report(n, "template calls are not allowed")
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.IdentifierNode:
+			if !sandboxFuncs[n.Ident] {
+				report(n, "function %q is not allowed", n.Ident)
+			}
+		}
+	}
+	walk(tmpl.Tree.Root)
+	return problems
+}
+
+// execute renders a checked template within the sandbox limits. The loop
+// bodies are instrumented with a step function, so templates that loop
+// without writing are stopped as well.
+func (s *templateSandbox) execute(name, source string, spec exportFormat, doc *ResumeDocument, data ExportData, out *bytes.Buffer) error {
+	if missing := missingTemplateFields(doc, s.required); len(missing) > 0 {
+		return &MissingFieldsError{Fields: missing}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	steps := 0
+	funcs := template.FuncMap{
+		sandboxStepFunc: func() (string, error) {
+			steps++
+			if steps > s.maxSteps {
+				return "", ErrTemplateTooComplex
+			}
+			if ctx.Err() != nil {
+				return "", ErrTemplateTimeout
+			}
+			return "", nil
+		},
+	}
+	for fn, impl := range exportTemplateFuncs {
+		funcs[fn] = impl
+	}
+
+	tmpl, err := parseExportTemplate(name, source, spec, funcs)
+	if err != nil {
+		return err
+	}
+	step, err := parseExportTemplate("step", spec.leftDelim+sandboxStepFunc+spec.rightDelim, spec, funcs)
+	if err != nil {
+		return err
+	}
+	instrumentLoops(tmpl.Tree.Root, step.Tree.Root.Nodes[0])
+
+	return tmpl.Execute(&sandboxWriter{ctx: ctx, out: out, limit: s.maxOutput}, data)
+}
+
+// instrumentLoops puts a call of the step action at the top of every range body
+func instrumentLoops(node parse.Node, step parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			instrumentLoops(child, step)
+		}
+	case *parse.RangeNode:
+		instrumentLoops(n.List, step)
+		instrumentLoops(n.ElseList, step)
+		n.List.Nodes = append([]parse.Node{step}, n.List.Nodes...)
+	case *parse.IfNode:
+		instrumentLoops(n.List, step)
+		instrumentLoops(n.ElseList, step)
+	case *parse.WithNode:
+		instrumentLoops(n.List, step)
+		instrumentLoops(n.ElseList, step)
+	}
+}
+
+// sandboxWriter fails writes past the output limit or the deadline
+type sandboxWriter struct {
+	ctx   context.Context
+	out   *bytes.Buffer
+	limit int
+}
+
+func (w *sandboxWriter) Write(p []byte) (int, error) {
+	if w.ctx.Err() != nil {
+		return 0, ErrTemplateTimeout
+	}
+	if w.out.Len()+len(p) > w.limit {
+		return 0, ErrTemplateOutputTooLarge
+	}
+	return w.out.Write(p)
+}
+
+// missingTemplateFields returns the required fields a resume does not have
+func missingTemplateFields(doc *ResumeDocument, required []string) []string {
+	present := map[string]bool{TemplateFieldName: strings.TrimSpace(doc.Name) != ""}
+	for _, block := range doc.Blocks {
+		switch block.Kind {
+		case ResumeBlockName, ResumeBlockLabel, ResumeBlockContact:
+			present[block.Kind] = true
+		case ResumeBlockHeading:
+			present[TemplateFieldSections] = true
+			present[TemplateFieldSectionPrefix+strings.ToLower(block.Text)] = true
+		}
+	}
+
+	missing := []string{}
+	for _, field := range required {
+		key := field
+		if strings.HasPrefix(field, TemplateFieldSectionPrefix) {
+			key = TemplateFieldSectionPrefix + strings.ToLower(strings.TrimSpace(strings.TrimPrefix(field, TemplateFieldSectionPrefix)))
+		}
+		if !present[key] {
+			missing = append(missing, field)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// sampleResumeText is rendered by template previews when no resume is given
+const sampleResumeText = `Alex Morgan
+Senior Software Engineer
+alex.morgan@example.com | +1 555 010 2030 | Berlin, Germany | github.com/alexmorgan
+
+SUMMARY
+Backend engineer with 8 years of experience building distributed systems in Go and Python.
+
+EXPERIENCE
+Senior Software Engineer, Northwind Analytics  Mar 2020 – Present
+- Led the migration of the billing platform to event sourcing, cutting reconciliation time by 70%
+- Mentored five engineers and introduced design reviews
+Software Engineer, Contoso Ltd  Jun 2016 – Feb 2020
+- Built the REST APIs behind the customer portal, serving 2M requests a day
+
+EDUCATION
+B.Sc. Computer Science, Technical University of Munich  2012 – 2016
+
+SKILLS
+Languages: Go, Python, SQL
+Tools: Kubernetes, PostgreSQL, Kafka
+`
+
+// SampleResumeDocument returns the resume template previews render by default
+func SampleResumeDocument() *ResumeDocument {
+	return ResumeDocumentFromText(sampleResumeText)
+}
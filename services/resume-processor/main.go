@@ -1,21 +1,41 @@
 package main
 
 import (
-	"log"
+	"context"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/resume-optimizer/resume-processor/internal/config"
 	"github.com/resume-optimizer/resume-processor/internal/handlers"
 	"github.com/resume-optimizer/resume-processor/internal/middleware"
 	"github.com/resume-optimizer/resume-processor/internal/database"
+	sharedmiddleware "github.com/resume-optimizer/shared/middleware"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 )
 
 func main() {
 	cfg := config.Load()
+
+	// Setup logging; code running outside a request logs through the
+	// global logger when it asks its context for one
+	sharedmiddleware.SetupLogger(cfg.LogLevel, cfg.LogFormat)
+	zerolog.DefaultContextLogger = &log.Logger
+
 	database.InitDatabase(cfg.DatabaseURL)
+	handlers.LoadSkillOverrides()
+	handlers.RecoverBatches()
 
-	r := gin.Default()
+	r := gin.New()
 	
+	r.Use(gin.Recovery())
+	r.Use(sharedmiddleware.RequestIDMiddleware())
+	r.Use(middleware.RequestLogger())
+	r.Use(sharedmiddleware.LoggingMiddleware())
 	r.Use(middleware.CORS())
 	
 	// Health check endpoint
@@ -32,6 +52,13 @@ func main() {
 			resumes.GET("/:id", handlers.GetResume)
 			resumes.GET("/", handlers.ListResumes)
 			resumes.DELETE("/:id", handlers.DeleteResume)
+			resumes.POST("/:id/translate", handlers.TranslateResume)
+			resumes.GET("/:id/revisions", handlers.ListResumeRevisions)
+			resumes.GET("/:id/analysis", handlers.AnalyzeResume)
+			resumes.GET("/:id/contact", handlers.GetResumeContact)
+			resumes.PATCH("/:id/contact", handlers.UpdateResumeContact)
+			resumes.GET("/:id/export", handlers.ExportResume)
+			resumes.GET("/:id/export.docx", handlers.ExportResumeDOCX)
 		}
 		
 		optimize := v1.Group("/optimize")
@@ -39,9 +66,87 @@ func main() {
 		{
 			optimize.POST("/", handlers.OptimizeResume)
 			optimize.POST("/feedback", handlers.ApplyFeedback)
+			optimize.POST("/:id/translate", handlers.TranslateOptimization)
+			optimize.GET("/:id/export", handlers.ExportOptimization)
+			optimize.GET("/:id/export.pdf", handlers.ExportOptimizationPDF)
+			optimize.GET("/:id/export.docx", handlers.ExportOptimizationDOCX)
+			optimize.POST("/batch", handlers.OptimizeBatch)
+			optimize.GET("/batch/:id", handlers.GetBatch)
+			optimize.GET("/batch/:id/download", handlers.DownloadBatch)
+			optimize.POST("/batch/:id/items/:itemId/retry", handlers.RetryBatchItem)
+		}
+		
+		v1.GET("/models", middleware.RequireAuth(), handlers.ListModels)
+		
+		skills := v1.Group("/skills")
+		skills.Use(middleware.RequireAuth())
+		{
+			skills.GET("/", handlers.ListSkills)
+			skills.GET("/:id", handlers.GetSkill)
+			skills.POST("/normalize", handlers.NormalizeSkills)
+		}
+		
+		admin := v1.Group("/admin")
+		admin.Use(middleware.RequireAuth(), middleware.RequireAdmin())
+		{
+			admin.POST("/skills", handlers.CreateSkill)
+			admin.PUT("/skills/:id", handlers.UpdateSkill)
+			admin.DELETE("/skills/:id", handlers.DeleteSkill)
+		}
+		
+		settings := v1.Group("/settings")
+		settings.Use(middleware.RequireAuth())
+		{
+			settings.GET("/", handlers.GetSettings)
+			settings.PUT("/", handlers.UpdateSettings)
+		}
+		
+		templates := v1.Group("/templates")
+		templates.Use(middleware.RequireAuth())
+		{
+			templates.GET("/", handlers.ListResumeTemplates)
+			templates.POST("/", handlers.CreateResumeTemplate)
+			templates.POST("/validate", handlers.ValidateResumeTemplate)
+			templates.POST("/preview", handlers.PreviewResumeTemplate)
+			templates.GET("/:id", handlers.GetResumeTemplate)
+			templates.PUT("/:id", handlers.UpdateResumeTemplate)
+			templates.DELETE("/:id", handlers.DeleteResumeTemplate)
 		}
 	}
 	
-	log.Printf("Resume processor service starting on port %s", cfg.Port)
-	log.Fatal(r.Run(":" + cfg.Port))
+	// Request contexts and batch runs derive from ctx, so a shutdown signal
+	// cancels in-flight scrapes, provider calls and OCR runs instead of
+	// waiting them out
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	handlers.SetBaseContext(ctx)
+
+	srv := &http.Server{
+		Addr:        ":" + cfg.Port,
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		log.Info().Str("port", cfg.Port).Msg("Resume processor service starting")
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal().Err(err).Msg("Server failed")
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info().Msg("Shutting down resume processor service")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Graceful shutdown failed")
+	}
+
+	// Batch items see the cancelled context; give them time to record it
+	batchCtx, cancelBatches := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelBatches()
+	if err := handlers.WaitForBatches(batchCtx); err != nil {
+		log.Error().Err(err).Msg("Batches did not stop in time; their items are cancelled on the next start")
+	}
 }
\ No newline at end of file